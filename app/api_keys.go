@@ -0,0 +1,115 @@
+package main
+
+// ApiSpec describes an API the broker serves.
+type ApiSpec struct {
+	name string
+	// supported versions, advertised by ApiVersions
+	minVersion int16
+	maxVersion int16
+	// first version using the compact types and tagged fields, in the body
+	// and the headers, -1 for none
+	flexibleVersion int16
+	newRequest      func(header RequestHeader) RequestInterface
+}
+
+// apiSpecs is the registry of the APIs the broker serves, by api key.
+var apiSpecs = map[int16]ApiSpec{
+	0: {
+		name: "Produce", minVersion: 3, maxVersion: 11, flexibleVersion: 9,
+		newRequest: func(header RequestHeader) RequestInterface { return &ProduceRequest{RequestHeader: header} },
+	},
+	1: {
+		name: "Fetch", minVersion: 0, maxVersion: 16, flexibleVersion: 12,
+		newRequest: func(header RequestHeader) RequestInterface { return &FetchRequest{RequestHeader: header} },
+	},
+	2: {
+		name: "ListOffsets", minVersion: 0, maxVersion: 9, flexibleVersion: 6,
+		newRequest: func(header RequestHeader) RequestInterface { return &ListOffsetsRequest{RequestHeader: header} },
+	},
+	3: {
+		name: "Metadata", minVersion: 0, maxVersion: 12, flexibleVersion: 9,
+		newRequest: func(header RequestHeader) RequestInterface { return &MetadataRequest{RequestHeader: header} },
+	},
+	8: {
+		name: "OffsetCommit", minVersion: 0, maxVersion: 9, flexibleVersion: 8,
+		newRequest: func(header RequestHeader) RequestInterface { return &OffsetCommitRequest{RequestHeader: header} },
+	},
+	9: {
+		name: "OffsetFetch", minVersion: 0, maxVersion: 9, flexibleVersion: 6,
+		newRequest: func(header RequestHeader) RequestInterface { return &OffsetFetchRequest{RequestHeader: header} },
+	},
+	10: {
+		name: "FindCoordinator", minVersion: 0, maxVersion: 4, flexibleVersion: 3,
+		newRequest: func(header RequestHeader) RequestInterface { return &FindCoordinatorRequest{RequestHeader: header} },
+	},
+	11: {
+		name: "JoinGroup", minVersion: 0, maxVersion: 9, flexibleVersion: 6,
+		newRequest: func(header RequestHeader) RequestInterface { return &JoinGroupRequest{RequestHeader: header} },
+	},
+	12: {
+		name: "Heartbeat", minVersion: 0, maxVersion: 4, flexibleVersion: 4,
+		newRequest: func(header RequestHeader) RequestInterface { return &HeartbeatRequest{RequestHeader: header} },
+	},
+	13: {
+		name: "LeaveGroup", minVersion: 0, maxVersion: 5, flexibleVersion: 4,
+		newRequest: func(header RequestHeader) RequestInterface { return &LeaveGroupRequest{RequestHeader: header} },
+	},
+	14: {
+		name: "SyncGroup", minVersion: 0, maxVersion: 5, flexibleVersion: 4,
+		newRequest: func(header RequestHeader) RequestInterface { return &SyncGroupRequest{RequestHeader: header} },
+	},
+	18: {
+		name: "ApiVersions", minVersion: 0, maxVersion: 4, flexibleVersion: 3,
+		newRequest: func(header RequestHeader) RequestInterface { return &ApiVersionsRequest{RequestHeader: header} },
+	},
+	19: {
+		name: "CreateTopics", minVersion: 0, maxVersion: 7, flexibleVersion: 5,
+		newRequest: func(header RequestHeader) RequestInterface { return &CreateTopicsRequest{RequestHeader: header} },
+	},
+	20: {
+		name: "DeleteTopics", minVersion: 0, maxVersion: 6, flexibleVersion: 4,
+		newRequest: func(header RequestHeader) RequestInterface { return &DeleteTopicsRequest{RequestHeader: header} },
+	},
+	37: {
+		name: "CreatePartitions", minVersion: 0, maxVersion: 3, flexibleVersion: 2,
+		newRequest: func(header RequestHeader) RequestInterface { return &CreatePartitionsRequest{RequestHeader: header} },
+	},
+	75: {
+		name: "DescribeTopicPartitions", minVersion: 0, maxVersion: 0, flexibleVersion: 0,
+		newRequest: func(header RequestHeader) RequestInterface { return &DescribePartitionsRequest{RequestHeader: header} },
+	},
+}
+
+func (spec ApiSpec) supportsVersion(apiVersion int16) bool {
+	return apiVersion >= spec.minVersion && apiVersion <= spec.maxVersion
+}
+
+func isFlexible(apiKey int16, apiVersion int16) bool {
+	spec, ok := apiSpecs[apiKey]
+	return ok && spec.flexibleVersion >= 0 && apiVersion >= spec.flexibleVersion
+}
+
+// requestHeaderVersion returns 2 for flexible request versions, whose header
+// has a tagged field section after clientId, 1 for the others and 0 for
+// ControlledShutdown v0, the only request without a clientId.
+func requestHeaderVersion(apiKey int16, apiVersion int16) int16 {
+	switch {
+	case apiKey == 7 && apiVersion == 0:
+		return 0
+	case isFlexible(apiKey, apiVersion):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// responseHeaderVersion returns 1 for flexible response versions, whose
+// header has a tagged field section after correlationId, and 0 otherwise.
+// ApiVersions responses always use v0, a client reads them before it knows
+// which versions the broker supports.
+func responseHeaderVersion(apiKey int16, apiVersion int16) int16 {
+	if apiKey != 18 && isFlexible(apiKey, apiVersion) {
+		return 1
+	}
+	return 0
+}
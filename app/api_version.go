@@ -1,84 +1,82 @@
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
-	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 )
 
 // ApiVersions
 
 type ApiVersionsRequest struct {
 	RequestHeader
-	clientSoftwareName    string
-	clientSoftwareVersion string
-}
-
-type ApiKey struct {
-	key        int16
-	minVersion int16
-	maxVersion int16
+	protocol.ApiVersionsRequest
 }
 
-type ApiVersionsResponse struct {
-	errorCode    int16
-	numOfApiKeys int8
-	apiKeys      []ApiKey
-	throttleTime int32
+// supportedFeatures are the feature levels the broker can run at, those of
+// a Kafka 3.8 broker.
+var supportedFeatures = []*protocol.ApiVersionsResponseSupportedFeatureKey{
+	{Name: "metadata.version", MinVersion: 1, MaxVersion: 20},
 }
 
-func (request *ApiVersionsRequest) parse(buffer *bytes.Buffer) {
-	request.clientSoftwareName = readCompactString(buffer)
-	request.clientSoftwareVersion = readCompactString(buffer)
-	ignoreTagField(buffer)
-	fmt.Printf("%+v\n", request)
-}
-
-func (response *ApiVersionsResponse) bytes(buffer *bytes.Buffer) {
-
-	binary.Write(buffer, binary.BigEndian, response.errorCode)
-	binary.Write(buffer, binary.BigEndian, response.numOfApiKeys)
-
-	for _, apiKey := range response.apiKeys {
-		binary.Write(buffer, binary.BigEndian, apiKey.key)
-		binary.Write(buffer, binary.BigEndian, apiKey.minVersion)
-		binary.Write(buffer, binary.BigEndian, apiKey.maxVersion)
-		addTagField(buffer)
+// bodyVersion is the version the request and response bodies are read and
+// written in. Clients don't know which versions the broker supports yet,
+// so a version it doesn't is answered in v0, which every client can read.
+func (request *ApiVersionsRequest) bodyVersion() int16 {
+	if !apiSpecs[request.apiKey].supportsVersion(request.apiVersion) {
+		return 0
 	}
+	return request.apiVersion
+}
 
-	binary.Write(buffer, binary.BigEndian, response.throttleTime)
-	addTagField(buffer)
-	fmt.Printf("%+v\n", response)
+func (request *ApiVersionsRequest) parse(decoder *protocol.Decoder) error {
+	return request.ApiVersionsRequest.Decode(decoder, request.bodyVersion())
 }
 
 func (request *ApiVersionsRequest) generateResponse(commonResponse *Response) {
 	commonResponse.correlationId = request.correlationId
 
-	apiVersionResponse := ApiVersionsResponse{}
-	apiVersionResponse.errorCode = getApiVersionsErrorCode(request.apiVersion)
-	apiVersionResponse.throttleTime = 0
-
-	apiVersion := ApiKey{}
-	apiVersion.key = request.apiKey
-	apiVersion.minVersion = 0
-	apiVersion.maxVersion = 4
-	apiVersionResponse.apiKeys = append(apiVersionResponse.apiKeys, apiVersion)
+	apiVersionsResponse := protocol.ApiVersionsResponse{}
+	apiVersionsResponse.Default()
 
-	// describe topic response
-	describeTopicVersion := ApiKey{}
-	describeTopicVersion.key = 75
-	describeTopicVersion.minVersion = 0
-	describeTopicVersion.maxVersion = 0
-	apiVersionResponse.apiKeys = append(apiVersionResponse.apiKeys, describeTopicVersion)
+	for _, apiKey := range slices.Sorted(maps.Keys(apiSpecs)) {
+		apiVersionsResponse.ApiKeys = append(apiVersionsResponse.ApiKeys, &protocol.ApiVersionsResponseApiVersion{
+			ApiKey:     apiKey,
+			MinVersion: apiSpecs[apiKey].minVersion,
+			MaxVersion: apiSpecs[apiKey].maxVersion,
+		})
+	}
+	if apiSpecs[request.apiKey].supportsVersion(request.apiVersion) {
+		addFeatures(&apiVersionsResponse)
+	} else {
+		// the client retries with the highest version it supports
+		apiVersionsResponse.ErrorCode = errorCodeUnsupportedVersion
+	}
 
-	// fetch response
-	fetchVersion := ApiKey{}
-	fetchVersion.key = 1
-	fetchVersion.minVersion = 0
-	fetchVersion.maxVersion = 16
-	apiVersionResponse.apiKeys = append(apiVersionResponse.apiKeys, fetchVersion)
+	version := request.bodyVersion()
+	commonResponse.body = protocol.NewEncoder(apiVersionsResponse.IsFlexible(version))
+	apiVersionsResponse.Encode(commonResponse.body, version)
+}
 
-	apiVersionResponse.numOfApiKeys = int8(len(apiVersionResponse.apiKeys) + 1)
+// addFeatures adds the supported features and the cluster's finalized
+// features, whose epoch is the offset of the metadata image like in kafka.
+func addFeatures(response *protocol.ApiVersionsResponse) {
+	response.SupportedFeatures = supportedFeatures
+	// only controllers ready to migrate from zookeeper set it
+	response.ZkMigrationReady = false
 
-	apiVersionResponse.bytes(&commonResponse.BytesData)
+	image := getMetadataImage()
+	if len(image.features) == 0 {
+		return
+	}
+	response.FinalizedFeaturesEpoch = image.nextOffset - 1
+	for _, name := range slices.Sorted(maps.Keys(image.features)) {
+		level := int16(image.features[name])
+		response.FinalizedFeatures = append(response.FinalizedFeatures, &protocol.ApiVersionsResponseFinalizedFeatureKey{
+			Name:            name,
+			MaxVersionLevel: level,
+			MinVersionLevel: level,
+		})
+	}
 }
\ No newline at end of file
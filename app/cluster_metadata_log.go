@@ -1,67 +1,101 @@
 package main
 
 import (
-	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
-	"os"
+	"math"
+	"time"
 
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 	"github.com/google/uuid"
 )
 
 type PartitionRecord struct {
-	frameVersion                  uint8
-	recordType                    uint8
-	version                       uint8
-	partitionId                   int32
-	topicId                       uuid.UUID
-	lengthOfReplicaArray          uint64
-	replicaArray                  []int32
-	lengthOfInSyncReplicaArray    uint64
-	inSyncReplicaArray            []int32
-	lengthOfRemovingReplicasArray uint64
-	lengthOfAddingReplicasArray   uint64
-	leader                        int32
-	leaderEpoch                   int32
-	partitionEpoch                uint32
-	lengthOfDirectoriesArray      uint64
-	directoriesArray              []uuid.UUID
-	taggedFieldCount              uint64
+	frameVersion           uint8
+	recordType             uint8
+	version                uint8
+	partitionId            int32
+	topicId                uuid.UUID
+	replicaArray           []int32
+	inSyncReplicaArray     []int32
+	leader                 int32
+	leaderEpoch            int32
+	partitionEpoch         uint32
+	directoriesArray       []uuid.UUID
+	leaderRecoveryState    int8
+	eligibleLeaderReplicas []int32
+	lastKnownElr           []int32
+	taggedFieldCount       uint64
 }
 
 type TopicRecord struct {
 	frameVersion     uint8
 	recordType       uint8
 	version          uint8
-	nameLength       uint64
 	name             string
 	topicId          uuid.UUID
 	taggedFieldCount uint64
 }
 
+// RemoveTopicRecord is metadata record type 10, the type kafka gives it.
+type RemoveTopicRecord struct {
+	frameVersion     uint8
+	recordType       uint8
+	version          uint8
+	topicId          uuid.UUID
+	taggedFieldCount uint64
+}
+
 type Record struct {
-	length             int64
-	attributes         int8
-	timestampDelta     int64
-	offsetDelta        int64
-	keyLength          int64
-	key                []byte
-	valueLength        int64
-	frameVersion       uint8
-	recordType         uint8
-	version            uint8
-	TopicRecord        TopicRecord
-	PartitionRecord    PartitionRecord
-	FeatureLevelRecord FeatureLevelRecord
-	headerArrayCount   uint64
+	length                         int64
+	attributes                     int8
+	timestampDelta                 int64
+	offsetDelta                    int64
+	keyLength                      int64
+	key                            []byte
+	valueLength                    int64
+	value                          []byte // nil for a null value, a tombstone in compacted topics
+	raw                            []byte // the whole encoded record, length included
+	frameVersion                   uint8
+	recordType                     uint8
+	version                        uint8
+	TopicRecord                    TopicRecord
+	PartitionRecord                PartitionRecord
+	FeatureLevelRecord             FeatureLevelRecord
+	ConfigRecord                   ConfigRecord
+	RemoveTopicRecord              RemoveTopicRecord
+	RegisterBrokerRecord           RegisterBrokerRecord
+	UnregisterBrokerRecord         UnregisterBrokerRecord
+	PartitionChangeRecord          PartitionChangeRecord
+	AccessControlEntryRecord       AccessControlEntryRecord
+	RemoveAccessControlEntryRecord RemoveAccessControlEntryRecord
+	FenceBrokerRecord              FenceBrokerRecord
+	ClientQuotaRecord              ClientQuotaRecord
+	ProducerIdsRecord              ProducerIdsRecord
+	BrokerRegistrationChangeRecord BrokerRegistrationChangeRecord
+	TransactionRecord              TransactionRecord
+	headerArrayCount               uint64
+}
+
+type ConfigRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	// 2 is a topic, 4 a broker
+	resourceType uint8
+	resourceName string
+	name         string
+	// null values remove the config
+	value            string
+	valueIsNull      bool
+	taggedFieldCount uint64
 }
 
 type FeatureLevelRecord struct {
 	frameVersion     uint8
 	recordType       uint8
 	version          uint8
-	nameLength       uint64
 	name             string
 	featureLevel     uint16
 	taggedFieldCount uint64
@@ -84,50 +118,19 @@ type ClusterMetadata struct {
 	records              []*Record
 }
 
-func readLogFile(fileName string) *[]byte {
-	fileData, err := os.ReadFile(fileName)
-	if err != nil {
-		fmt.Printf("Error while reading cluster metadata log file, Error Details: %s", err)
-	}
-	fmt.Printf("%+v\n", fileData)
-	return &fileData
-}
-
-func getTopicLog(topicName string, partitionIndex int) (*[]byte, error) {
-
-	topicLogFileName := fmt.Sprintf("/tmp/kraft-combined-logs/%s-%d/00000000000000000000.log", topicName, partitionIndex)
-	fileData := readLogFile(topicLogFileName)
-
-	return fileData, nil
-}
-
-func getTopicRecordList() ([]*TopicRecord, error) {
-	topicList := []*TopicRecord{}
+// readClusterMetadata reads the batches of the metadata log from the one
+// holding fromOffset to the end of the log.
+func readClusterMetadata(fromOffset int64) ([]*ClusterMetadata, error) {
 
-	clusterMetadataLogs, err := readClusterMetadata()
-	if err != nil {
-		return topicList, err
+	// the metadata log can span several segments, read all of them
+	var fileData []byte
+	metadataLog, err := getPartitionLog("__cluster_metadata", 0)
+	if err == nil {
+		fileData, err = metadataLog.read(fromOffset, math.MaxInt32, true)
 	}
-
-	for _, recordBatch := range clusterMetadataLogs {
-		for _, record := range recordBatch.records {
-			if record.recordType == 2 {
-				topicList = append(topicList, &record.TopicRecord)
-			}
-		}
-	}
-
-	return topicList, nil
-}
-
-func readClusterMetadata() ([]*ClusterMetadata, error) {
-
-	clusterMetadataLogFileName := "/tmp/kraft-combined-logs/__cluster_metadata-0/00000000000000000000.log"
-	fileData, err := os.ReadFile(clusterMetadataLogFileName)
 	if err != nil {
 		fmt.Printf("Error while reading cluster metadata log file, Error Details: %s", err)
 	}
-	fmt.Printf("%+v\n", fileData)
 
 	// fileData := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 79, 0, 0, 0, 1, 2, 176, 105, 69, 124, 0, 0, 0, 0, 0, 0, 0, 0, 1, 145, 224, 90, 248, 24, 0, 0, 1, 145, 224, 90, 248, 24, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 1, 58, 0, 0, 0, 1, 46, 1, 12, 0, 17, 109, 101, 116, 97, 100, 97, 116, 97, 46, 118, 101, 114, 115, 105, 111, 110, 0, 20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 154, 0, 0, 0, 1, 2, 105, 208, 150, 103, 0, 0, 0, 0, 0, 1, 0, 0, 1, 145, 224, 91, 45, 21, 0, 0, 1, 145, 224, 91, 45, 21, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 2, 60, 0, 0, 0, 1, 48, 1, 2, 0, 4, 98, 97, 114, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 84, 0, 0, 144, 1, 0, 0, 2, 1, 130, 1, 1, 3, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 84, 2, 0, 0, 0, 1, 2, 0, 0, 0, 1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 2, 16, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 154, 0, 0, 0, 1, 2, 16, 140, 191, 92, 0, 0, 0, 0, 0, 1, 0, 0, 1, 145, 224, 91, 45, 21, 0, 0, 1, 145, 224, 91, 45, 21, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 2, 60, 0, 0, 0, 1, 48, 1, 2, 0, 4, 98, 97, 122, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 152, 0, 0, 144, 1, 0, 0, 2, 1, 130, 1, 1, 3, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 152, 2, 0, 0, 0, 1, 2, 0, 0, 0, 1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 2, 16, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6, 0, 0, 0, 228, 0, 0, 0, 1, 2, 133, 202, 140, 77, 0, 0, 0, 0, 0, 2, 0, 0, 1, 145, 224, 91, 45, 21, 0, 0, 1, 145, 224, 91, 45, 21, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 0, 0, 3, 60, 0, 0, 0, 1, 48, 1, 2, 0, 4, 112, 97, 120, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 99, 0, 0, 144, 1, 0, 0, 2, 1, 130, 1, 1, 3, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 99, 2, 0, 0, 0, 1, 2, 0, 0, 0, 1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 2, 16, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 1, 0, 0, 144, 1, 0, 0, 4, 1, 130, 1, 1, 3, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 99, 2, 0, 0, 0, 1, 2, 0, 0, 0, 1, 1, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 2, 16, 0, 0, 0, 0, 0, 64, 0, 128, 0, 0, 0, 0, 0, 0, 1, 0, 0}
 
@@ -147,121 +150,303 @@ func readClusterMetadata() ([]*ClusterMetadata, error) {
 	// fmt.Printf("%+v\n", clusterMetadata)
 
 	clusterMetadataLogRecords := []*ClusterMetadata{}
-	fileBuffer := bytes.NewBuffer(fileData)
-
-	for fileBuffer.Len() > 0 {
-		clusterMetadata, err := parseClusterMetadata(fileBuffer)
+	for position := 0; position < len(fileData); {
+		clusterMetadata, batchSize, err := parseClusterMetadata(fileData[position:])
 		if err != nil {
-			return []*ClusterMetadata{}, err
+			// the batches before a bad one can still be used
+			return clusterMetadataLogRecords, err
 		}
-		// fmt.Printf("%+v\n", clusterMetadata)
 		clusterMetadataLogRecords = append(clusterMetadataLogRecords, clusterMetadata)
+		position += batchSize
 	}
 
 	return clusterMetadataLogRecords, nil
 }
 
-func parseClusterMetadata(fileBuffer *bytes.Buffer) (*ClusterMetadata, error) {
+// readRecordBatchHeader reads the fixed size RecordBatch v2 header, everything
+// up to and including the records count.
+func readRecordBatchHeader(decoder *protocol.Decoder, clusterMetadata *ClusterMetadata) error {
+	clusterMetadata.baseOffset = uint64(decoder.ReadInt64())
+	clusterMetadata.batchLength = decoder.ReadUint32()
+	clusterMetadata.partitionLeaderEpoch = decoder.ReadUint32()
+	clusterMetadata.magicByte = uint8(decoder.ReadInt8())
+	clusterMetadata.crc = decoder.ReadUint32()
+	clusterMetadata.attributes = decoder.ReadUint16()
+	clusterMetadata.lastOffsetDelta = decoder.ReadUint32()
+	clusterMetadata.baseTimestamp = uint64(decoder.ReadInt64())
+	clusterMetadata.maxTimestamp = uint64(decoder.ReadInt64())
+	clusterMetadata.producerId = uint64(decoder.ReadInt64())
+	clusterMetadata.producerEpoch = decoder.ReadUint16()
+	clusterMetadata.baseSequence = decoder.ReadUint32()
+	clusterMetadata.recordsLength = decoder.ReadUint32()
+	return decoder.Err()
+}
+
+// readRecord reads one record of a batch. The value is kept as is, metadata
+// records are decoded from it by parseClusterMetadata.
+func readRecord(decoder *protocol.Decoder) (*Record, error) {
+	record := &Record{}
+
+	record.length = decoder.ReadVarint()
+	if decoder.Err() == nil && (record.length <= 0 || record.length > int64(decoder.Remaining())) {
+		return nil, fmt.Errorf("invalid record length %d", record.length)
+	}
+	body := decoder.ReadRaw(int(record.length))
+	if err := decoder.Err(); err != nil {
+		return nil, err
+	}
+	record.raw = append(binary.AppendVarint(nil, record.length), body...)
+
+	recordDecoder := protocol.NewDecoder(body, false)
+	record.attributes = recordDecoder.ReadInt8()
+	record.timestampDelta = recordDecoder.ReadVarint()
+	record.offsetDelta = recordDecoder.ReadVarint()
+	record.keyLength = recordDecoder.ReadVarint()
+	if record.keyLength >= 0 {
+		record.key = recordDecoder.ReadRaw(int(record.keyLength))
+	}
+	record.valueLength = recordDecoder.ReadVarint()
+	if record.valueLength >= 0 {
+		record.value = recordDecoder.ReadRaw(int(record.valueLength))
+	}
+	record.headerArrayCount = uint64(max(recordDecoder.ReadVarint(), 0))
+	if err := recordDecoder.Err(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// parseClusterMetadata reads the batch at the start of data and returns it
+// along with its size.
+func parseClusterMetadata(data []byte) (*ClusterMetadata, int, error) {
+	if len(data) < recordBatchHeaderSize {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	batchSize := recordBatchLogOverhead + int(binary.BigEndian.Uint32(data[recordBatchLengthPosition:]))
+	if batchSize < recordBatchHeaderSize || batchSize > len(data) {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if !recordBatchCrcMatches(data[:batchSize]) {
+		return nil, 0, errCorruptRecordBatch
+	}
+
+	decoder := protocol.NewDecoder(data[:batchSize], false)
 	clusterMetadata := &ClusterMetadata{}
+	if err := readRecordBatchHeader(decoder, clusterMetadata); err != nil {
+		return nil, 0, err
+	}
 
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.baseOffset)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.batchLength)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.partitionLeaderEpoch)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.magicByte)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.crc)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.attributes)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.lastOffsetDelta)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.baseTimestamp)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.maxTimestamp)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.producerId)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.producerEpoch)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.baseSequence)
-	binary.Read(fileBuffer, binary.BigEndian, &clusterMetadata.recordsLength)
-
-	// fmt.Printf("ClusterMetadata Records Length: %d\n", clusterMetadata.recordsLength)
+	recordsDecoder := decoder
+	if codec := int16(clusterMetadata.attributes & recordBatchCompressionMask); codec != compressionNone {
+		records, err := decompress(codec, decoder.ReadRaw(decoder.Remaining()))
+		if err != nil {
+			return nil, 0, err
+		}
+		recordsDecoder = protocol.NewDecoder(records, false)
+	}
 
 	for i := uint32(0); i < clusterMetadata.recordsLength; i++ {
-		record := Record{}
-
-		length, _ := binary.ReadVarint(fileBuffer)
-		record.length = length
-		_ = binary.Read(fileBuffer, binary.BigEndian, &record.attributes)
-		timestampDelta, _ := binary.ReadVarint(fileBuffer)
-		record.timestampDelta = timestampDelta
-		offsetDelta, _ := binary.ReadVarint(fileBuffer)
-		record.offsetDelta = offsetDelta
-		keyLength, _ := binary.ReadVarint(fileBuffer)
-		record.keyLength = keyLength
-		if keyLength > 0 {
-			record.key = make([]byte, keyLength)
-			_, _ = io.ReadFull(fileBuffer, record.key)
+		record, err := readRecord(recordsDecoder)
+		if err != nil {
+			return nil, 0, err
 		}
-		valueLength, _ := binary.ReadVarint(fileBuffer)
-		record.valueLength = valueLength
-		valueBytes := make([]byte, valueLength)
-		_, _ = io.ReadFull(fileBuffer, valueBytes)
-		valueBuf := bytes.NewBuffer(valueBytes)
-		_ = binary.Read(valueBuf, binary.BigEndian, &record.frameVersion)
-		_ = binary.Read(valueBuf, binary.BigEndian, &record.recordType)
-		_ = binary.Read(valueBuf, binary.BigEndian, &record.version)
-		switch record.recordType {
-		case 2:
-			topicRecord := TopicRecord{}
-			topicRecord.frameVersion = record.frameVersion
-			topicRecord.recordType = record.recordType
-			topicRecord.version = record.version
-			topicRecord.nameLength, _ = binary.ReadUvarint(valueBuf)
-			topicRecord.name = string(valueBuf.Next(int(topicRecord.nameLength - 1)))
-			topicIDBytes := valueBuf.Next(16)
-			topicRecord.topicId, _ = uuid.FromBytes(topicIDBytes)
-			topicRecord.taggedFieldCount, _ = binary.ReadUvarint(valueBuf)
-			record.TopicRecord = topicRecord
-		case 3:
-			partitionRecord := PartitionRecord{}
-			partitionRecord.frameVersion = record.frameVersion
-			partitionRecord.recordType = record.recordType
-			partitionRecord.version = record.version
-			_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.partitionId)
-			_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.topicId)
-			partitionRecord.lengthOfReplicaArray, _ = binary.ReadUvarint(valueBuf)
-			partitionRecord.replicaArray = make([]int32, partitionRecord.lengthOfReplicaArray)
-			for j := uint64(0); j < partitionRecord.lengthOfReplicaArray; j++ {
-				_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.replicaArray[j])
-			}
-			partitionRecord.lengthOfInSyncReplicaArray, _ = binary.ReadUvarint(valueBuf)
-			partitionRecord.inSyncReplicaArray = make([]int32, partitionRecord.lengthOfInSyncReplicaArray)
-			for j := uint64(0); j < partitionRecord.lengthOfInSyncReplicaArray; j++ {
-				_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.inSyncReplicaArray[j])
-			}
-			partitionRecord.lengthOfRemovingReplicasArray, _ = binary.ReadUvarint(valueBuf)
-			partitionRecord.lengthOfAddingReplicasArray, _ = binary.ReadUvarint(valueBuf)
-			_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.leader)
-			_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.leaderEpoch)
-			_ = binary.Read(valueBuf, binary.BigEndian, &partitionRecord.partitionEpoch)
-			partitionRecord.lengthOfDirectoriesArray, _ = binary.ReadUvarint(valueBuf)
-			partitionRecord.directoriesArray = make([]uuid.UUID, partitionRecord.lengthOfDirectoriesArray)
-			for j := uint64(0); j < partitionRecord.lengthOfDirectoriesArray; j++ {
-				dirBytes := valueBuf.Next(16)
-				partitionRecord.directoriesArray[j], _ = uuid.FromBytes(dirBytes)
-			}
-			partitionRecord.taggedFieldCount, _ = binary.ReadUvarint(valueBuf)
-			record.PartitionRecord = partitionRecord
-
-			// fmt.Printf("\nPartition Record: %+v", partitionRecord)
-		case 12:
-			featureRecord := FeatureLevelRecord{}
-			featureRecord.frameVersion = record.frameVersion
-			featureRecord.recordType = record.recordType
-			featureRecord.version = record.version
-			featureRecord.nameLength, _ = binary.ReadUvarint(valueBuf)
-			featureRecord.name = string(valueBuf.Next(int(featureRecord.nameLength - 1)))
-			_ = binary.Read(valueBuf, binary.BigEndian, &featureRecord.featureLevel)
-			featureRecord.taggedFieldCount, _ = binary.ReadUvarint(valueBuf)
-			record.FeatureLevelRecord = featureRecord
+		if err := decodeMetadataRecord(record); err != nil {
+			return nil, 0, fmt.Errorf("metadata record at offset %d: %w", int64(clusterMetadata.baseOffset)+record.offsetDelta, err)
 		}
-		record.headerArrayCount, _ = binary.ReadUvarint(fileBuffer)
-		clusterMetadata.records = append(clusterMetadata.records, &record)
+		clusterMetadata.records = append(clusterMetadata.records, record)
+	}
+
+	return clusterMetadata, batchSize, nil
+}
+
+// decodeMetadataRecord decodes the metadata record held in the record's
+// value into the field of its type.
+func decodeMetadataRecord(record *Record) error {
+	// metadata records are flexible, with compact strings and arrays
+	valueDecoder := protocol.NewDecoder(record.value, true)
+	record.frameVersion = uint8(valueDecoder.ReadInt8())
+	record.recordType = uint8(valueDecoder.ReadInt8())
+	record.version = uint8(valueDecoder.ReadInt8())
+
+	var err error
+	switch record.recordType {
+	case 2:
+		topicRecord := TopicRecord{}
+		topicRecord.frameVersion = record.frameVersion
+		topicRecord.recordType = record.recordType
+		topicRecord.version = record.version
+		topicRecord.name = valueDecoder.ReadString()
+		topicRecord.topicId = valueDecoder.ReadUuid()
+		topicRecord.taggedFieldCount = uint64(len(valueDecoder.ReadTaggedFields()))
+		record.TopicRecord = topicRecord
+	case 3:
+		partitionRecord := PartitionRecord{}
+		partitionRecord.frameVersion = record.frameVersion
+		partitionRecord.recordType = record.recordType
+		partitionRecord.version = record.version
+		partitionRecord.partitionId = valueDecoder.ReadInt32()
+		partitionRecord.topicId = valueDecoder.ReadUuid()
+		partitionRecord.replicaArray = valueDecoder.ReadInt32Array()
+		partitionRecord.inSyncReplicaArray = valueDecoder.ReadInt32Array()
+		// removing and adding replicas
+		valueDecoder.ReadInt32Array()
+		valueDecoder.ReadInt32Array()
+		partitionRecord.leader = valueDecoder.ReadInt32()
+		partitionRecord.leaderEpoch = valueDecoder.ReadInt32()
+		partitionRecord.partitionEpoch = valueDecoder.ReadUint32()
+		partitionRecord.directoriesArray = []uuid.UUID{}
+		// directories were added in v1
+		if partitionRecord.version >= 1 {
+			partitionRecord.directoriesArray = readUuidArray(valueDecoder)
+		}
+		taggedFields := valueDecoder.ReadTaggedFields()
+		partitionRecord.taggedFieldCount = uint64(len(taggedFields))
+		if value, ok := taggedFields[0]; ok {
+			tagDecoder := protocol.NewDecoder(value, true)
+			partitionRecord.leaderRecoveryState = tagDecoder.ReadInt8()
+			err = tagDecoder.Err()
+		}
+		// the eligible leader replicas were added in v2
+		if value, ok := taggedFields[1]; ok && partitionRecord.version >= 2 && err == nil {
+			partitionRecord.eligibleLeaderReplicas, err = readTaggedInt32Array(value)
+		}
+		if value, ok := taggedFields[2]; ok && partitionRecord.version >= 2 && err == nil {
+			partitionRecord.lastKnownElr, err = readTaggedInt32Array(value)
+		}
+		record.PartitionRecord = partitionRecord
+	case 12:
+		featureRecord := FeatureLevelRecord{}
+		featureRecord.frameVersion = record.frameVersion
+		featureRecord.recordType = record.recordType
+		featureRecord.version = record.version
+		featureRecord.name = valueDecoder.ReadString()
+		featureRecord.featureLevel = valueDecoder.ReadUint16()
+		featureRecord.taggedFieldCount = uint64(len(valueDecoder.ReadTaggedFields()))
+		record.FeatureLevelRecord = featureRecord
+	case 4:
+		configRecord := ConfigRecord{}
+		configRecord.frameVersion = record.frameVersion
+		configRecord.recordType = record.recordType
+		configRecord.version = record.version
+		configRecord.resourceType = uint8(valueDecoder.ReadInt8())
+		configRecord.resourceName = valueDecoder.ReadString()
+		configRecord.name = valueDecoder.ReadString()
+		var ok bool
+		configRecord.value, ok = valueDecoder.ReadNullableString()
+		configRecord.valueIsNull = !ok && valueDecoder.Err() == nil
+		configRecord.taggedFieldCount = uint64(len(valueDecoder.ReadTaggedFields()))
+		record.ConfigRecord = configRecord
+	case 10:
+		removeTopicRecord := RemoveTopicRecord{}
+		removeTopicRecord.frameVersion = record.frameVersion
+		removeTopicRecord.recordType = record.recordType
+		removeTopicRecord.version = record.version
+		removeTopicRecord.topicId = valueDecoder.ReadUuid()
+		removeTopicRecord.taggedFieldCount = uint64(len(valueDecoder.ReadTaggedFields()))
+		record.RemoveTopicRecord = removeTopicRecord
+	case 0:
+		record.RegisterBrokerRecord = decodeRegisterBrokerRecord(valueDecoder, record)
+	case 1:
+		record.UnregisterBrokerRecord = decodeUnregisterBrokerRecord(valueDecoder, record)
+	case 5:
+		record.PartitionChangeRecord, err = decodePartitionChangeRecord(valueDecoder, record)
+	case 6:
+		record.AccessControlEntryRecord = decodeAccessControlEntryRecord(valueDecoder, record)
+	case 7:
+		record.RemoveAccessControlEntryRecord = decodeRemoveAccessControlEntryRecord(valueDecoder, record)
+	case 8, 9:
+		// fence and unfence broker
+		record.FenceBrokerRecord = decodeFenceBrokerRecord(valueDecoder, record)
+	case 14:
+		record.ClientQuotaRecord = decodeClientQuotaRecord(valueDecoder, record)
+	case 15:
+		record.ProducerIdsRecord = decodeProducerIdsRecord(valueDecoder, record)
+	case 17:
+		record.BrokerRegistrationChangeRecord, err = decodeBrokerRegistrationChangeRecord(valueDecoder, record)
+	case 23, 24, 25:
+		// begin, end and abort transaction
+		record.TransactionRecord, err = decodeTransactionRecord(valueDecoder, record)
+	}
+	if err != nil {
+		return err
 	}
+	return valueDecoder.Err()
+}
+
+// metadataRecordValue writes the frame version, record type and version that
+// prefix every metadata record value.
+func metadataRecordValue(recordType uint8, version uint8) *protocol.Encoder {
+	encoder := protocol.NewEncoder(true)
+	encoder.WriteInt8(1)
+	encoder.WriteInt8(int8(recordType))
+	encoder.WriteInt8(int8(version))
+	return encoder
+}
+
+func (topicRecord *TopicRecord) encode() []byte {
+	encoder := metadataRecordValue(2, 0)
+	encoder.WriteString(topicRecord.name)
+	encoder.WriteUuid(topicRecord.topicId)
+	encoder.WriteTaggedFields(nil)
+	return encoder.Bytes()
+}
 
-	return clusterMetadata, nil
-}
\ No newline at end of file
+func (partitionRecord *PartitionRecord) encode() []byte {
+	encoder := metadataRecordValue(3, 1)
+	encoder.WriteInt32(partitionRecord.partitionId)
+	encoder.WriteUuid(partitionRecord.topicId)
+	encoder.WriteInt32Array(partitionRecord.replicaArray)
+	encoder.WriteInt32Array(partitionRecord.inSyncReplicaArray)
+	// removing and adding replicas
+	encoder.WriteInt32Array([]int32{})
+	encoder.WriteInt32Array([]int32{})
+	encoder.WriteInt32(partitionRecord.leader)
+	encoder.WriteInt32(partitionRecord.leaderEpoch)
+	encoder.WriteUint32(partitionRecord.partitionEpoch)
+	encoder.WriteArrayLength(len(partitionRecord.directoriesArray))
+	for _, directory := range partitionRecord.directoriesArray {
+		encoder.WriteUuid(directory)
+	}
+	taggedFields := map[uint64][]byte{}
+	if partitionRecord.leaderRecoveryState != 0 {
+		taggedFields[0] = []byte{byte(partitionRecord.leaderRecoveryState)}
+	}
+	encoder.WriteTaggedFields(taggedFields)
+	return encoder.Bytes()
+}
+
+func (configRecord *ConfigRecord) encode() []byte {
+	encoder := metadataRecordValue(4, 0)
+	encoder.WriteInt8(int8(configRecord.resourceType))
+	encoder.WriteString(configRecord.resourceName)
+	encoder.WriteString(configRecord.name)
+	if configRecord.valueIsNull {
+		encoder.WriteNullableString("")
+	} else {
+		encoder.WriteString(configRecord.value)
+	}
+	encoder.WriteTaggedFields(nil)
+	return encoder.Bytes()
+}
+
+func (removeTopicRecord *RemoveTopicRecord) encode() []byte {
+	encoder := metadataRecordValue(10, 0)
+	encoder.WriteUuid(removeTopicRecord.topicId)
+	encoder.WriteTaggedFields(nil)
+	return encoder.Bytes()
+}
+
+// appendClusterMetadata writes the given record values to the cluster
+// metadata log as a single batch.
+func appendClusterMetadata(values [][]byte) error {
+	metadataLog, err := getPartitionLog("__cluster_metadata", 0)
+	if err != nil {
+		return err
+	}
+
+	batch := newRecordBatch(0, time.Now().UnixMilli(), nil, values)
+	if _, err = metadataLog.append(batch); err != nil {
+		return err
+	}
+	return updateMetadataImage()
+}
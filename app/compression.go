@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Compression codecs, the values of the low three bits of the RecordBatch
+// attributes. Snappy, lz4 and zstd are implemented in this package as go.mod
+// can't take new dependencies.
+const (
+	compressionNone   int16 = 0
+	compressionGzip   int16 = 1
+	compressionSnappy int16 = 2
+	compressionLz4    int16 = 3
+	compressionZstd   int16 = 4
+)
+
+// maxDecompressedSize bounds what a compressed batch may claim to hold.
+const maxDecompressedSize = 1 << 30
+
+var compressionCodecNames = map[string]int16{
+	"uncompressed": compressionNone,
+	"gzip":         compressionGzip,
+	"snappy":       compressionSnappy,
+	"lz4":          compressionLz4,
+	"zstd":         compressionZstd,
+}
+
+// compressionCodecForType returns the codec of a compression.type config. The
+// "producer" type, keeping whatever the producer used, has none.
+func compressionCodecForType(compressionType string) (int16, bool) {
+	codec, ok := compressionCodecNames[compressionType]
+	return codec, ok
+}
+
+func compress(codec int16, data []byte) ([]byte, error) {
+	switch codec {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		compressed := &bytes.Buffer{}
+		writer := gzip.NewWriter(compressed)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return compressed.Bytes(), nil
+	case compressionSnappy:
+		return snappyCompress(data), nil
+	case compressionLz4:
+		return lz4Compress(data), nil
+	case compressionZstd:
+		return zstdCompress(data), nil
+	}
+	return nil, fmt.Errorf("unknown compression codec %d", codec)
+}
+
+func decompress(codec int16, data []byte) ([]byte, error) {
+	switch codec {
+	case compressionNone:
+		return data, nil
+	case compressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	case compressionSnappy:
+		return snappyDecompress(data)
+	case compressionLz4:
+		return lz4Decompress(data)
+	case compressionZstd:
+		return zstdDecompress(data)
+	}
+	return nil, fmt.Errorf("unknown compression codec %d", codec)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// Lz4
+
+// Kafka uses the lz4 frame format. Frames are written with independent 64KB
+// blocks and no checksums besides the header one.
+const (
+	lz4FrameMagic          = 0x184D2204
+	lz4SkippableFrameMagic = 0x184D2A50
+	lz4SkippableFrameMask  = 0xFFFFFFF0
+
+	lz4FlagVersion         = 0x40
+	lz4FlagBlockIndep      = 0x20
+	lz4FlagBlockChecksum   = 0x10
+	lz4FlagContentSize     = 0x08
+	lz4FlagContentChecksum = 0x04
+	lz4FlagDictId          = 0x01
+	lz4BlockMaxSize64KB    = 0x40
+	lz4BlockSize           = 64 * 1024
+	lz4UncompressedBlock   = 0x80000000
+
+	// the last match starts 12 bytes before the end of a block, the last 5
+	// bytes are always literals
+	lz4MatchFindLimit = 12
+	lz4LastLiterals   = 5
+)
+
+var errCorruptLz4 = errors.New("lz4 data is corrupt")
+
+func lz4Decompress(data []byte) ([]byte, error) {
+	decompressed := []byte{}
+	position := 0
+	for position < len(data) {
+		if position+4 > len(data) {
+			return nil, errCorruptLz4
+		}
+		magic := binary.LittleEndian.Uint32(data[position:])
+		position += 4
+
+		if magic&lz4SkippableFrameMask == lz4SkippableFrameMagic {
+			if position+4 > len(data) {
+				return nil, errCorruptLz4
+			}
+			frameSize := int(binary.LittleEndian.Uint32(data[position:]))
+			position += 4 + frameSize
+			continue
+		}
+		if magic != lz4FrameMagic || position+2 > len(data) {
+			return nil, errCorruptLz4
+		}
+
+		flags := data[position]
+		if flags&0xC0 != lz4FlagVersion {
+			return nil, errCorruptLz4
+		}
+		// flags, block descriptor and header checksum
+		position += 3
+		if flags&lz4FlagContentSize != 0 {
+			position += 8
+		}
+		if flags&lz4FlagDictId != 0 {
+			position += 4
+		}
+
+		frameStart := len(decompressed)
+		for {
+			if position+4 > len(data) {
+				return nil, errCorruptLz4
+			}
+			blockSize := binary.LittleEndian.Uint32(data[position:])
+			position += 4
+			if blockSize == 0 {
+				break
+			}
+
+			size := int(blockSize &^ lz4UncompressedBlock)
+			if size > len(data)-position {
+				return nil, errCorruptLz4
+			}
+			block := data[position : position+size]
+			if blockSize&lz4UncompressedBlock != 0 {
+				decompressed = append(decompressed, block...)
+			} else {
+				var err error
+				decompressed, err = lz4DecodeBlock(decompressed, frameStart, block)
+				if err != nil {
+					return nil, err
+				}
+			}
+			position += size
+			if flags&lz4FlagBlockChecksum != 0 {
+				position += 4
+			}
+		}
+		if flags&lz4FlagContentChecksum != 0 {
+			position += 4
+		}
+	}
+	return decompressed, nil
+}
+
+// lz4DecodeBlock appends the decoding of a block to dst. Matches may reach
+// back into earlier blocks of the frame starting at frameStart.
+func lz4DecodeBlock(dst []byte, frameStart int, block []byte) ([]byte, error) {
+	position := 0
+	for position < len(block) {
+		token := block[position]
+		position++
+
+		literalLength, n, ok := lz4ReadLength(block[position:], int(token>>4))
+		if !ok {
+			return nil, errCorruptLz4
+		}
+		position += n
+		if literalLength > len(block)-position || len(dst)+literalLength > maxDecompressedSize {
+			return nil, errCorruptLz4
+		}
+		dst = append(dst, block[position:position+literalLength]...)
+		position += literalLength
+
+		// the last sequence has only literals
+		if position == len(block) {
+			break
+		}
+
+		if position+2 > len(block) {
+			return nil, errCorruptLz4
+		}
+		offset := int(binary.LittleEndian.Uint16(block[position:]))
+		position += 2
+		matchLength, n, ok := lz4ReadLength(block[position:], int(token&0xF))
+		if !ok {
+			return nil, errCorruptLz4
+		}
+		position += n
+		matchLength += 4
+
+		if offset == 0 || offset > len(dst)-frameStart || len(dst)+matchLength > maxDecompressedSize {
+			return nil, errCorruptLz4
+		}
+		for i := 0; i < matchLength; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	return dst, nil
+}
+
+// lz4ReadLength reads the bytes extending a length of 15 from its token.
+func lz4ReadLength(data []byte, length int) (int, int, bool) {
+	if length != 15 {
+		return length, 0, true
+	}
+	for n := 0; n < len(data); n++ {
+		length += int(data[n])
+		if data[n] != 255 {
+			return length, n + 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+func lz4Compress(data []byte) []byte {
+	compressed := binary.LittleEndian.AppendUint32([]byte{}, lz4FrameMagic)
+	descriptor := []byte{lz4FlagVersion | lz4FlagBlockIndep, lz4BlockMaxSize64KB}
+	compressed = append(compressed, descriptor...)
+	compressed = append(compressed, byte(xxh32(descriptor, 0)>>8))
+
+	for start := 0; start < len(data); start += lz4BlockSize {
+		block := data[start:min(start+lz4BlockSize, len(data))]
+		encoded := lz4EncodeBlock(block)
+		if len(encoded) >= len(block) {
+			compressed = binary.LittleEndian.AppendUint32(compressed, uint32(len(block))|lz4UncompressedBlock)
+			compressed = append(compressed, block...)
+			continue
+		}
+		compressed = binary.LittleEndian.AppendUint32(compressed, uint32(len(encoded)))
+		compressed = append(compressed, encoded...)
+	}
+	// end mark
+	return binary.LittleEndian.AppendUint32(compressed, 0)
+}
+
+// lz4EncodeBlock is a greedy encoder, matching against the last position of
+// every four bytes seen.
+func lz4EncodeBlock(data []byte) []byte {
+	block := []byte{}
+	table := make([]int, 1<<14)
+	literalStart := 0
+	position := 0
+	for position+lz4MatchFindLimit < len(data) {
+		sequence := binary.LittleEndian.Uint32(data[position:])
+		hash := sequence * 2654435761 >> 18
+		candidate := table[hash] - 1
+		table[hash] = position + 1
+
+		if candidate < 0 || position-candidate > 0xffff || binary.LittleEndian.Uint32(data[candidate:]) != sequence {
+			position++
+			continue
+		}
+
+		matchLength := 4
+		for position+matchLength < len(data)-lz4LastLiterals && data[candidate+matchLength] == data[position+matchLength] {
+			matchLength++
+		}
+		block = lz4AppendSequence(block, data[literalStart:position], position-candidate, matchLength)
+		position += matchLength
+		literalStart = position
+	}
+	return lz4AppendSequence(block, data[literalStart:], 0, 0)
+}
+
+// lz4AppendSequence writes literals followed by a match, or just the literals
+// for the last sequence, whose matchLength is 0.
+func lz4AppendSequence(block []byte, literals []byte, offset int, matchLength int) []byte {
+	literalToken := min(len(literals), 15)
+	matchToken := 0
+	if matchLength > 0 {
+		matchToken = min(matchLength-4, 15)
+	}
+	block = append(block, byte(literalToken<<4|matchToken))
+	block = lz4AppendLength(block, len(literals))
+	block = append(block, literals...)
+	if matchLength == 0 {
+		return block
+	}
+	block = binary.LittleEndian.AppendUint16(block, uint16(offset))
+	return lz4AppendLength(block, matchLength-4)
+}
+
+func lz4AppendLength(block []byte, length int) []byte {
+	if length < 15 {
+		return block
+	}
+	for length -= 15; length >= 255; length -= 255 {
+		block = append(block, 255)
+	}
+	return append(block, byte(length))
+}
+
+const (
+	xxh32Prime1 uint32 = 2654435761
+	xxh32Prime2 uint32 = 2246822519
+	xxh32Prime3 uint32 = 3266489917
+	xxh32Prime4 uint32 = 668265263
+	xxh32Prime5 uint32 = 374761393
+)
+
+// xxh32 is the xxHash32 of data, used by the lz4 frame header checksum.
+func xxh32(data []byte, seed uint32) uint32 {
+	var hash uint32
+	position := 0
+	if len(data) >= 16 {
+		v1 := seed + xxh32Prime1 + xxh32Prime2
+		v2 := seed + xxh32Prime2
+		v3 := seed
+		v4 := seed - xxh32Prime1
+		round := func(v uint32, input uint32) uint32 {
+			return bits.RotateLeft32(v+input*xxh32Prime2, 13) * xxh32Prime1
+		}
+		for ; position+16 <= len(data); position += 16 {
+			v1 = round(v1, binary.LittleEndian.Uint32(data[position:]))
+			v2 = round(v2, binary.LittleEndian.Uint32(data[position+4:]))
+			v3 = round(v3, binary.LittleEndian.Uint32(data[position+8:]))
+			v4 = round(v4, binary.LittleEndian.Uint32(data[position+12:]))
+		}
+		hash = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		hash = seed + xxh32Prime5
+	}
+	hash += uint32(len(data))
+
+	for ; position+4 <= len(data); position += 4 {
+		hash += binary.LittleEndian.Uint32(data[position:]) * xxh32Prime3
+		hash = bits.RotateLeft32(hash, 17) * xxh32Prime4
+	}
+	for ; position < len(data); position++ {
+		hash += uint32(data[position]) * xxh32Prime5
+		hash = bits.RotateLeft32(hash, 11) * xxh32Prime1
+	}
+
+	hash ^= hash >> 15
+	hash *= xxh32Prime2
+	hash ^= hash >> 13
+	hash *= xxh32Prime3
+	hash ^= hash >> 16
+	return hash
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// Snappy
+
+// Kafka clients write snappy in the xerial framing, a header followed by
+// length prefixed snappy blocks. Some write a bare block, both are read.
+var snappyXerialHeader = []byte{0x82, 'S', 'N', 'A', 'P', 'P', 'Y', 0}
+
+const (
+	snappyXerialHeaderSize = 16
+	snappyXerialChunkSize  = 32 * 1024
+
+	snappyTagLiteral = 0
+	snappyTagCopy1   = 1
+	snappyTagCopy2   = 2
+	snappyTagCopy4   = 3
+)
+
+var errCorruptSnappy = errors.New("snappy data is corrupt")
+
+func snappyDecompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, snappyXerialHeader) {
+		return snappyDecodeBlock(nil, data)
+	}
+	if len(data) < snappyXerialHeaderSize {
+		return nil, errCorruptSnappy
+	}
+
+	decompressed := []byte{}
+	position := snappyXerialHeaderSize
+	for position < len(data) {
+		if position+4 > len(data) {
+			return nil, errCorruptSnappy
+		}
+		chunkSize := int(binary.BigEndian.Uint32(data[position:]))
+		position += 4
+		if chunkSize > len(data)-position {
+			return nil, errCorruptSnappy
+		}
+		var err error
+		decompressed, err = snappyDecodeBlock(decompressed, data[position:position+chunkSize])
+		if err != nil {
+			return nil, err
+		}
+		position += chunkSize
+	}
+	return decompressed, nil
+}
+
+// snappyDecodeBlock appends the decoding of a snappy block to dst.
+func snappyDecodeBlock(dst []byte, block []byte) ([]byte, error) {
+	length, n := binary.Uvarint(block)
+	if n <= 0 || length > maxDecompressedSize {
+		return nil, errCorruptSnappy
+	}
+	start := len(dst)
+	end := start + int(length)
+
+	position := n
+	for position < len(block) {
+		tag := block[position]
+		position++
+
+		var offset, elementLength int
+		switch tag & 3 {
+		case snappyTagLiteral:
+			elementLength = int(tag >> 2)
+			if elementLength >= 60 {
+				size := elementLength - 59
+				if position+size > len(block) {
+					return nil, errCorruptSnappy
+				}
+				elementLength = 0
+				for i := size - 1; i >= 0; i-- {
+					elementLength = elementLength<<8 | int(block[position+i])
+				}
+				position += size
+			}
+			elementLength++
+			if elementLength > len(block)-position || len(dst)+elementLength > end {
+				return nil, errCorruptSnappy
+			}
+			dst = append(dst, block[position:position+elementLength]...)
+			position += elementLength
+			continue
+		case snappyTagCopy1:
+			if position+1 > len(block) {
+				return nil, errCorruptSnappy
+			}
+			elementLength = int(tag>>2&7) + 4
+			offset = int(tag>>5)<<8 | int(block[position])
+			position++
+		case snappyTagCopy2:
+			if position+2 > len(block) {
+				return nil, errCorruptSnappy
+			}
+			elementLength = int(tag>>2) + 1
+			offset = int(binary.LittleEndian.Uint16(block[position:]))
+			position += 2
+		case snappyTagCopy4:
+			if position+4 > len(block) {
+				return nil, errCorruptSnappy
+			}
+			elementLength = int(tag>>2) + 1
+			offset = int(binary.LittleEndian.Uint32(block[position:]))
+			position += 4
+		}
+
+		if offset <= 0 || offset > len(dst)-start || len(dst)+elementLength > end {
+			return nil, errCorruptSnappy
+		}
+		// copies may overlap what they write, so byte by byte
+		for i := 0; i < elementLength; i++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+
+	if len(dst) != end {
+		return nil, errCorruptSnappy
+	}
+	return dst, nil
+}
+
+func snappyCompress(data []byte) []byte {
+	compressed := append([]byte{}, snappyXerialHeader...)
+	// version and compatible version
+	compressed = binary.BigEndian.AppendUint32(compressed, 1)
+	compressed = binary.BigEndian.AppendUint32(compressed, 1)
+
+	for start := 0; start < len(data); start += snappyXerialChunkSize {
+		chunk := data[start:min(start+snappyXerialChunkSize, len(data))]
+		block := snappyEncodeBlock(chunk)
+		compressed = binary.BigEndian.AppendUint32(compressed, uint32(len(block)))
+		compressed = append(compressed, block...)
+	}
+	return compressed
+}
+
+// snappyEncodeBlock is a greedy encoder, copying the last match of every four
+// bytes seen.
+func snappyEncodeBlock(data []byte) []byte {
+	block := binary.AppendUvarint([]byte{}, uint64(len(data)))
+
+	table := make([]int, 1<<14)
+	literalStart := 0
+	position := 0
+	for position+4 <= len(data) {
+		sequence := binary.LittleEndian.Uint32(data[position:])
+		hash := sequence * 0x1e35a7bd >> 18
+		candidate := table[hash] - 1
+		table[hash] = position + 1
+
+		if candidate < 0 || position-candidate > 0xffff || binary.LittleEndian.Uint32(data[candidate:]) != sequence {
+			position++
+			continue
+		}
+
+		matchLength := 4
+		for position+matchLength < len(data) && data[candidate+matchLength] == data[position+matchLength] {
+			matchLength++
+		}
+		block = snappyAppendLiteral(block, data[literalStart:position])
+		block = snappyAppendCopy(block, position-candidate, matchLength)
+		position += matchLength
+		literalStart = position
+	}
+	return snappyAppendLiteral(block, data[literalStart:])
+}
+
+func snappyAppendLiteral(block []byte, literal []byte) []byte {
+	if len(literal) == 0 {
+		return block
+	}
+	length := len(literal) - 1
+	switch {
+	case length < 60:
+		block = append(block, byte(length<<2|snappyTagLiteral))
+	case length < 1<<8:
+		block = append(block, 60<<2|snappyTagLiteral, byte(length))
+	case length < 1<<16:
+		block = append(block, 61<<2|snappyTagLiteral, byte(length), byte(length>>8))
+	default:
+		block = append(block, 63<<2|snappyTagLiteral)
+		block = binary.LittleEndian.AppendUint32(block, uint32(length))
+	}
+	return append(block, literal...)
+}
+
+// snappyAppendCopy writes a match as copies of at most 64 bytes, each using a
+// two byte offset.
+func snappyAppendCopy(block []byte, offset int, length int) []byte {
+	for length > 0 {
+		copyLength := min(length, 64)
+		block = append(block, byte((copyLength-1)<<2|snappyTagCopy2))
+		block = binary.LittleEndian.AppendUint16(block, uint16(offset))
+		length -= copyLength
+	}
+	return block
+}
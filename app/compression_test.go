@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+// The frames in testdata/compression were written by other implementations,
+// from compressionTestInput(name):
+//
+//	zstd -1, -3 and -19, the zstd cli
+//	lz4 -1 and -9 --content-size -BX, the lz4 cli, and pierrec/lz4 as franz-go writes it
+//	s2.EncodeSnappy from klauspost/compress as franz-go writes it, bare and
+//	in 32KB xerial chunks as the java client writes it
+//	gzip -9, the gzip cli
+func compressionTestInput(name string) []byte {
+	random := rand.New(rand.NewSource(1))
+	switch name {
+	case "letters":
+		// nothing repeats, zstd writes it as literals only
+		letters := make([]byte, 400)
+		for i := range letters {
+			letters[i] = byte('a' + random.Intn(26))
+		}
+		return letters
+	case "records":
+		users := []string{"alice", "bob", "carol", "dave", "erin", "frank"}
+		events := []string{"login", "logout", "click", "purchase", "view"}
+		records := &bytes.Buffer{}
+		for i := 0; records.Len() < 200_000; i++ {
+			fmt.Fprintf(records, `{"id":%d,"user":"%s","event":"%s","amount":%d,"ts":%d}`+"\n",
+				i, users[random.Intn(len(users))], events[random.Intn(len(events))], random.Intn(100_000), 1_700_000_000_000+int64(i)*random.Int63n(1000))
+		}
+		return records.Bytes()
+	case "random":
+		data := make([]byte, 20_000)
+		random.Read(data)
+		return data
+	}
+	return nil
+}
+
+func TestDecompressForeignFrames(t *testing.T) {
+	tests := []struct {
+		file  string
+		codec int16
+		input string
+	}{
+		{"letters.3.zst", compressionZstd, "letters"},
+		{"records.1.zst", compressionZstd, "records"},
+		{"records.3.zst", compressionZstd, "records"},
+		{"records.19.zst", compressionZstd, "records"},
+		{"random.3.zst", compressionZstd, "random"},
+		{"letters.lz4", compressionLz4, "letters"},
+		{"records.1.lz4", compressionLz4, "records"},
+		{"records.9.lz4", compressionLz4, "records"},
+		{"records.pierrec.lz4", compressionLz4, "records"},
+		{"random.lz4", compressionLz4, "random"},
+		{"letters.snappy", compressionSnappy, "letters"},
+		{"records.snappy", compressionSnappy, "records"},
+		{"records.xerial.snappy", compressionSnappy, "records"},
+		{"random.xerial.snappy", compressionSnappy, "random"},
+		{"letters.gz", compressionGzip, "letters"},
+		{"records.gz", compressionGzip, "records"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			frame, err := os.ReadFile("testdata/compression/" + test.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decompressed, err := decompress(test.codec, frame)
+			if err != nil {
+				t.Fatalf("decompress: %s", err)
+			}
+			if want := compressionTestInput(test.input); !bytes.Equal(decompressed, want) {
+				t.Fatalf("decompressed %d bytes, want the %d bytes of %s", len(decompressed), len(want), test.input)
+			}
+		})
+	}
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	for _, input := range []string{"letters", "records", "random"} {
+		for name, codec := range compressionCodecNames {
+			t.Run(input+"/"+name, func(t *testing.T) {
+				want := compressionTestInput(input)
+				compressed, err := compress(codec, want)
+				if err != nil {
+					t.Fatalf("compress: %s", err)
+				}
+				decompressed, err := decompress(codec, compressed)
+				if err != nil {
+					t.Fatalf("decompress: %s", err)
+				}
+				if !bytes.Equal(decompressed, want) {
+					t.Fatalf("decompressed %d bytes, want %d", len(decompressed), len(want))
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,1087 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
+
+// Zstd
+
+// The decoder handles everything RFC 8878 frames can hold but dictionaries.
+// The encoder is a greedy matcher writing raw literals and sequences with the
+// predefined FSE tables, which every decoder knows.
+const (
+	zstdFrameMagic          = 0xFD2FB528
+	zstdSkippableFrameMagic = 0x184D2A50
+	zstdSkippableFrameMask  = 0xFFFFFFF0
+
+	zstdBlockRaw        = 0
+	zstdBlockRle        = 1
+	zstdBlockCompressed = 2
+	zstdMaxBlockSize    = 128 * 1024
+
+	zstdLiteralsRaw        = 0
+	zstdLiteralsRle        = 1
+	zstdLiteralsCompressed = 2
+	zstdLiteralsTreeless   = 3
+
+	zstdModePredefined = 0
+	zstdModeRle        = 1
+	zstdModeCompressed = 2
+	zstdModeRepeat     = 3
+
+	zstdMaxHuffmanBits   = 11
+	zstdMaxMatchOffset   = 1 << 24
+	zstdMaxLiteralLength = 35
+	zstdMaxMatchLength   = 52
+	zstdMaxOffsetCode    = 31
+)
+
+var errCorruptZstd = errors.New("zstd data is corrupt")
+
+var (
+	zstdLiteralLengthBase = [36]int{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+		16, 18, 20, 22, 24, 28, 32, 40, 48, 64, 128, 256, 512, 1024, 2048, 4096,
+		8192, 16384, 32768, 65536,
+	}
+	zstdLiteralLengthBits = [36]int{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 6, 7, 8, 9, 10, 11, 12,
+		13, 14, 15, 16,
+	}
+	zstdMatchLengthBase = [53]int{
+		3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18,
+		19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34,
+		35, 37, 39, 41, 43, 47, 51, 59, 67, 83, 99, 131, 259, 515, 1027, 2051,
+		4099, 8195, 16387, 32771, 65539,
+	}
+	zstdMatchLengthBits = [53]int{
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 1, 2, 2, 3, 3, 4, 4, 5, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16,
+	}
+
+	zstdPredefinedLiteralLengths = []int16{
+		4, 3, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 1, 1, 1,
+		2, 2, 2, 2, 2, 2, 2, 2, 2, 3, 2, 1, 1, 1, 1, 1,
+		-1, -1, -1, -1,
+	}
+	zstdPredefinedMatchLengths = []int16{
+		1, 4, 3, 2, 2, 2, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, -1, -1,
+		-1, -1, -1, -1, -1,
+	}
+	zstdPredefinedOffsets = []int16{
+		1, 1, 1, 1, 1, 1, 2, 2, 2, 1, 1, 1, 1, 1, 1, 1,
+		1, 1, 1, 1, 1, 1, 1, 1, -1, -1, -1, -1, -1,
+	}
+)
+
+// The limits of the three kinds of sequence symbols, in bitstream order.
+type zstdSequenceKind struct {
+	maxAccuracyLog int
+	maxSymbol      int
+}
+
+var zstdSequenceKinds = [3]zstdSequenceKind{
+	{9, zstdMaxLiteralLength},
+	{8, zstdMaxOffsetCode},
+	{9, zstdMaxMatchLength},
+}
+
+var zstdPredefinedTables = [3]*zstdFseTable{
+	zstdMustBuildFseTable(zstdPredefinedLiteralLengths, 6),
+	zstdMustBuildFseTable(zstdPredefinedOffsets, 5),
+	zstdMustBuildFseTable(zstdPredefinedMatchLengths, 6),
+}
+
+// zstdForwardBits reads a bitstream from its first byte, least significant
+// bit first. Reads past the end give zeros.
+type zstdForwardBits struct {
+	data     []byte
+	position int
+}
+
+func (reader *zstdForwardBits) peek(n int) uint32 {
+	var value uint32
+	for i := 0; i < n; i++ {
+		bit := reader.position + i
+		if bit>>3 < len(reader.data) {
+			value |= uint32(reader.data[bit>>3]>>(bit&7)&1) << i
+		}
+	}
+	return value
+}
+
+func (reader *zstdForwardBits) read(n int) uint32 {
+	value := reader.peek(n)
+	reader.position += n
+	return value
+}
+
+// zstdBackwardBits reads a bitstream from its end, the way FSE and huffman
+// streams are written. The highest set bit of the last byte marks the end.
+type zstdBackwardBits struct {
+	data []byte
+	// bits left to read, negative once reads went past the start
+	position int
+}
+
+func newZstdBackwardBits(data []byte) (*zstdBackwardBits, error) {
+	if len(data) == 0 || data[len(data)-1] == 0 {
+		return nil, errCorruptZstd
+	}
+	position := 8*(len(data)-1) + bits.Len8(data[len(data)-1]) - 1
+	return &zstdBackwardBits{data: data, position: position}, nil
+}
+
+// peek returns the next n bits, zeros standing in for those past the start.
+func (reader *zstdBackwardBits) peek(n int) uint64 {
+	if n == 0 || reader.position <= 0 {
+		return 0
+	}
+	start := reader.position - n
+	if start >= 0 {
+		return reader.bitsAt(start, n)
+	}
+	return reader.bitsAt(0, reader.position) << -start
+}
+
+func (reader *zstdBackwardBits) read(n int) uint64 {
+	value := reader.peek(n)
+	reader.position -= n
+	return value
+}
+
+func (reader *zstdBackwardBits) bitsAt(start int, n int) uint64 {
+	var value uint64
+	for i := 0; i < 8 && start>>3+i < len(reader.data); i++ {
+		value |= uint64(reader.data[start>>3+i]) << (8 * i)
+	}
+	return value >> (start & 7) & (1<<n - 1)
+}
+
+type zstdFseEntry struct {
+	symbol uint8
+	bits   uint8
+	base   uint16
+}
+
+type zstdFseTable struct {
+	accuracyLog int
+	entries     []zstdFseEntry
+}
+
+// update moves a state to the next one, reading its bits.
+func (table *zstdFseTable) update(state uint64, reader *zstdBackwardBits) uint64 {
+	entry := table.entries[state]
+	return uint64(entry.base) + reader.read(int(entry.bits))
+}
+
+func zstdMustBuildFseTable(normalized []int16, accuracyLog int) *zstdFseTable {
+	table, err := zstdBuildFseTable(normalized, accuracyLog)
+	if err != nil {
+		panic(err)
+	}
+	return table
+}
+
+// zstdBuildFseTable builds a decoding table from normalized symbol counts, a
+// count of -1 standing for a "less than one" probability.
+func zstdBuildFseTable(normalized []int16, accuracyLog int) (*zstdFseTable, error) {
+	tableSize := 1 << accuracyLog
+	entries := make([]zstdFseEntry, tableSize)
+	nextStates := make([]int, len(normalized))
+
+	highThreshold := tableSize - 1
+	for symbol, count := range normalized {
+		if count == -1 {
+			if highThreshold < 0 {
+				return nil, errCorruptZstd
+			}
+			entries[highThreshold].symbol = uint8(symbol)
+			highThreshold--
+			nextStates[symbol] = 1
+		} else {
+			nextStates[symbol] = int(count)
+		}
+	}
+
+	mask := tableSize - 1
+	step := tableSize>>1 + tableSize>>3 + 3
+	position := 0
+	for symbol, count := range normalized {
+		for i := 0; i < int(count); i++ {
+			entries[position].symbol = uint8(symbol)
+			position = (position + step) & mask
+			for position > highThreshold {
+				position = (position + step) & mask
+			}
+		}
+	}
+	if position != 0 {
+		return nil, errCorruptZstd
+	}
+
+	for i := range entries {
+		symbol := entries[i].symbol
+		nextState := nextStates[symbol]
+		nextStates[symbol]++
+		entryBits := accuracyLog - (bits.Len(uint(nextState)) - 1)
+		entries[i].bits = uint8(entryBits)
+		entries[i].base = uint16(nextState<<entryBits - tableSize)
+	}
+	return &zstdFseTable{accuracyLog: accuracyLog, entries: entries}, nil
+}
+
+// zstdReadFseTable reads the normalized counts of an FSE table description
+// and builds its table. It returns the number of bytes read.
+func zstdReadFseTable(data []byte, maxSymbol int, maxAccuracyLog int) (*zstdFseTable, int, error) {
+	reader := &zstdForwardBits{data: data}
+	accuracyLog := int(reader.read(4)) + 5
+	if accuracyLog > maxAccuracyLog {
+		return nil, 0, errCorruptZstd
+	}
+
+	remaining := 1<<accuracyLog + 1
+	threshold := 1 << accuracyLog
+	countBits := accuracyLog + 1
+	normalized := []int16{}
+	previousZero := false
+	for remaining > 1 && len(normalized) <= maxSymbol {
+		if previousZero {
+			for {
+				repeat := int(reader.read(2))
+				for i := 0; i < repeat; i++ {
+					normalized = append(normalized, 0)
+				}
+				if repeat != 3 {
+					break
+				}
+			}
+			if len(normalized) > maxSymbol {
+				return nil, 0, errCorruptZstd
+			}
+		}
+
+		// small values take one bit less
+		max := 2*threshold - 1 - remaining
+		count := int(reader.peek(countBits - 1))
+		if count < max {
+			reader.position += countBits - 1
+		} else {
+			count = int(reader.read(countBits))
+			if count >= threshold {
+				count -= max
+			}
+		}
+		count--
+		if count < 0 {
+			remaining--
+		} else {
+			remaining -= count
+		}
+		if remaining < 1 {
+			return nil, 0, errCorruptZstd
+		}
+		normalized = append(normalized, int16(count))
+		previousZero = count == 0
+		for remaining < threshold {
+			countBits--
+			threshold >>= 1
+		}
+	}
+
+	size := (reader.position + 7) / 8
+	if remaining != 1 || size > len(data) {
+		return nil, 0, errCorruptZstd
+	}
+	table, err := zstdBuildFseTable(normalized, accuracyLog)
+	return table, size, err
+}
+
+type zstdHuffmanEntry struct {
+	symbol uint8
+	bits   uint8
+}
+
+type zstdHuffmanTable struct {
+	maxBits int
+	entries []zstdHuffmanEntry
+}
+
+// zstdReadHuffmanTable reads a huffman tree description, the weights of the
+// symbols either FSE compressed or four bits each. The weight of the last
+// symbol is implied. It returns the number of bytes read.
+func zstdReadHuffmanTable(data []byte) (*zstdHuffmanTable, int, error) {
+	if len(data) == 0 {
+		return nil, 0, errCorruptZstd
+	}
+	header := int(data[0])
+	weights := []uint8{}
+	size := 0
+
+	if header >= 128 {
+		count := header - 127
+		size = 1 + (count+1)/2
+		if size > len(data) {
+			return nil, 0, errCorruptZstd
+		}
+		for i := 0; i < count; i++ {
+			weight := data[1+i/2]
+			if i%2 == 0 {
+				weight >>= 4
+			}
+			weights = append(weights, weight&0xF)
+		}
+	} else {
+		size = 1 + header
+		if size > len(data) {
+			return nil, 0, errCorruptZstd
+		}
+		table, n, err := zstdReadFseTable(data[1:size], 255, 6)
+		if err != nil {
+			return nil, 0, err
+		}
+		reader, err := newZstdBackwardBits(data[1+n : size])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// two interleaved states share the table until the stream runs out
+		states := [2]uint64{reader.read(table.accuracyLog), reader.read(table.accuracyLog)}
+		for i := 0; ; i ^= 1 {
+			if len(weights) > 255 {
+				return nil, 0, errCorruptZstd
+			}
+			weights = append(weights, table.entries[states[i]].symbol)
+			states[i] = table.update(states[i], reader)
+			if reader.position < 0 {
+				weights = append(weights, table.entries[states[i^1]].symbol)
+				break
+			}
+		}
+	}
+
+	weightSum := 0
+	for _, weight := range weights {
+		if weight > zstdMaxHuffmanBits {
+			return nil, 0, errCorruptZstd
+		}
+		if weight > 0 {
+			weightSum += 1 << (weight - 1)
+		}
+	}
+	if weightSum == 0 {
+		return nil, 0, errCorruptZstd
+	}
+	maxBits := bits.Len(uint(weightSum))
+	lastWeightSum := 1<<maxBits - weightSum
+	if maxBits > zstdMaxHuffmanBits || lastWeightSum&(lastWeightSum-1) != 0 || len(weights) > 255 {
+		return nil, 0, errCorruptZstd
+	}
+	weights = append(weights, uint8(bits.Len(uint(lastWeightSum))))
+
+	// codes of the same length are laid out in symbol order, longest first
+	rankCounts := make([]int, maxBits+1)
+	for _, weight := range weights {
+		rankCounts[weight]++
+	}
+	rankStarts := make([]int, maxBits+1)
+	position := 0
+	for weight := 1; weight <= maxBits; weight++ {
+		rankStarts[weight] = position
+		position += rankCounts[weight] << (weight - 1)
+	}
+
+	entries := make([]zstdHuffmanEntry, 1<<maxBits)
+	for symbol, weight := range weights {
+		if weight == 0 {
+			continue
+		}
+		length := 1 << (weight - 1)
+		entry := zstdHuffmanEntry{symbol: uint8(symbol), bits: uint8(maxBits + 1 - int(weight))}
+		for i := rankStarts[weight]; i < rankStarts[weight]+length; i++ {
+			entries[i] = entry
+		}
+		rankStarts[weight] += length
+	}
+	return &zstdHuffmanTable{maxBits: maxBits, entries: entries}, size, nil
+}
+
+// decodeStream appends the count symbols of a huffman stream to dst.
+func (table *zstdHuffmanTable) decodeStream(dst []byte, stream []byte, count int) ([]byte, error) {
+	reader, err := newZstdBackwardBits(stream)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < count; i++ {
+		entry := table.entries[reader.peek(table.maxBits)]
+		reader.position -= int(entry.bits)
+		dst = append(dst, entry.symbol)
+	}
+	if reader.position != 0 {
+		return nil, errCorruptZstd
+	}
+	return dst, nil
+}
+
+// zstdDecoder holds what the blocks of a frame share.
+type zstdDecoder struct {
+	output         []byte
+	frameStart     int
+	repeatOffsets  [3]int
+	huffmanTable   *zstdHuffmanTable
+	sequenceTables [3]*zstdFseTable
+}
+
+func zstdDecompress(data []byte) ([]byte, error) {
+	decoder := &zstdDecoder{output: []byte{}}
+	position := 0
+	for position < len(data) {
+		if position+4 > len(data) {
+			return nil, errCorruptZstd
+		}
+		magic := binary.LittleEndian.Uint32(data[position:])
+		position += 4
+
+		if magic&zstdSkippableFrameMask == zstdSkippableFrameMagic {
+			if position+4 > len(data) {
+				return nil, errCorruptZstd
+			}
+			position += 4 + int(binary.LittleEndian.Uint32(data[position:]))
+			continue
+		}
+		if magic != zstdFrameMagic {
+			return nil, errCorruptZstd
+		}
+
+		n, err := decoder.decodeFrame(data[position:])
+		if err != nil {
+			return nil, err
+		}
+		position += n
+	}
+	return decoder.output, nil
+}
+
+// decodeFrame decodes the frame following a magic number and returns its
+// size. The content checksum isn't checked, the batch crc covers the frame.
+func (decoder *zstdDecoder) decodeFrame(data []byte) (int, error) {
+	if len(data) == 0 {
+		return 0, errCorruptZstd
+	}
+	descriptor := data[0]
+	position := 1
+
+	singleSegment := descriptor&0x20 != 0
+	hasChecksum := descriptor&0x04 != 0
+	if descriptor&0x08 != 0 {
+		return 0, errCorruptZstd
+	}
+	if !singleSegment {
+		// window descriptor, the whole content is kept anyway
+		position++
+	}
+	dictionaryIdSize := [4]int{0, 1, 2, 4}[descriptor&3]
+	for i := 0; i < dictionaryIdSize; i++ {
+		if position+i < len(data) && data[position+i] != 0 {
+			return 0, errors.New("zstd dictionaries are not supported")
+		}
+	}
+	position += dictionaryIdSize
+	contentSizeSize := [4]int{0, 2, 4, 8}[descriptor>>6]
+	if contentSizeSize == 0 && singleSegment {
+		contentSizeSize = 1
+	}
+	position += contentSizeSize
+
+	decoder.frameStart = len(decoder.output)
+	decoder.repeatOffsets = [3]int{1, 4, 8}
+	decoder.huffmanTable = nil
+	decoder.sequenceTables = [3]*zstdFseTable{}
+
+	for last := false; !last; {
+		if position+3 > len(data) {
+			return 0, errCorruptZstd
+		}
+		header := int(data[position]) | int(data[position+1])<<8 | int(data[position+2])<<16
+		position += 3
+		last = header&1 != 0
+		blockSize := header >> 3
+
+		switch header >> 1 & 3 {
+		case zstdBlockRaw:
+			if blockSize > len(data)-position || len(decoder.output)+blockSize > maxDecompressedSize {
+				return 0, errCorruptZstd
+			}
+			decoder.output = append(decoder.output, data[position:position+blockSize]...)
+			position += blockSize
+		case zstdBlockRle:
+			if position+1 > len(data) || len(decoder.output)+blockSize > maxDecompressedSize {
+				return 0, errCorruptZstd
+			}
+			decoder.output = append(decoder.output, bytes.Repeat(data[position:position+1], blockSize)...)
+			position++
+		case zstdBlockCompressed:
+			if blockSize > len(data)-position || blockSize > zstdMaxBlockSize {
+				return 0, errCorruptZstd
+			}
+			if err := decoder.decodeCompressedBlock(data[position : position+blockSize]); err != nil {
+				return 0, err
+			}
+			position += blockSize
+		default:
+			return 0, errCorruptZstd
+		}
+	}
+
+	if hasChecksum {
+		position += 4
+	}
+	if position > len(data) {
+		return 0, errCorruptZstd
+	}
+	return position, nil
+}
+
+func (decoder *zstdDecoder) decodeCompressedBlock(block []byte) error {
+	literals, n, err := decoder.readLiterals(block)
+	if err != nil {
+		return err
+	}
+	return decoder.executeSequences(block[n:], literals)
+}
+
+// readLiterals reads the literals section of a block and returns the
+// literals and the size of the section.
+func (decoder *zstdDecoder) readLiterals(block []byte) ([]byte, int, error) {
+	if len(block) == 0 {
+		return nil, 0, errCorruptZstd
+	}
+	literalsType := block[0] & 3
+	sizeFormat := block[0] >> 2 & 3
+
+	if literalsType == zstdLiteralsRaw || literalsType == zstdLiteralsRle {
+		size, headerSize := int(block[0]>>3), 1
+		switch sizeFormat {
+		case 1:
+			if len(block) < 2 {
+				return nil, 0, errCorruptZstd
+			}
+			size, headerSize = int(block[0]>>4)|int(block[1])<<4, 2
+		case 3:
+			if len(block) < 3 {
+				return nil, 0, errCorruptZstd
+			}
+			size, headerSize = int(block[0]>>4)|int(block[1])<<4|int(block[2])<<12, 3
+		}
+
+		if literalsType == zstdLiteralsRle {
+			if headerSize+1 > len(block) {
+				return nil, 0, errCorruptZstd
+			}
+			return bytes.Repeat(block[headerSize:headerSize+1], size), headerSize + 1, nil
+		}
+		if headerSize+size > len(block) {
+			return nil, 0, errCorruptZstd
+		}
+		return block[headerSize : headerSize+size], headerSize + size, nil
+	}
+
+	streams, headerSize, sizeBits := 4, 3, 10
+	switch sizeFormat {
+	case 0:
+		streams = 1
+	case 2:
+		headerSize, sizeBits = 4, 14
+	case 3:
+		headerSize, sizeBits = 5, 18
+	}
+	if headerSize > len(block) {
+		return nil, 0, errCorruptZstd
+	}
+	var header uint64
+	for i := 0; i < headerSize; i++ {
+		header |= uint64(block[i]) << (8 * i)
+	}
+	regeneratedSize := int(header >> 4 & (1<<sizeBits - 1))
+	compressedSize := int(header >> (4 + sizeBits) & (1<<sizeBits - 1))
+	if headerSize+compressedSize > len(block) {
+		return nil, 0, errCorruptZstd
+	}
+	data := block[headerSize : headerSize+compressedSize]
+
+	if literalsType == zstdLiteralsCompressed {
+		table, n, err := zstdReadHuffmanTable(data)
+		if err != nil {
+			return nil, 0, err
+		}
+		decoder.huffmanTable = table
+		data = data[n:]
+	} else if decoder.huffmanTable == nil {
+		return nil, 0, errCorruptZstd
+	}
+
+	if streams == 1 {
+		literals, err := decoder.huffmanTable.decodeStream(make([]byte, 0, regeneratedSize), data, regeneratedSize)
+		return literals, headerSize + compressedSize, err
+	}
+
+	// a jump table holds the sizes of the first three streams
+	if len(data) < 6 {
+		return nil, 0, errCorruptZstd
+	}
+	streamSizes := [4]int{
+		int(binary.LittleEndian.Uint16(data)),
+		int(binary.LittleEndian.Uint16(data[2:])),
+		int(binary.LittleEndian.Uint16(data[4:])),
+	}
+	streamSizes[3] = len(data) - 6 - streamSizes[0] - streamSizes[1] - streamSizes[2]
+	segmentSize := (regeneratedSize + 3) / 4
+	if streamSizes[3] < 0 || regeneratedSize < 3*segmentSize {
+		return nil, 0, errCorruptZstd
+	}
+
+	literals := make([]byte, 0, regeneratedSize)
+	position := 6
+	for i, streamSize := range streamSizes {
+		count := segmentSize
+		if i == 3 {
+			count = regeneratedSize - 3*segmentSize
+		}
+		var err error
+		literals, err = decoder.huffmanTable.decodeStream(literals, data[position:position+streamSize], count)
+		if err != nil {
+			return nil, 0, err
+		}
+		position += streamSize
+	}
+	return literals, headerSize + compressedSize, nil
+}
+
+// readSequenceTable returns the table of one kind of sequence symbols, and
+// the number of bytes its description took.
+func (decoder *zstdDecoder) readSequenceTable(kind int, mode byte, data []byte) (*zstdFseTable, int, error) {
+	switch mode {
+	case zstdModePredefined:
+		return zstdPredefinedTables[kind], 0, nil
+	case zstdModeRle:
+		if len(data) == 0 || int(data[0]) > zstdSequenceKinds[kind].maxSymbol {
+			return nil, 0, errCorruptZstd
+		}
+		return &zstdFseTable{entries: []zstdFseEntry{{symbol: data[0]}}}, 1, nil
+	case zstdModeCompressed:
+		return zstdReadFseTable(data, zstdSequenceKinds[kind].maxSymbol, zstdSequenceKinds[kind].maxAccuracyLog)
+	}
+	if decoder.sequenceTables[kind] == nil {
+		return nil, 0, errCorruptZstd
+	}
+	return decoder.sequenceTables[kind], 0, nil
+}
+
+// executeSequences reads the sequences section of a block and appends what
+// its sequences and literals decode to to the output.
+func (decoder *zstdDecoder) executeSequences(section []byte, literals []byte) error {
+	if len(section) == 0 {
+		return errCorruptZstd
+	}
+	count, position := int(section[0]), 1
+	switch {
+	case section[0] == 255:
+		if len(section) < 3 {
+			return errCorruptZstd
+		}
+		count, position = int(section[1])|int(section[2])<<8+0x7F00, 3
+	case section[0] >= 128:
+		if len(section) < 2 {
+			return errCorruptZstd
+		}
+		count, position = int(section[0]-128)<<8|int(section[1]), 2
+	}
+
+	// a block without sequences is only literals
+	if count == 0 {
+		decoder.output = append(decoder.output, literals...)
+		return nil
+	}
+
+	if position >= len(section) {
+		return errCorruptZstd
+	}
+	modes := section[position]
+	position++
+	if modes&3 != 0 {
+		return errCorruptZstd
+	}
+	for kind := range zstdSequenceKinds {
+		table, n, err := decoder.readSequenceTable(kind, modes>>(6-2*kind)&3, section[position:])
+		if err != nil {
+			return err
+		}
+		decoder.sequenceTables[kind] = table
+		position += n
+	}
+	return decoder.decodeSequences(section[position:], count, literals)
+}
+
+func (decoder *zstdDecoder) decodeSequences(stream []byte, count int, literals []byte) error {
+	reader, err := newZstdBackwardBits(stream)
+	if err != nil {
+		return err
+	}
+	literalLengths, offsets, matchLengths := decoder.sequenceTables[0], decoder.sequenceTables[1], decoder.sequenceTables[2]
+	literalLengthState := reader.read(literalLengths.accuracyLog)
+	offsetState := reader.read(offsets.accuracyLog)
+	matchLengthState := reader.read(matchLengths.accuracyLog)
+
+	literalPosition := 0
+	for i := 0; i < count; i++ {
+		literalLengthCode := int(literalLengths.entries[literalLengthState].symbol)
+		offsetCode := int(offsets.entries[offsetState].symbol)
+		matchLengthCode := int(matchLengths.entries[matchLengthState].symbol)
+		if literalLengthCode > zstdMaxLiteralLength || offsetCode > zstdMaxOffsetCode || matchLengthCode > zstdMaxMatchLength {
+			return errCorruptZstd
+		}
+
+		offsetValue := 1<<offsetCode + int(reader.read(offsetCode))
+		matchLength := zstdMatchLengthBase[matchLengthCode] + int(reader.read(zstdMatchLengthBits[matchLengthCode]))
+		literalLength := zstdLiteralLengthBase[literalLengthCode] + int(reader.read(zstdLiteralLengthBits[literalLengthCode]))
+		offset := decoder.resolveOffset(offsetValue, literalLength)
+
+		if i != count-1 {
+			literalLengthState = literalLengths.update(literalLengthState, reader)
+			matchLengthState = matchLengths.update(matchLengthState, reader)
+			offsetState = offsets.update(offsetState, reader)
+		}
+
+		if literalLength > len(literals)-literalPosition {
+			return errCorruptZstd
+		}
+		decoder.output = append(decoder.output, literals[literalPosition:literalPosition+literalLength]...)
+		literalPosition += literalLength
+
+		if offset <= 0 || offset > len(decoder.output)-decoder.frameStart || len(decoder.output)+matchLength > maxDecompressedSize {
+			return errCorruptZstd
+		}
+		for j := 0; j < matchLength; j++ {
+			decoder.output = append(decoder.output, decoder.output[len(decoder.output)-offset])
+		}
+	}
+	if reader.position != 0 {
+		return errCorruptZstd
+	}
+
+	decoder.output = append(decoder.output, literals[literalPosition:]...)
+	return nil
+}
+
+// resolveOffset turns an offset value into an offset, values up to 3 picking
+// one of the last three offsets used.
+func (decoder *zstdDecoder) resolveOffset(offsetValue int, literalLength int) int {
+	repeats := &decoder.repeatOffsets
+	if offsetValue > 3 {
+		offset := offsetValue - 3
+		*repeats = [3]int{offset, repeats[0], repeats[1]}
+		return offset
+	}
+
+	index := offsetValue - 1
+	if literalLength == 0 {
+		index++
+	}
+	switch index {
+	case 1:
+		*repeats = [3]int{repeats[1], repeats[0], repeats[2]}
+	case 2:
+		*repeats = [3]int{repeats[2], repeats[0], repeats[1]}
+	case 3:
+		*repeats = [3]int{repeats[0] - 1, repeats[0], repeats[1]}
+	}
+	return repeats[0]
+}
+
+type zstdSequence struct {
+	literalLength int
+	matchLength   int
+	offset        int
+}
+
+func zstdCompress(data []byte) []byte {
+	frame := binary.LittleEndian.AppendUint32([]byte{}, zstdFrameMagic)
+
+	// a single segment frame with the content size and no checksum
+	switch size := len(data); {
+	case size < 256:
+		frame = append(frame, 0x20, byte(size))
+	case size < 65536+256:
+		frame = binary.LittleEndian.AppendUint16(append(frame, 0x60), uint16(size-256))
+	default:
+		frame = binary.LittleEndian.AppendUint32(append(frame, 0xA0), uint32(size))
+	}
+
+	if len(data) == 0 {
+		return zstdAppendBlockHeader(frame, true, zstdBlockRaw, 0)
+	}
+
+	table := make([]int, 1<<16)
+	for start := 0; start < len(data); start += zstdMaxBlockSize {
+		end := min(start+zstdMaxBlockSize, len(data))
+		last := end == len(data)
+		block := zstdEncodeBlock(data, start, end, table)
+		if len(block) >= end-start {
+			frame = zstdAppendBlockHeader(frame, last, zstdBlockRaw, end-start)
+			frame = append(frame, data[start:end]...)
+			continue
+		}
+		frame = zstdAppendBlockHeader(frame, last, zstdBlockCompressed, len(block))
+		frame = append(frame, block...)
+	}
+	return frame
+}
+
+func zstdAppendBlockHeader(frame []byte, last bool, blockType int, size int) []byte {
+	header := blockType<<1 | size<<3
+	if last {
+		header |= 1
+	}
+	return append(frame, byte(header), byte(header>>8), byte(header>>16))
+}
+
+// zstdEncodeBlock encodes data[start:end], matching against the last position
+// of every four bytes seen in the frame so far.
+func zstdEncodeBlock(data []byte, start int, end int, table []int) []byte {
+	literals := []byte{}
+	sequences := []zstdSequence{}
+	literalStart := start
+	position := start
+	for position+4 <= end {
+		sequence := binary.LittleEndian.Uint32(data[position:])
+		hash := sequence * 2654435761 >> 16
+		candidate := table[hash] - 1
+		table[hash] = position + 1
+
+		if candidate < 0 || position-candidate > zstdMaxMatchOffset || binary.LittleEndian.Uint32(data[candidate:]) != sequence {
+			position++
+			continue
+		}
+
+		matchLength := 4
+		for position+matchLength < end && data[candidate+matchLength] == data[position+matchLength] {
+			matchLength++
+		}
+		literals = append(literals, data[literalStart:position]...)
+		sequences = append(sequences, zstdSequence{
+			literalLength: position - literalStart,
+			matchLength:   matchLength,
+			offset:        position - candidate,
+		})
+		position += matchLength
+		literalStart = position
+	}
+	literals = append(literals, data[literalStart:end]...)
+
+	// raw literals
+	block := []byte{}
+	switch size := len(literals); {
+	case size < 32:
+		block = append(block, byte(size<<3))
+	case size < 4096:
+		block = append(block, byte(size<<4|1<<2), byte(size>>4))
+	default:
+		block = append(block, byte(size<<4|3<<2), byte(size>>4), byte(size>>12))
+	}
+	block = append(block, literals...)
+
+	switch count := len(sequences); {
+	case count < 128:
+		block = append(block, byte(count))
+	case count < 0x7F00:
+		block = append(block, byte(count>>8+128), byte(count))
+	default:
+		block = append(block, 255, byte(count-0x7F00), byte((count-0x7F00)>>8))
+	}
+	if len(sequences) == 0 {
+		return block
+	}
+	// every kind of symbol uses its predefined table
+	block = append(block, 0)
+	return append(block, zstdEncodeSequences(sequences)...)
+}
+
+// zstdFseEncoder encodes symbols with an FSE table, the reverse of the
+// decoding table built from the same counts.
+type zstdFseEncoder struct {
+	accuracyLog int
+	stateTable  []uint16
+	transforms  []zstdFseTransform
+}
+
+type zstdFseTransform struct {
+	deltaBits      uint32
+	deltaFindState int
+}
+
+var zstdPredefinedEncoders = [3]*zstdFseEncoder{
+	newZstdFseEncoder(zstdPredefinedLiteralLengths, 6),
+	newZstdFseEncoder(zstdPredefinedOffsets, 5),
+	newZstdFseEncoder(zstdPredefinedMatchLengths, 6),
+}
+
+func newZstdFseEncoder(normalized []int16, accuracyLog int) *zstdFseEncoder {
+	tableSize := 1 << accuracyLog
+	symbols := make([]int, tableSize)
+	cumulative := make([]int, len(normalized)+1)
+
+	highThreshold := tableSize - 1
+	for symbol, count := range normalized {
+		if count == -1 {
+			cumulative[symbol+1] = cumulative[symbol] + 1
+			symbols[highThreshold] = symbol
+			highThreshold--
+		} else {
+			cumulative[symbol+1] = cumulative[symbol] + int(count)
+		}
+	}
+
+	// the symbols are spread the way the decoding table spreads them
+	mask := tableSize - 1
+	step := tableSize>>1 + tableSize>>3 + 3
+	position := 0
+	for symbol, count := range normalized {
+		for i := 0; i < int(count); i++ {
+			symbols[position] = symbol
+			position = (position + step) & mask
+			for position > highThreshold {
+				position = (position + step) & mask
+			}
+		}
+	}
+
+	stateTable := make([]uint16, tableSize)
+	for i, symbol := range symbols {
+		stateTable[cumulative[symbol]] = uint16(tableSize + i)
+		cumulative[symbol]++
+	}
+
+	transforms := make([]zstdFseTransform, len(normalized))
+	total := 0
+	for symbol, count := range normalized {
+		switch count {
+		case 0:
+		case -1, 1:
+			transforms[symbol] = zstdFseTransform{uint32(accuracyLog<<16 - tableSize), total - 1}
+			total++
+		default:
+			maxBitsOut := accuracyLog - (bits.Len(uint(count-1)) - 1)
+			minStatePlus := int(count) << maxBitsOut
+			transforms[symbol] = zstdFseTransform{uint32(maxBitsOut<<16 - minStatePlus), total - int(count)}
+			total += int(count)
+		}
+	}
+	return &zstdFseEncoder{accuracyLog: accuracyLog, stateTable: stateTable, transforms: transforms}
+}
+
+// initialState is the state the decoder ends on, decoding symbol.
+func (encoder *zstdFseEncoder) initialState(symbol int) uint32 {
+	transform := encoder.transforms[symbol]
+	stateBits := (transform.deltaBits + 1<<15) >> 16
+	state := stateBits<<16 - transform.deltaBits
+	return uint32(encoder.stateTable[int(state>>stateBits)+transform.deltaFindState])
+}
+
+// encode writes the bits leading from the state decoding symbol to state.
+func (encoder *zstdFseEncoder) encode(writer *zstdBitWriter, state uint32, symbol int) uint32 {
+	transform := encoder.transforms[symbol]
+	stateBits := (state + transform.deltaBits) >> 16
+	writer.write(uint64(state), int(stateBits))
+	return uint32(encoder.stateTable[int(state>>stateBits)+transform.deltaFindState])
+}
+
+// zstdBitWriter writes a bitstream least significant bit first, for reading
+// back from its end.
+type zstdBitWriter struct {
+	data      []byte
+	container uint64
+	count     int
+}
+
+func (writer *zstdBitWriter) write(value uint64, n int) {
+	writer.container |= value & (1<<n - 1) << writer.count
+	writer.count += n
+	for writer.count >= 8 {
+		writer.data = append(writer.data, byte(writer.container))
+		writer.container >>= 8
+		writer.count -= 8
+	}
+}
+
+// close marks the end of the stream with a set bit.
+func (writer *zstdBitWriter) close() []byte {
+	writer.write(1, 1)
+	if writer.count > 0 {
+		writer.data = append(writer.data, byte(writer.container))
+	}
+	return writer.data
+}
+
+// zstdEncodeSequences writes the sequences bitstream. It is written from the
+// last sequence to the first, as the decoder reads it backwards.
+func zstdEncodeSequences(sequences []zstdSequence) []byte {
+	literalLengths, offsets, matchLengths := zstdPredefinedEncoders[0], zstdPredefinedEncoders[1], zstdPredefinedEncoders[2]
+
+	type codes struct {
+		literalLength, matchLength, offset int
+		offsetValue                        int
+	}
+	sequenceCodes := make([]codes, len(sequences))
+	for i, sequence := range sequences {
+		offsetValue := sequence.offset + 3
+		sequenceCodes[i] = codes{
+			literalLength: zstdLengthCode(zstdLiteralLengthBase[:], sequence.literalLength),
+			matchLength:   zstdLengthCode(zstdMatchLengthBase[:], sequence.matchLength),
+			offset:        bits.Len(uint(offsetValue)) - 1,
+			offsetValue:   offsetValue,
+		}
+	}
+
+	writer := &zstdBitWriter{}
+	writeExtraBits := func(i int) {
+		code := sequenceCodes[i]
+		writer.write(uint64(sequences[i].literalLength-zstdLiteralLengthBase[code.literalLength]), zstdLiteralLengthBits[code.literalLength])
+		writer.write(uint64(sequences[i].matchLength-zstdMatchLengthBase[code.matchLength]), zstdMatchLengthBits[code.matchLength])
+		writer.write(uint64(code.offsetValue-1<<code.offset), code.offset)
+	}
+
+	last := len(sequences) - 1
+	literalLengthState := literalLengths.initialState(sequenceCodes[last].literalLength)
+	offsetState := offsets.initialState(sequenceCodes[last].offset)
+	matchLengthState := matchLengths.initialState(sequenceCodes[last].matchLength)
+	writeExtraBits(last)
+	for i := last - 1; i >= 0; i-- {
+		offsetState = offsets.encode(writer, offsetState, sequenceCodes[i].offset)
+		matchLengthState = matchLengths.encode(writer, matchLengthState, sequenceCodes[i].matchLength)
+		literalLengthState = literalLengths.encode(writer, literalLengthState, sequenceCodes[i].literalLength)
+		writeExtraBits(i)
+	}
+
+	writer.write(uint64(matchLengthState), matchLengths.accuracyLog)
+	writer.write(uint64(offsetState), offsets.accuracyLog)
+	writer.write(uint64(literalLengthState), literalLengths.accuracyLog)
+	return writer.close()
+}
+
+// zstdLengthCode returns the code of a length, the last whose base is not
+// above it.
+func zstdLengthCode(bases []int, length int) int {
+	code := len(bases) - 1
+	for bases[code] > length {
+		code--
+	}
+	return code
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config holds the broker settings. Values are read from a server.properties
+// style file passed as the first argument, anything missing keeps its default.
+
+type Config struct {
+	socketRequestMaxBytes    int32
+	logDir                   string
+	nodeId                   int32
+	port                     int32
+	advertisedHost           string
+	advertisedPort           int32
+	autoCreateTopicsEnable   bool
+	numPartitions            int32
+	defaultReplicationFactor int16
+	logIndexIntervalBytes    int
+	logSegmentBytes          int64
+	logRollMs                int64
+	// -1 means no limit for both retention settings
+	logRetentionMs               int64
+	logRetentionBytes            int64
+	logRetentionCheckIntervalMs  int64
+	logCleanerEnable             bool
+	logCleanerBackoffMs          int64
+	logCleanerDeleteRetentionMs  int64
+	logFlushCheckpointIntervalMs int64
+	groupMinSessionTimeoutMs     int32
+	groupMaxSessionTimeoutMs     int32
+	groupInitialRebalanceDelayMs int32
+	groupMaxSize                 int32
+	offsetsTopicNumPartitions    int32
+	offsetMetadataMaxBytes       int32
+	// partitions of __transaction_state when it gets created
+	transactionLogNumPartitions int32
+	// how often the metadata log is checked for batches written by others
+	metadataLogWatchIntervalMs int64
+}
+
+var serverConfig = defaultConfig()
+
+func defaultConfig() *Config {
+	return &Config{
+		socketRequestMaxBytes:        100 * 1024 * 1024,
+		logDir:                       "/tmp/kraft-combined-logs",
+		nodeId:                       1,
+		port:                         9092,
+		advertisedHost:               "localhost",
+		advertisedPort:               9092,
+		autoCreateTopicsEnable:       true,
+		numPartitions:                1,
+		defaultReplicationFactor:     1,
+		logIndexIntervalBytes:        4096,
+		logSegmentBytes:              1024 * 1024 * 1024,
+		logRollMs:                    7 * 24 * 60 * 60 * 1000,
+		logRetentionMs:               7 * 24 * 60 * 60 * 1000,
+		logRetentionBytes:            -1,
+		logRetentionCheckIntervalMs:  5 * 60 * 1000,
+		logCleanerEnable:             true,
+		logCleanerBackoffMs:          15 * 1000,
+		logCleanerDeleteRetentionMs:  24 * 60 * 60 * 1000,
+		logFlushCheckpointIntervalMs: 60 * 1000,
+		groupMinSessionTimeoutMs:     6 * 1000,
+		groupMaxSessionTimeoutMs:     30 * 60 * 1000,
+		groupInitialRebalanceDelayMs: 3 * 1000,
+		groupMaxSize:                 1<<31 - 1,
+		offsetsTopicNumPartitions:    50,
+		offsetMetadataMaxBytes:       4096,
+		transactionLogNumPartitions:  50,
+		metadataLogWatchIntervalMs:   500,
+	}
+}
+
+func loadConfig(fileName string) (*Config, error) {
+	config := defaultConfig()
+
+	properties, err := readPropertiesFile(fileName)
+	if err != nil {
+		return config, err
+	}
+
+	// advertised.listeners falls back to listeners, so it has to be applied last
+	advertisedListeners, hasAdvertisedListeners := properties["advertised.listeners"]
+	delete(properties, "advertised.listeners")
+	// like kafka, log.roll.ms wins over log.roll.hours, and log.retention.ms
+	// over log.retention.minutes which wins over log.retention.hours
+	if _, ok := properties["log.roll.ms"]; ok {
+		delete(properties, "log.roll.hours")
+	}
+	if _, ok := properties["log.retention.ms"]; ok {
+		delete(properties, "log.retention.minutes")
+		delete(properties, "log.retention.hours")
+	}
+	if _, ok := properties["log.retention.minutes"]; ok {
+		delete(properties, "log.retention.hours")
+	}
+
+	for key, value := range properties {
+		config.setProperty(key, value)
+	}
+	if hasAdvertisedListeners {
+		config.setProperty("advertised.listeners", advertisedListeners)
+	}
+
+	return config, nil
+}
+
+func (config *Config) setProperty(key string, value string) {
+	if err := config.set(key, value); err != nil {
+		fmt.Printf("Ignoring config %s=%s, Error Details: %s\n", key, value, err)
+	}
+}
+
+// readPropertiesFile reads a java .properties file, like server.properties or
+// the meta.properties kafka-storage writes into the log directory.
+func readPropertiesFile(fileName string) (map[string]string, error) {
+	properties := map[string]string{}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return properties, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	return properties, scanner.Err()
+}
+
+func (config *Config) set(key string, value string) error {
+	switch key {
+	case "socket.request.max.bytes":
+		maxBytes, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.socketRequestMaxBytes = maxBytes
+	case "log.dirs", "log.dir":
+		// only a single log directory is supported, use the first one
+		logDir, _, _ := strings.Cut(value, ",")
+		config.logDir = strings.TrimSpace(logDir)
+	case "node.id", "broker.id":
+		nodeId, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		config.nodeId = int32(nodeId)
+	case "listeners":
+		host, port, err := parseListeners(value)
+		if err != nil {
+			return err
+		}
+		config.port = port
+		config.advertisedPort = port
+		if host != "" {
+			config.advertisedHost = host
+		}
+	case "advertised.listeners":
+		host, port, err := parseListeners(value)
+		if err != nil {
+			return err
+		}
+		config.advertisedPort = port
+		if host != "" {
+			config.advertisedHost = host
+		}
+	case "auto.create.topics.enable":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		config.autoCreateTopicsEnable = enabled
+	case "num.partitions":
+		numPartitions, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.numPartitions = numPartitions
+	case "default.replication.factor":
+		replicationFactor, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.defaultReplicationFactor = int16(replicationFactor)
+	case "log.index.interval.bytes":
+		intervalBytes, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.logIndexIntervalBytes = int(intervalBytes)
+	case "log.segment.bytes":
+		segmentBytes, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.logSegmentBytes = int64(segmentBytes)
+	case "log.roll.ms":
+		rollMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRollMs = rollMs
+	case "log.roll.hours":
+		rollHours, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRollMs = rollHours * 60 * 60 * 1000
+	case "log.retention.ms":
+		retentionMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRetentionMs = retentionMs
+	case "log.retention.minutes":
+		retentionMinutes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRetentionMs = retentionMinutes * 60 * 1000
+	case "log.retention.hours":
+		retentionHours, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRetentionMs = retentionHours * 60 * 60 * 1000
+	case "log.retention.bytes":
+		retentionBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logRetentionBytes = retentionBytes
+	case "log.retention.check.interval.ms":
+		intervalMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if intervalMs <= 0 {
+			return fmt.Errorf("%s must be positive", key)
+		}
+		config.logRetentionCheckIntervalMs = intervalMs
+	case "log.cleaner.enable":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		config.logCleanerEnable = enabled
+	case "log.cleaner.backoff.ms":
+		backoffMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if backoffMs <= 0 {
+			return fmt.Errorf("%s must be positive", key)
+		}
+		config.logCleanerBackoffMs = backoffMs
+	case "log.cleaner.delete.retention.ms":
+		retentionMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		config.logCleanerDeleteRetentionMs = retentionMs
+	case "log.flush.offset.checkpoint.interval.ms":
+		intervalMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if intervalMs <= 0 {
+			return fmt.Errorf("%s must be positive", key)
+		}
+		config.logFlushCheckpointIntervalMs = intervalMs
+	case "group.min.session.timeout.ms":
+		timeoutMs, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.groupMinSessionTimeoutMs = timeoutMs
+	case "group.max.session.timeout.ms":
+		timeoutMs, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.groupMaxSessionTimeoutMs = timeoutMs
+	case "group.initial.rebalance.delay.ms":
+		delayMs, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		config.groupInitialRebalanceDelayMs = int32(max(delayMs, 0))
+	case "group.max.size":
+		maxSize, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.groupMaxSize = maxSize
+	case "offsets.topic.num.partitions":
+		numPartitions, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.offsetsTopicNumPartitions = numPartitions
+	case "transaction.state.log.num.partitions":
+		numPartitions, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.transactionLogNumPartitions = numPartitions
+	case "offset.metadata.max.bytes":
+		maxBytes, err := parsePositiveInt32(key, value)
+		if err != nil {
+			return err
+		}
+		config.offsetMetadataMaxBytes = maxBytes
+	case "metadata.log.watch.interval.ms":
+		intervalMs, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if intervalMs <= 0 {
+			return fmt.Errorf("%s must be positive", key)
+		}
+		config.metadataLogWatchIntervalMs = intervalMs
+	}
+	return nil
+}
+
+func parsePositiveInt32(key string, value string) (int32, error) {
+	number, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if number <= 0 {
+		return 0, fmt.Errorf("%s must be positive", key)
+	}
+	return int32(number), nil
+}
+
+// parseListeners returns the host and port of the first non controller
+// listener in a list like "PLAINTEXT://:9092,CONTROLLER://:9093".
+func parseListeners(value string) (string, int32, error) {
+	for _, listener := range strings.Split(value, ",") {
+		name, address, found := strings.Cut(strings.TrimSpace(listener), "://")
+		if !found {
+			return "", 0, fmt.Errorf("invalid listener %s", listener)
+		}
+		if name == "CONTROLLER" {
+			continue
+		}
+
+		separator := strings.LastIndex(address, ":")
+		if separator < 0 {
+			return "", 0, fmt.Errorf("listener %s has no port", listener)
+		}
+		port, err := strconv.ParseInt(address[separator+1:], 10, 32)
+		if err != nil {
+			return "", 0, err
+		}
+		return address[:separator], int32(port), nil
+	}
+	return "", 0, fmt.Errorf("no broker listener in %s", value)
+}
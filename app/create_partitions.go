@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// CreatePartitions
+
+type CreatePartitionsRequest struct {
+	RequestHeader
+	protocol.CreatePartitionsRequest
+}
+
+func (request *CreatePartitionsRequest) parse(decoder *protocol.Decoder) error {
+	return request.CreatePartitionsRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *CreatePartitionsRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	createPartitionsResponse := protocol.CreatePartitionsResponse{}
+	createPartitionsResponse.Default()
+
+	topicCounts := map[string]int{}
+	for _, requestTopic := range request.Topics {
+		topicCounts[requestTopic.Name]++
+	}
+
+	for _, requestTopic := range request.Topics {
+		result := &protocol.CreatePartitionsResponseCreatePartitionsTopicResult{Name: requestTopic.Name}
+		createPartitionsResponse.Results = append(createPartitionsResponse.Results, result)
+
+		if topicCounts[requestTopic.Name] > 1 {
+			result.ErrorCode = errorCodeInvalidRequest
+			result.ErrorMessage = fmt.Sprintf("create partitions request contains topic %s more than once", requestTopic.Name)
+			continue
+		}
+
+		// replicas of each new partition, nil lets the broker place them
+		var assignments [][]int32
+		if requestTopic.Assignments != nil {
+			assignments = [][]int32{}
+		}
+		for _, assignment := range requestTopic.Assignments {
+			assignments = append(assignments, assignment.BrokerIds)
+		}
+
+		errorCode, err := createPartitions(requestTopic.Name, requestTopic.Count, assignments, request.ValidateOnly)
+		if err != nil {
+			result.ErrorCode = errorCode
+			result.ErrorMessage = err.Error()
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(createPartitionsResponse.IsFlexible(request.apiVersion))
+	createPartitionsResponse.Encode(commonResponse.body, request.apiVersion)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// CreateTopics
+
+// config sources of the returned topic configs
+const (
+	configSourceDynamicTopicConfig int8 = 1
+	configSourceDefaultConfig      int8 = 5
+)
+
+type CreateTopicsRequest struct {
+	RequestHeader
+	protocol.CreateTopicsRequest
+}
+
+func (request *CreateTopicsRequest) parse(decoder *protocol.Decoder) error {
+	return request.CreateTopicsRequest.Decode(decoder, request.apiVersion)
+}
+
+// replicaAssignment turns the assignments of a request into the replicas of
+// each partition. Partitions have to be numbered 0 to N-1.
+func replicaAssignment(topic *protocol.CreateTopicsRequestCreatableTopic) ([][]int32, int16, error) {
+	if len(topic.Assignments) == 0 {
+		numPartitions := topic.NumPartitions
+		if numPartitions == -1 {
+			numPartitions = serverConfig.numPartitions
+		}
+		replicationFactor := topic.ReplicationFactor
+		if replicationFactor == -1 {
+			replicationFactor = serverConfig.defaultReplicationFactor
+		}
+		return newReplicaAssignment(numPartitions, replicationFactor)
+	}
+
+	if topic.NumPartitions != -1 || topic.ReplicationFactor != -1 {
+		return nil, errorCodeInvalidRequest, fmt.Errorf("both numPartitions or replicationFactor and replicasAssignments were set, both cannot be used at the same time")
+	}
+	assignment := make([][]int32, len(topic.Assignments))
+	for _, partitionAssignment := range topic.Assignments {
+		partitionIndex := partitionAssignment.PartitionIndex
+		if partitionIndex < 0 || int(partitionIndex) >= len(assignment) || assignment[partitionIndex] != nil {
+			return nil, errorCodeInvalidReplicaAssignment, fmt.Errorf("partitions should be a consecutive 0-based integer sequence")
+		}
+		assignment[partitionIndex] = partitionAssignment.BrokerIds
+	}
+	return assignment, errorCodeNone, nil
+}
+
+// createTopicsResponseConfigs lists every config of a new topic, with its
+// overrides and the defaults for the rest.
+func createTopicsResponseConfigs(configs map[string]string) []*protocol.CreateTopicsResponseCreatableTopicConfigs {
+	responseConfigs := []*protocol.CreateTopicsResponseCreatableTopicConfigs{}
+	defaults := topicConfigDefaults()
+	for _, name := range slices.Sorted(maps.Keys(defaults)) {
+		config := &protocol.CreateTopicsResponseCreatableTopicConfigs{Name: name, Value: defaults[name], ConfigSource: configSourceDefaultConfig}
+		if value, ok := configs[name]; ok {
+			config.Value = value
+			config.ConfigSource = configSourceDynamicTopicConfig
+		}
+		responseConfigs = append(responseConfigs, config)
+	}
+	return responseConfigs
+}
+
+func (request *CreateTopicsRequest) createTopic(requestTopic *protocol.CreateTopicsRequestCreatableTopic) *protocol.CreateTopicsResponseCreatableTopicResult {
+	topicResponse := &protocol.CreateTopicsResponseCreatableTopicResult{}
+	topicResponse.Default()
+	topicResponse.Name = requestTopic.Name
+
+	assignment, errorCode, err := replicaAssignment(requestTopic)
+	if err != nil {
+		topicResponse.ErrorCode = errorCode
+		topicResponse.ErrorMessage = err.Error()
+		return topicResponse
+	}
+
+	// a null value reads as the empty string, which no topic config accepts
+	configs := map[string]string{}
+	for _, config := range requestTopic.Configs {
+		configs[config.Name] = config.Value
+	}
+
+	topic, errorCode, err := createTopicWithAssignment(requestTopic.Name, assignment, configs, request.ValidateOnly)
+	if err != nil {
+		topicResponse.ErrorCode = errorCode
+		topicResponse.ErrorMessage = err.Error()
+		return topicResponse
+	}
+
+	topicResponse.TopicId = topic.topicId
+	topicResponse.NumPartitions = int32(len(topic.partitions))
+	topicResponse.ReplicationFactor = int16(len(assignment[0]))
+	topicResponse.Configs = createTopicsResponseConfigs(configs)
+	return topicResponse
+}
+
+func (request *CreateTopicsRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	createTopicsResponse := protocol.CreateTopicsResponse{}
+	createTopicsResponse.Default()
+
+	topicCounts := map[string]int{}
+	for _, requestTopic := range request.Topics {
+		topicCounts[requestTopic.Name]++
+	}
+
+	for _, requestTopic := range request.Topics {
+		if topicCounts[requestTopic.Name] > 1 {
+			topicResponse := &protocol.CreateTopicsResponseCreatableTopicResult{}
+			topicResponse.Default()
+			topicResponse.Name = requestTopic.Name
+			topicResponse.ErrorCode = errorCodeInvalidRequest
+			topicResponse.ErrorMessage = fmt.Sprintf("create topics request contains topic %s more than once", requestTopic.Name)
+			createTopicsResponse.Topics = append(createTopicsResponse.Topics, topicResponse)
+			continue
+		}
+		createTopicsResponse.Topics = append(createTopicsResponse.Topics, request.createTopic(requestTopic))
+	}
+
+	commonResponse.body = protocol.NewEncoder(createTopicsResponse.IsFlexible(request.apiVersion))
+	createTopicsResponse.Encode(commonResponse.body, request.apiVersion)
+}
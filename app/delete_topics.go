@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+	"github.com/google/uuid"
+)
+
+// DeleteTopics
+
+type DeleteTopicsRequest struct {
+	RequestHeader
+	protocol.DeleteTopicsRequest
+}
+
+func (request *DeleteTopicsRequest) parse(decoder *protocol.Decoder) error {
+	if err := request.DeleteTopicsRequest.Decode(decoder, request.apiVersion); err != nil {
+		return err
+	}
+	// v0-v5 send TopicNames, v6+ name topics either by name or by id
+	for _, name := range request.TopicNames {
+		request.Topics = append(request.Topics, &protocol.DeleteTopicsRequestDeleteTopicState{Name: name})
+	}
+	return nil
+}
+
+func (request *DeleteTopicsRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	deleteTopicsResponse := protocol.DeleteTopicsResponse{}
+	deleteTopicsResponse.Default()
+	clusterTopics := getClusterTopics()
+
+	// resolve every requested topic first, a topic asked for twice, by name
+	// or by id, is an invalid request
+	topics := make([]*Topic, len(request.Topics))
+	topicCounts := map[uuid.UUID]int{}
+	for i, requestTopic := range request.Topics {
+		topicResponse := &protocol.DeleteTopicsResponseDeletableTopicResult{Name: requestTopic.Name, TopicId: requestTopic.TopicId}
+		deleteTopicsResponse.Responses = append(deleteTopicsResponse.Responses, topicResponse)
+
+		if requestTopic.Name != "" && requestTopic.TopicId != uuid.Nil {
+			topicResponse.ErrorCode = errorCodeInvalidRequest
+			topicResponse.ErrorMessage = "topic name and topic id can't both be set"
+			continue
+		}
+		if requestTopic.Name != "" {
+			topics[i] = findTopicByName(clusterTopics, requestTopic.Name)
+			if topics[i] == nil {
+				topicResponse.ErrorCode = errorCodeUnknownTopicOrPartition
+				topicResponse.ErrorMessage = fmt.Sprintf("topic %s does not exist", requestTopic.Name)
+				continue
+			}
+		} else {
+			topics[i] = findTopicById(clusterTopics, requestTopic.TopicId)
+			if topics[i] == nil {
+				topicResponse.ErrorCode = errorCodeUnknownTopicId
+				topicResponse.ErrorMessage = fmt.Sprintf("topic id %s does not exist", requestTopic.TopicId)
+				continue
+			}
+		}
+		topicResponse.Name = topics[i].name
+		topicResponse.TopicId = topics[i].topicId
+		topicCounts[topics[i].topicId]++
+	}
+
+	for i, topicResponse := range deleteTopicsResponse.Responses {
+		topic := topics[i]
+		if topic == nil || topicResponse.ErrorCode != errorCodeNone {
+			continue
+		}
+		if topicCounts[topic.topicId] > 1 {
+			topicResponse.ErrorCode = errorCodeInvalidRequest
+			topicResponse.ErrorMessage = fmt.Sprintf("delete topics request contains topic %s more than once", topic.name)
+			continue
+		}
+		if topic.isInternal {
+			topicResponse.ErrorCode = errorCodeInvalidTopicException
+			topicResponse.ErrorMessage = fmt.Sprintf("internal topic %s can't be deleted", topic.name)
+			continue
+		}
+
+		if err := deleteTopic(topic); err != nil {
+			fmt.Printf("Error while deleting topic %s. %s\n", topic.name, err)
+			topicResponse.ErrorCode = errorCodeUnknownServerError
+			topicResponse.ErrorMessage = err.Error()
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(deleteTopicsResponse.IsFlexible(request.apiVersion))
+	deleteTopicsResponse.Encode(commonResponse.body, request.apiVersion)
+}
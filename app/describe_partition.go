@@ -1,19 +1,16 @@
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
-	"fmt"
+	"cmp"
+	"slices"
 
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 	"github.com/google/uuid"
 )
 
 type DescribePartitionsRequest struct {
 	RequestHeader
-	names                  []string
-	responsePartitionLimit int32
-	topicName              string
-	partitionIndex         int32
+	protocol.DescribeTopicPartitionsRequest
 }
 
 type Partition struct {
@@ -37,156 +34,128 @@ type Topic struct {
 	topicAuthorizedOperations int32
 }
 
-type NextCursor struct {
-	topicName      string
-	partitionIndex int32
-}
-
-type DescribePartitionsResponse struct {
-	throttleTime int32
-	topics       []*Topic
-	nextCursor   NextCursor
-}
-
-func (request *DescribePartitionsRequest) parse(buffer *bytes.Buffer) {
-	request.names = getStringArray(buffer)
-	binary.Read(buffer, binary.BigEndian, &request.responsePartitionLimit)
-	ignoreTagField(buffer)
-	fmt.Printf("%+v\n", request)
+func (request *DescribePartitionsRequest) parse(decoder *protocol.Decoder) error {
+	return request.DescribeTopicPartitionsRequest.Decode(decoder, request.apiVersion)
 }
 
-func (response *DescribePartitionsResponse) bytes(buffer *bytes.Buffer, request *DescribePartitionsRequest) {
-
-	binary.Write(buffer, binary.BigEndian, response.throttleTime)
-
-	topicsToSend := []*Topic{}
-
-	// for _, topic := range response.topics {
-	// 	if slices.Contains(request.names, topic.name) {
-	// 		topicsToSend = append(topicsToSend, topic)
-	// 	}
-	// }
-
-	for _, requestTopicName := range request.names {
-		foundTopic := false
-		for _, topic := range response.topics {
-			if topic.name == requestTopicName {
-				topicsToSend = append(topicsToSend, topic)
-				foundTopic = true
-			}
-		}
-
-		if !foundTopic {
-			topicsToSend = append(topicsToSend, &Topic{errorCode: 3, name: requestTopicName})
-		}
+// describePartitionsLimit caps ResponsePartitionLimit, like kafka's
+// max.request.partition.size.limit.
+const describePartitionsLimit = 2000
+
+func describeTopic(topic *Topic, partitions []*Partition) *protocol.DescribeTopicPartitionsResponseTopic {
+	topicResponse := &protocol.DescribeTopicPartitionsResponseTopic{
+		ErrorCode:                 topic.errorCode,
+		Name:                      topic.name,
+		TopicId:                   topic.topicId,
+		IsInternal:                topic.isInternal,
+		Partitions:                []*protocol.DescribeTopicPartitionsResponsePartition{},
+		TopicAuthorizedOperations: topic.topicAuthorizedOperations,
 	}
-
-	binary.Write(buffer, binary.BigEndian, int8(len(topicsToSend)+1))
-	for _, topic := range topicsToSend {
-		binary.Write(buffer, binary.BigEndian, topic.errorCode)
-		writeCompactString(buffer, topic.name)
-
-		binary.Write(buffer, binary.BigEndian, topic.topicId[:])
-		binary.Write(buffer, binary.BigEndian, topic.isInternal)
-
-		// fmt.Printf("\nPartition Record in bytes: %+v", topic.partitions)
-
-		if topic.partitions == nil {
-			binary.Write(buffer, binary.BigEndian, int8(1))
-		} else {
-			binary.Write(buffer, binary.BigEndian, int8(len(topic.partitions)+1))
-			for _, partition := range topic.partitions {
-				binary.Write(buffer, binary.BigEndian, partition.errorCode)
-				binary.Write(buffer, binary.BigEndian, partition.partitionIndex)
-				binary.Write(buffer, binary.BigEndian, partition.leaderId)
-				binary.Write(buffer, binary.BigEndian, partition.leaderEpoch)
-				writeCompactArray(buffer, partition.replicaNodes)
-				writeCompactArray(buffer, partition.isrNodes)
-				writeCompactArray(buffer, partition.eligibleLeaderReplicas)
-				writeCompactArray(buffer, partition.lastKnownElr)
-				writeCompactArray(buffer, partition.offlineReplicas)
-				addTagField(buffer)
-			}
-		}
-
-		binary.Write(buffer, binary.BigEndian, topic.topicAuthorizedOperations)
-		addTagField(buffer)
+	for _, partition := range partitions {
+		topicResponse.Partitions = append(topicResponse.Partitions, &protocol.DescribeTopicPartitionsResponsePartition{
+			ErrorCode:      partition.errorCode,
+			PartitionIndex: partition.partitionIndex,
+			LeaderId:       partition.leaderId,
+			LeaderEpoch:    partition.leaderEpoch,
+			ReplicaNodes:   partition.replicaNodes,
+			IsrNodes:       partition.isrNodes,
+			// empty rather than null, the image has no null lists
+			EligibleLeaderReplicas: append([]int32{}, partition.eligibleLeaderReplicas...),
+			LastKnownElr:           append([]int32{}, partition.lastKnownElr...),
+			OfflineReplicas:        partition.offlineReplicas,
+		})
 	}
+	return topicResponse
+}
 
-	binary.Write(buffer, binary.BigEndian, int8(-1))
-
-	addTagField(buffer)
-	fmt.Printf("%+v\n", response)
+func unknownTopic(name string, errorCode int16) *protocol.DescribeTopicPartitionsResponseTopic {
+	return &protocol.DescribeTopicPartitionsResponseTopic{
+		ErrorCode:  errorCode,
+		Name:       name,
+		Partitions: []*protocol.DescribeTopicPartitionsResponsePartition{},
+	}
 }
 
 func (request *DescribePartitionsRequest) generateResponse(commonResponse *Response) {
 	commonResponse.correlationId = request.correlationId
 
-	dTVResponse := DescribePartitionsResponse{}
-	dTVResponse.throttleTime = 0
-	// dTVResponse.topics = append(dTVResponse.topics, Topic{errorCode: 0, name: request.names[0], topicId: uuid.UUID{0}, partitions: nil})
+	response := protocol.DescribeTopicPartitionsResponse{}
+	response.Default()
 
-	clusterMetadataLogs, err := readClusterMetadata()
-	if err != nil {
-		fmt.Printf("Error while reading cluster data. Error details: %s", err)
+	// like kafka, topics are described in name order, all of them when the
+	// request names none
+	topics := getMetadataImage().topics
+	names := []string{}
+	for _, requestTopic := range request.Topics {
+		names = append(names, requestTopic.Name)
+	}
+	if len(names) == 0 {
+		for _, topic := range topics {
+			names = append(names, topic.name)
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	// a cursor continues a previous response from its NextCursor
+	cursor := request.Cursor
+	if cursor != nil && (cursor.PartitionIndex < 0 || !slices.Contains(names, cursor.TopicName)) {
+		for _, name := range names {
+			response.Topics = append(response.Topics, unknownTopic(name, errorCodeInvalidRequest))
+		}
+		names = nil
 	}
 
-	err = addClusterMetadataIntoResponse(&dTVResponse, clusterMetadataLogs)
-	if err != nil {
-		fmt.Printf("Error while adding cluster data into repsonse. Error details: %s", err)
+	remainingPartitions := request.ResponsePartitionLimit
+	if remainingPartitions <= 0 || remainingPartitions > describePartitionsLimit {
+		remainingPartitions = describePartitionsLimit
 	}
 
-	dTVResponse.bytes(&commonResponse.BytesData, request)
+	for _, name := range names {
+		firstPartition := int32(0)
+		if cursor != nil {
+			if name < cursor.TopicName {
+				continue
+			}
+			if name == cursor.TopicName {
+				firstPartition = cursor.PartitionIndex
+			}
+		}
 
-}
+		topic := findTopicByName(topics, name)
+		if topic == nil {
+			response.Topics = append(response.Topics, unknownTopic(name, errorCodeUnknownTopicOrPartition))
+			continue
+		}
+		if remainingPartitions == 0 {
+			response.NextCursor = &protocol.DescribeTopicPartitionsResponseCursor{TopicName: name, PartitionIndex: firstPartition}
+			break
+		}
 
-func addClusterMetadataIntoResponse(response *DescribePartitionsResponse, clusterMetadataLogs []*ClusterMetadata) error {
-
-	topicPartitionMap := make(map[uuid.UUID]*Topic)
-
-	for _, clusterMetadata := range clusterMetadataLogs {
-		// fmt.Printf("%+v\n%d\n", clusterMetadata.records[0], clusterMetadata.recordsLength)
-
-		for _, record := range clusterMetadata.records {
-			switch record.recordType {
-			case 2:
-				// topicRecord
-				topic := &Topic{
-					errorCode:  0,
-					name:       record.TopicRecord.name,
-					topicId:    record.TopicRecord.topicId,
-					isInternal: false,
-				}
-
-				topicPartitionMap[topic.topicId] = topic
-				response.topics = append(response.topics, topic)
-			case 3:
-				// partitionRecord
-				partition := &Partition{
-					errorCode:              0,
-					partitionIndex:         record.PartitionRecord.partitionId,
-					leaderId:               record.PartitionRecord.leader,
-					leaderEpoch:            record.PartitionRecord.leaderEpoch,
-					replicaNodes:           record.PartitionRecord.replicaArray,
-					isrNodes:               record.PartitionRecord.inSyncReplicaArray,
-					eligibleLeaderReplicas: []int32{},
-					lastKnownElr:           []int32{},
-					offlineReplicas:        []int32{},
-				}
-
-				topic, ok := topicPartitionMap[record.PartitionRecord.topicId]
-				if ok {
-					topic.partitions = append(topic.partitions, partition)
-				}
-
-				// fmt.Printf("\nTopic details: %+v", topic)
-				// fmt.Printf("\nPartition Record adding in response: %+v", record.PartitionRecord)
-			case 12:
-				// featureRecord
-				// Skipping for now
+		partitions := []*Partition{}
+		for _, partition := range topic.partitions {
+			if partition.partitionIndex >= firstPartition {
+				partitions = append(partitions, partition)
 			}
 		}
+		slices.SortFunc(partitions, func(a, b *Partition) int {
+			return cmp.Compare(a.partitionIndex, b.partitionIndex)
+		})
+		if len(partitions) > int(remainingPartitions) {
+			response.NextCursor = &protocol.DescribeTopicPartitionsResponseCursor{
+				TopicName:      name,
+				PartitionIndex: partitions[remainingPartitions].partitionIndex,
+			}
+			partitions = partitions[:remainingPartitions]
+		}
+		remainingPartitions -= int32(len(partitions))
+
+		response.Topics = append(response.Topics, describeTopic(topic, partitions))
+		if response.NextCursor != nil {
+			break
+		}
 	}
 
-	return nil
+	commonResponse.body = protocol.NewEncoder(response.IsFlexible(request.apiVersion))
+	response.Encode(commonResponse.body, request.apiVersion)
 }
\ No newline at end of file
@@ -0,0 +1,38 @@
+package main
+
+// Kafka protocol error codes, see https://kafka.apache.org/protocol.html#protocol_error_codes
+
+const (
+	errorCodeUnknownServerError          int16 = -1
+	errorCodeNone                        int16 = 0
+	errorCodeOffsetOutOfRange            int16 = 1
+	errorCodeCorruptMessage              int16 = 2
+	errorCodeUnknownTopicOrPartition     int16 = 3
+	errorCodeOffsetMetadataTooLarge      int16 = 12
+	errorCodeCoordinatorNotAvailable     int16 = 15
+	errorCodeInvalidTopicException       int16 = 17
+	errorCodeInvalidRequiredAcks         int16 = 21
+	errorCodeIllegalGeneration           int16 = 22
+	errorCodeInconsistentGroupProtocol   int16 = 23
+	errorCodeInvalidGroupId              int16 = 24
+	errorCodeUnknownMemberId             int16 = 25
+	errorCodeInvalidSessionTimeout       int16 = 26
+	errorCodeRebalanceInProgress         int16 = 27
+	errorCodeUnsupportedVersion          int16 = 35
+	errorCodeTopicAlreadyExists          int16 = 36
+	errorCodeInvalidPartitions           int16 = 37
+	errorCodeInvalidReplicationFactor    int16 = 38
+	errorCodeInvalidReplicaAssignment    int16 = 39
+	errorCodeInvalidConfig               int16 = 40
+	errorCodeInvalidRequest              int16 = 42
+	errorCodeUnsupportedForMessageFormat int16 = 43
+	errorCodeKafkaStorageError           int16 = 56
+	errorCodeFencedLeaderEpoch           int16 = 74
+	errorCodeUnknownLeaderEpoch          int16 = 75
+	errorCodeUnsupportedCompressionType  int16 = 76
+	errorCodeMemberIdRequired            int16 = 79
+	errorCodeGroupMaxSizeReached         int16 = 81
+	errorCodeFencedInstanceId            int16 = 82
+	errorCodeInvalidRecord               int16 = 87
+	errorCodeUnknownTopicId              int16 = 100
+)
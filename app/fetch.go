@@ -1,216 +1,240 @@
 package main
 
 import (
-	"bytes"
-	"encoding/binary"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 	"github.com/google/uuid"
 )
 
 // Fetch
 
-type FetchPartition struct {
-	Partition          int32
-	CurrentLeaderEpoch int32
-	FetchOffset        int64
-	LastFetchedEpoch   int32
-	LogStartOffset     int64
-	PartitionMaxBytes  int32
-}
-
-type FetchTopic struct {
-	TopicID    uuid.UUID
-	Partitions []*FetchPartition
-}
-
-type ForgottenTopicData struct {
-	TopicID    uuid.UUID
-	Partitions []int32
-}
-
 type FetchRequest struct {
 	RequestHeader
-	MaxWaitMs           int32
-	MinBytes            int32
-	MaxBytes            int32
-	IsolationLevel      int8
-	SessionID           int32
-	SessionEpoch        int32
-	Topics              []*FetchTopic
-	ForgottenTopicsData []*ForgottenTopicData
-	RackID              string
+	protocol.FetchRequest
 }
 
-type AbortedTransaction struct {
-	ProducerID  int64
-	FirstOffset int64
+func (request *FetchRequest) parse(decoder *protocol.Decoder) error {
+	if err := request.FetchRequest.Decode(decoder, request.apiVersion); err != nil {
+		return err
+	}
+	return nil
 }
 
-type FetchResponsePartition struct {
-	PartitionIndex       int32
-	ErrorCode            int16
-	HighWatermark        int64
-	LastStableOffset     int64
-	LogStartOffset       int64
-	AbortedTransactions  []*AbortedTransaction
-	PreferredReadReplica int32
-	Records              []byte
-}
+func (request *FetchRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
 
-type FetchResponseTopic struct {
-	TopicID    uuid.UUID
-	Partitions []*FetchResponsePartition
-}
+	fetchResponse := protocol.FetchResponse{}
+	fetchResponse.Default()
+	fetchResponse.SessionId = request.SessionId
 
-type FetchResponse struct {
-	ThrottleTimeMs int32
-	ErrorCode      int16
-	SessionID      int32
-	Responses      []*FetchResponseTopic
-}
+	image := getMetadataImage()
+	clusterTopics := image.topics
 
-func (request *FetchRequest) parse(buffer *bytes.Buffer) {
-
-	binary.Read(buffer, binary.BigEndian, &request.MaxWaitMs)
-	binary.Read(buffer, binary.BigEndian, &request.MinBytes)
-	binary.Read(buffer, binary.BigEndian, &request.MaxBytes)
-	binary.Read(buffer, binary.BigEndian, &request.IsolationLevel)
-	binary.Read(buffer, binary.BigEndian, &request.SessionID)
-	binary.Read(buffer, binary.BigEndian, &request.SessionEpoch)
-
-	topicsLength, _ := binary.ReadUvarint(buffer)
-
-	request.Topics = make([]*FetchTopic, topicsLength-1)
-	for i := 0; i < int(topicsLength-1); i++ {
-		topic := &FetchTopic{}
-		topicBytes := make([]byte, 16)
-		buffer.Read(topicBytes)
-		topic.TopicID, _ = uuid.FromBytes(topicBytes)
-		partitionsLength, _ := binary.ReadUvarint(buffer)
-
-		topic.Partitions = make([]*FetchPartition, partitionsLength-1)
-
-		for j := 0; j < int(partitionsLength-1); j++ {
-			partition := &FetchPartition{}
-			binary.Read(buffer, binary.BigEndian, &partition.Partition)
-			binary.Read(buffer, binary.BigEndian, &partition.CurrentLeaderEpoch)
-			binary.Read(buffer, binary.BigEndian, &partition.FetchOffset)
-			binary.Read(buffer, binary.BigEndian, &partition.LastFetchedEpoch)
-			binary.Read(buffer, binary.BigEndian, &partition.LogStartOffset)
-			binary.Read(buffer, binary.BigEndian, &partition.PartitionMaxBytes)
-			topic.Partitions[j] = partition
-		}
-		request.Topics[i] = topic
+	// MaxBytes is shared by every partition in the response
+	remainingBytes := int(request.MaxBytes)
+	if remainingBytes <= 0 {
+		remainingBytes = int(^uint32(0) >> 1)
 	}
+	// like kafka, the first batch is sent whole while the response is still empty
+	emptyResponse := true
 
-	forgottenTopicsLength, _ := binary.ReadUvarint(buffer)
-	if forgottenTopicsLength > 0 {
-		request.ForgottenTopicsData = make([]*ForgottenTopicData, forgottenTopicsLength-1)
-		for i := 0; i < int(forgottenTopicsLength-1); i++ {
-			forgottenTopic := &ForgottenTopicData{}
-			topicBytes := make([]byte, 16)
-			buffer.Read(topicBytes)
-			forgottenTopic.TopicID, _ = uuid.FromBytes(topicBytes)
-			forgottenPartitionsLength, _ := binary.ReadUvarint(buffer)
-
-			forgottenTopic.Partitions = make([]int32, forgottenPartitionsLength)
-			for j := 0; j < int(forgottenPartitionsLength); j++ {
-				binary.Read(buffer, binary.BigEndian, &forgottenTopic.Partitions[j])
+	for _, topic := range request.Topics {
+		topicResponse := &protocol.FetchResponseFetchableTopicResponse{Topic: topic.Topic, TopicId: topic.TopicId}
+		unknownTopicErrorCode := errorCodeUnknownTopicOrPartition
+		var clusterTopic *Topic
+		if request.apiVersion >= 13 {
+			unknownTopicErrorCode = errorCodeUnknownTopicId
+			clusterTopic = findTopicById(clusterTopics, topic.TopicId)
+			if topic.TopicId == metadataTopicId {
+				clusterTopic = metadataTopic()
+			}
+		} else {
+			clusterTopic = findTopicByName(clusterTopics, topic.Topic)
+			if topic.Topic == "__cluster_metadata" {
+				clusterTopic = metadataTopic()
 			}
-			request.ForgottenTopicsData[i] = forgottenTopic
 		}
-	}
-
-	rackIDLength, _ := binary.ReadUvarint(buffer)
-	if rackIDLength > 0 {
-		rackIDBytes := make([]byte, rackIDLength-1)
-		buffer.Read(rackIDBytes)
-
-		request.RackID = string(rackIDBytes)
-	}
-
-	ignoreTagField(buffer)
-	fmt.Printf("%+v\n", request)
-}
-
-func (response *FetchResponse) bytes(buffer *bytes.Buffer) {
 
-	binary.Write(buffer, binary.BigEndian, response.ThrottleTimeMs)
-	binary.Write(buffer, binary.BigEndian, response.ErrorCode)
-	binary.Write(buffer, binary.BigEndian, response.SessionID)
-
-	binary.Write(buffer, binary.BigEndian, binary.AppendUvarint([]byte{}, uint64(len(response.Responses)+1)))
-	for _, topicResponse := range response.Responses {
-		topicBytes, _ := topicResponse.TopicID.MarshalBinary()
-		buffer.Write(topicBytes)
+		for _, fetchPartition := range topic.Partitions {
+			partition := &protocol.FetchResponsePartitionData{}
+			partition.Default()
+			partition.PartitionIndex = fetchPartition.Partition
+			partition.ErrorCode = errorCodeNone
+			partition.HighWatermark = -1
+			partition.AbortedTransactions = []*protocol.FetchResponseAbortedTransaction{}
+			partition.Records = []byte{}
+			topicResponse.Partitions = append(topicResponse.Partitions, partition)
+
+			if clusterTopic == nil {
+				partition.ErrorCode = unknownTopicErrorCode
+				continue
+			}
+			clusterPartition := findPartition(clusterTopic, fetchPartition.Partition)
+			if clusterPartition == nil {
+				partition.ErrorCode = errorCodeUnknownTopicOrPartition
+				continue
+			}
+			// -1 skips the check, clients send the epoch they got from Metadata.
+			// The metadata log's epoch isn't known here, so it is never checked.
+			epochKnown := fetchPartition.CurrentLeaderEpoch != -1 && clusterPartition.leaderEpoch != -1
+			if epochKnown && fetchPartition.CurrentLeaderEpoch < clusterPartition.leaderEpoch {
+				partition.ErrorCode = errorCodeFencedLeaderEpoch
+				partition.CurrentLeader = &protocol.FetchResponseLeaderIdAndEpoch{
+					LeaderId:    clusterPartition.leaderId,
+					LeaderEpoch: clusterPartition.leaderEpoch,
+				}
+				addNodeEndpoint(&fetchResponse, image, clusterPartition.leaderId)
+				continue
+			}
+			if epochKnown && fetchPartition.CurrentLeaderEpoch > clusterPartition.leaderEpoch {
+				partition.ErrorCode = errorCodeUnknownLeaderEpoch
+				continue
+			}
 
-		binary.Write(buffer, binary.BigEndian, binary.AppendUvarint([]byte{}, uint64(len(topicResponse.Partitions)+1)))
-		for _, partitionResponse := range topicResponse.Partitions {
-			binary.Write(buffer, binary.BigEndian, partitionResponse.PartitionIndex)
-			binary.Write(buffer, binary.BigEndian, partitionResponse.ErrorCode)
-			binary.Write(buffer, binary.BigEndian, partitionResponse.HighWatermark)
-			binary.Write(buffer, binary.BigEndian, partitionResponse.LastStableOffset)
-			binary.Write(buffer, binary.BigEndian, partitionResponse.LogStartOffset)
+			partitionLog, err := getPartitionLog(clusterTopic.name, fetchPartition.Partition)
+			if err != nil {
+				fmt.Printf("Error while getting topic log. %s\n", err)
+				partition.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
 
-			binary.Write(buffer, binary.BigEndian, binary.AppendUvarint([]byte{}, uint64(len(partitionResponse.AbortedTransactions)+1)))
-			for _, abortedTransaction := range partitionResponse.AbortedTransactions {
-				binary.Write(buffer, binary.BigEndian, abortedTransaction.ProducerID)
+			logStartOffset, highWatermark := partitionLog.offsets()
+			partition.HighWatermark = highWatermark
+			partition.LastStableOffset = partitionLog.lastStableOffset()
+			partition.LogStartOffset = logStartOffset
 
-				binary.Write(buffer, binary.BigEndian, abortedTransaction.FirstOffset)
-				addTagField(buffer)
+			// like a KRaft leader, a fetcher behind the start of the metadata
+			// log is sent the snapshot to load instead
+			if clusterTopic.topicId == metadataTopicId && fetchPartition.FetchOffset < logStartOffset {
+				snapshotId, err := latestSnapshotId(partitionLog.dir)
+				if err != nil {
+					fmt.Printf("Error while listing metadata snapshots. %s\n", err)
+					partition.ErrorCode = errorCodeKafkaStorageError
+					continue
+				}
+				if snapshotId != nil {
+					partition.SnapshotId = snapshotId
+					continue
+				}
+			}
+			if fetchPartition.FetchOffset < logStartOffset || fetchPartition.FetchOffset > highWatermark {
+				partition.ErrorCode = errorCodeOffsetOutOfRange
+				continue
 			}
 
-			binary.Write(buffer, binary.BigEndian, partitionResponse.PreferredReadReplica)
-
-			binary.Write(buffer, binary.BigEndian, binary.AppendUvarint([]byte{}, uint64(len(partitionResponse.Records)+1)))
-			buffer.Write(partitionResponse.Records)
-			addTagField(buffer)
+			maxBytes := min(int(fetchPartition.PartitionMaxBytes), remainingBytes)
+			records, err := partitionLog.read(fetchPartition.FetchOffset, maxBytes, emptyResponse)
+			if errors.Is(err, errCorruptRecordBatch) {
+				fmt.Printf("Error while reading topic log. %s\n", err)
+				partition.ErrorCode = errorCodeCorruptMessage
+				continue
+			}
+			if err != nil {
+				fmt.Printf("Error while reading topic log. %s\n", err)
+				partition.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
+			// zstd came with fetch v10, older clients can't decompress it
+			if request.apiVersion < 10 && containsCodec(records, compressionZstd) {
+				partition.ErrorCode = errorCodeUnsupportedCompressionType
+				continue
+			}
+			partition.Records = records
+			remainingBytes = max(remainingBytes-len(records), 0)
+			if len(records) > 0 {
+				emptyResponse = false
+			}
 		}
-		addTagField(buffer)
+
+		fetchResponse.Responses = append(fetchResponse.Responses, topicResponse)
 	}
-	addTagField(buffer)
 
+	commonResponse.body = protocol.NewEncoder(fetchResponse.IsFlexible(request.apiVersion))
+	fetchResponse.Encode(commonResponse.body, request.apiVersion)
 }
 
-func (request *FetchRequest) generateResponse(commonResponse *Response) {
-	commonResponse.correlationId = request.correlationId
-
-	fetchResponse := FetchResponse{}
-	fetchResponse.ThrottleTimeMs = 0
-	fetchResponse.ErrorCode = 0
-	fetchResponse.SessionID = request.SessionID
+// metadataTopicId is the topic id kafka reserves for the metadata log, which
+// has no TopicRecord of its own.
+var metadataTopicId = uuid.UUID{15: 1}
+
+// metadataTopic describes the metadata log for KRaft observers fetching it,
+// this broker leads its only partition.
+func metadataTopic() *Topic {
+	return &Topic{
+		name:       "__cluster_metadata",
+		topicId:    metadataTopicId,
+		isInternal: true,
+		partitions: []*Partition{{partitionIndex: 0, leaderId: serverConfig.nodeId, leaderEpoch: -1}},
+	}
+}
 
-	topicRecords, err := getTopicRecordList()
+// latestSnapshotId returns the id of the newest KRaft snapshot in dir, the
+// <end offset>-<epoch>.checkpoint files the controller writes, or nil if
+// there is none.
+func latestSnapshotId(dir string) (*protocol.FetchResponseSnapshotId, error) {
+	entries, err := os.ReadDir(dir)
 	if err != nil {
-		fmt.Printf("Error while getting topic record list. %s", err)
+		return nil, err
 	}
 
-	for _, topic := range request.Topics {
-		topicResponse := &FetchResponseTopic{
-			TopicID: topic.TopicID,
-			Partitions: []*FetchResponsePartition{
-				{
-					PartitionIndex: 0,
-					ErrorCode:      100,
-				},
-			},
+	var latest *protocol.FetchResponseSnapshotId
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), ".checkpoint")
+		if !found {
+			continue
+		}
+		offsetPart, epochPart, found := strings.Cut(name, "-")
+		if !found {
+			continue
+		}
+		endOffset, err := strconv.ParseInt(offsetPart, 10, 64)
+		if err != nil {
+			continue
 		}
+		epoch, err := strconv.ParseInt(epochPart, 10, 32)
+		if err != nil {
+			continue
+		}
+		if latest == nil || endOffset > latest.EndOffset || endOffset == latest.EndOffset && int32(epoch) > latest.Epoch {
+			latest = &protocol.FetchResponseSnapshotId{EndOffset: endOffset, Epoch: int32(epoch)}
+		}
+	}
+	return latest, nil
+}
 
-		for _, topicRecord := range topicRecords {
-			partition := topicResponse.Partitions[0]
-			if topic.TopicID == topicRecord.topicId {
-				partition.ErrorCode = 0
-				topicLog, err := getTopicLog(topicRecord.name, int(partition.PartitionIndex))
-				if err != nil {
-					fmt.Printf("Error while getting topic log. %s", err)
-				}
-				partition.Records = append(partition.Records, *topicLog...)
-			}
+// addNodeEndpoint adds the endpoint of a leader named in CurrentLeader to
+// the response, v16+ sends them so clients don't need a Metadata request.
+func addNodeEndpoint(response *protocol.FetchResponse, image *MetadataImage, nodeId int32) {
+	for _, nodeEndpoint := range response.NodeEndpoints {
+		if nodeEndpoint.NodeId == nodeId {
+			return
 		}
+	}
 
-		fetchResponse.Responses = append(fetchResponse.Responses, topicResponse)
+	nodeEndpoint := &protocol.FetchResponseNodeEndpoint{NodeId: nodeId}
+	if nodeId == serverConfig.nodeId {
+		nodeEndpoint.Host = serverConfig.advertisedHost
+		nodeEndpoint.Port = serverConfig.advertisedPort
+	} else if broker, ok := image.brokers[nodeId]; ok && len(broker.endPoints) > 0 {
+		nodeEndpoint.Host = broker.endPoints[0].host
+		nodeEndpoint.Port = int32(broker.endPoints[0].port)
+		nodeEndpoint.Rack = broker.rack
+	} else {
+		return
 	}
+	response.NodeEndpoints = append(response.NodeEndpoints, nodeEndpoint)
+}
 
-	fetchResponse.bytes(&commonResponse.BytesData)
+// containsCodec reports whether any batch of records is compressed with codec.
+func containsCodec(records []byte, codec int16) bool {
+	found := false
+	forEachBatch(records, func(batch *ClusterMetadata, batchBytes []byte) {
+		found = found || recordBatchCodec(batchBytes) == codec
+	})
+	return found
 }
\ No newline at end of file
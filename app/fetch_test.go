@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+	"github.com/google/uuid"
+)
+
+// newTestFetchTopic serves a topic whose partitions hold the given number
+// of one-record batches, each batch in a segment of its own. It returns the
+// topic and the size of a batch.
+func newTestFetchTopic(t *testing.T, leaderEpoch int32, batchCounts ...int) (*Topic, int) {
+	t.Helper()
+
+	config, logs, image := serverConfig, partitionLogs, currentMetadataImage.Load()
+	t.Cleanup(func() {
+		serverConfig, partitionLogs = config, logs
+		currentMetadataImage.Store(image)
+	})
+	serverConfig = defaultConfig()
+	serverConfig.logDir = t.TempDir()
+	serverConfig.logSegmentBytes = 1
+	partitionLogs = map[string]*PartitionLog{}
+
+	topic := &Topic{name: "fetch", topicId: uuid.New()}
+	batchSize := 0
+	for partitionIndex, batchCount := range batchCounts {
+		topic.partitions = append(topic.partitions, &Partition{partitionIndex: int32(partitionIndex), leaderId: 1, leaderEpoch: leaderEpoch})
+
+		partitionLog, err := getPartitionLog(topic.name, int32(partitionIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < batchCount; i++ {
+			batch := newRecordBatch(0, 1_700_000_000_000, nil, [][]byte{[]byte("value")})
+			batchSize = len(batch)
+			if _, err := partitionLog.append(batch); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	metadataImage := newMetadataImage()
+	metadataImage.topics = []*Topic{topic}
+	metadataImage.topicsByName[topic.name] = topic
+	metadataImage.topicsById[topic.topicId] = topic
+	currentMetadataImage.Store(metadataImage)
+	return topic, batchSize
+}
+
+// fetch sends request and returns the partitions of the response in order.
+func fetch(t *testing.T, request *FetchRequest) []*protocol.FetchResponsePartitionData {
+	t.Helper()
+
+	response := &Response{}
+	request.generateResponse(response)
+	if err := response.body.Err(); err != nil {
+		t.Fatal(err)
+	}
+	fetchResponse := &protocol.FetchResponse{}
+	if err := fetchResponse.Decode(protocol.NewDecoder(response.body.Bytes(), false), request.apiVersion); err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := []*protocol.FetchResponsePartitionData{}
+	for _, topic := range fetchResponse.Responses {
+		partitions = append(partitions, topic.Partitions...)
+	}
+	return partitions
+}
+
+// batchOffsets returns the base offset of every batch in records.
+func batchOffsets(records []byte) []int64 {
+	offsets := []int64{}
+	for position := 0; position+recordBatchLogOverhead <= len(records); {
+		offsets = append(offsets, int64(binary.BigEndian.Uint64(records[position:])))
+		position += recordBatchLogOverhead + int(binary.BigEndian.Uint32(records[position+recordBatchLengthPosition:]))
+	}
+	return offsets
+}
+
+func TestFetchOffsetsAndLimits(t *testing.T) {
+	topic, batchSize := newTestFetchTopic(t, 0, 5, 2)
+
+	type fetchPartition struct {
+		partition         int32
+		fetchOffset       int64
+		partitionMaxBytes int
+	}
+	type fetchedPartition struct {
+		errorCode int16
+		offsets   []int64
+	}
+	tests := []struct {
+		name       string
+		maxBytes   int
+		partitions []fetchPartition
+		want       []fetchedPartition
+	}{
+		{"whole log", 1 << 20, []fetchPartition{{0, 0, 1 << 20}}, []fetchedPartition{{errorCodeNone, []int64{0, 1, 2, 3, 4}}}},
+		{"from the middle", 1 << 20, []fetchPartition{{0, 3, 1 << 20}}, []fetchedPartition{{errorCodeNone, []int64{3, 4}}}},
+		{"at the high watermark", 1 << 20, []fetchPartition{{0, 5, 1 << 20}}, []fetchedPartition{{errorCodeNone, []int64{}}}},
+		{"past the high watermark", 1 << 20, []fetchPartition{{0, 6, 1 << 20}}, []fetchedPartition{{errorCodeOffsetOutOfRange, []int64{}}}},
+		{"below the log start", 1 << 20, []fetchPartition{{0, -1, 1 << 20}}, []fetchedPartition{{errorCodeOffsetOutOfRange, []int64{}}}},
+		{"partition max bytes", 1 << 20, []fetchPartition{{0, 1, 2*batchSize + 1}}, []fetchedPartition{{errorCodeNone, []int64{1, 2}}}},
+		{"first batch larger than the limit", 1 << 20, []fetchPartition{{0, 0, 1}}, []fetchedPartition{{errorCodeNone, []int64{0}}}},
+		{
+			"max bytes shared by the partitions",
+			3 * batchSize,
+			[]fetchPartition{{0, 0, 1 << 20}, {1, 0, 1 << 20}},
+			[]fetchedPartition{{errorCodeNone, []int64{0, 1, 2}}, {errorCodeNone, []int64{}}},
+		},
+		{
+			"first batch only while the response is empty",
+			1,
+			[]fetchPartition{{0, 5, 1 << 20}, {1, 0, 1 << 20}, {0, 0, 1 << 20}},
+			[]fetchedPartition{{errorCodeNone, []int64{}}, {errorCodeNone, []int64{0}}, {errorCodeNone, []int64{}}},
+		},
+		{"unknown partition", 1 << 20, []fetchPartition{{2, 0, 1 << 20}}, []fetchedPartition{{errorCodeUnknownTopicOrPartition, []int64{}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := &FetchRequest{}
+			request.apiVersion = 12
+			request.FetchRequest.Default()
+			request.MaxBytes = int32(test.maxBytes)
+			fetchTopic := &protocol.FetchRequestFetchTopic{Topic: topic.name}
+			for _, partition := range test.partitions {
+				fetchTopic.Partitions = append(fetchTopic.Partitions, &protocol.FetchRequestFetchPartition{
+					Partition:          partition.partition,
+					CurrentLeaderEpoch: -1,
+					FetchOffset:        partition.fetchOffset,
+					PartitionMaxBytes:  int32(partition.partitionMaxBytes),
+				})
+			}
+			request.Topics = []*protocol.FetchRequestFetchTopic{fetchTopic}
+
+			partitions := fetch(t, request)
+			if len(partitions) != len(test.want) {
+				t.Fatalf("%d partitions, want %d", len(partitions), len(test.want))
+			}
+			for i, want := range test.want {
+				offsets := batchOffsets(partitions[i].Records)
+				if partitions[i].ErrorCode != want.errorCode || !slices.Equal(offsets, want.offsets) {
+					t.Fatalf("partition %d: error %d, batches %v, want %d, %v", i, partitions[i].ErrorCode, offsets, want.errorCode, want.offsets)
+				}
+			}
+			if hw := partitions[0].HighWatermark; test.want[0].errorCode != errorCodeUnknownTopicOrPartition && hw != 5 {
+				t.Fatalf("high watermark %d, want 5", hw)
+			}
+		})
+	}
+}
+
+func TestFetchLeaderEpochAndTopicIds(t *testing.T) {
+	topic, _ := newTestFetchTopic(t, 3, 1)
+
+	tests := []struct {
+		name               string
+		apiVersion         int16
+		topicId            uuid.UUID
+		currentLeaderEpoch int32
+		errorCode          int16
+		currentLeader      bool
+	}{
+		{"no epoch", 12, uuid.Nil, -1, errorCodeNone, false},
+		{"current epoch", 12, uuid.Nil, 3, errorCodeNone, false},
+		{"stale epoch", 12, uuid.Nil, 2, errorCodeFencedLeaderEpoch, true},
+		{"newer epoch", 12, uuid.Nil, 4, errorCodeUnknownLeaderEpoch, false},
+		{"topic id", 13, topic.topicId, -1, errorCodeNone, false},
+		{"unknown topic id", 13, uuid.New(), -1, errorCodeUnknownTopicId, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := &FetchRequest{}
+			request.apiVersion = test.apiVersion
+			request.FetchRequest.Default()
+			request.MaxBytes = 1 << 20
+			request.Topics = []*protocol.FetchRequestFetchTopic{{
+				Topic:   topic.name,
+				TopicId: test.topicId,
+				Partitions: []*protocol.FetchRequestFetchPartition{
+					{Partition: 0, CurrentLeaderEpoch: test.currentLeaderEpoch, PartitionMaxBytes: 1 << 20},
+				},
+			}}
+
+			partition := fetch(t, request)[0]
+			if partition.ErrorCode != test.errorCode {
+				t.Fatalf("error %d, want %d", partition.ErrorCode, test.errorCode)
+			}
+			if test.currentLeader != (partition.CurrentLeader != nil && partition.CurrentLeader.LeaderEpoch == 3) {
+				t.Fatalf("current leader %+v", partition.CurrentLeader)
+			}
+		})
+	}
+}
+
+func TestFetchMetadataSnapshotId(t *testing.T) {
+	newTestFetchTopic(t, 0)
+
+	// the controller deleted the metadata log below its last snapshot
+	dir := fmt.Sprintf("%s/__cluster_metadata-0", serverConfig.logDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"00000000000000000010.log", "00000000000000000005-0000000001.checkpoint", "00000000000000000010-0000000002.checkpoint"} {
+		if err := os.WriteFile(fmt.Sprintf("%s/%s", dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		fetchOffset int64
+		errorCode   int16
+		snapshotId  string
+	}{
+		{"below the log start", 3, errorCodeNone, "10-2"},
+		{"at the log start", 10, errorCodeNone, ""},
+		{"past the high watermark", 11, errorCodeOffsetOutOfRange, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := &FetchRequest{}
+			request.apiVersion = 13
+			request.FetchRequest.Default()
+			request.MaxBytes = 1 << 20
+			request.Topics = []*protocol.FetchRequestFetchTopic{{
+				TopicId: metadataTopicId,
+				Partitions: []*protocol.FetchRequestFetchPartition{
+					{Partition: 0, CurrentLeaderEpoch: 2, FetchOffset: test.fetchOffset, PartitionMaxBytes: 1 << 20},
+				},
+			}}
+
+			partition := fetch(t, request)[0]
+			if partition.ErrorCode != test.errorCode {
+				t.Fatalf("error %d, want %d", partition.ErrorCode, test.errorCode)
+			}
+			snapshotId := ""
+			if partition.SnapshotId != nil {
+				snapshotId = fmt.Sprintf("%d-%d", partition.SnapshotId.EndOffset, partition.SnapshotId.Epoch)
+			}
+			if snapshotId != test.snapshotId {
+				t.Fatalf("snapshot id %q, want %q", snapshotId, test.snapshotId)
+			}
+		})
+	}
+}
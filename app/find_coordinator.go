@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// FindCoordinator
+
+const (
+	coordinatorKeyTypeGroup       int8 = 0
+	coordinatorKeyTypeTransaction int8 = 1
+)
+
+type FindCoordinatorRequest struct {
+	RequestHeader
+	protocol.FindCoordinatorRequest
+}
+
+func (request *FindCoordinatorRequest) parse(decoder *protocol.Decoder) error {
+	return request.FindCoordinatorRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *FindCoordinatorRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	findCoordinatorResponse := protocol.FindCoordinatorResponse{}
+	findCoordinatorResponse.Default()
+	// v0-v3 answer for a single key, v4+ for a batch of them
+	if request.apiVersion < 4 {
+		coordinator := findCoordinator(request.Key, request.KeyType)
+		findCoordinatorResponse.ErrorCode = coordinator.ErrorCode
+		findCoordinatorResponse.ErrorMessage = coordinator.ErrorMessage
+		findCoordinatorResponse.NodeId = coordinator.NodeId
+		findCoordinatorResponse.Host = coordinator.Host
+		findCoordinatorResponse.Port = coordinator.Port
+	} else {
+		for _, key := range request.CoordinatorKeys {
+			findCoordinatorResponse.Coordinators = append(findCoordinatorResponse.Coordinators, findCoordinator(key, request.KeyType))
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(findCoordinatorResponse.IsFlexible(request.apiVersion))
+	findCoordinatorResponse.Encode(commonResponse.body, request.apiVersion)
+}
+
+// findCoordinator answers for a single key. This is the only broker, so it
+// coordinates every group and transaction.
+func findCoordinator(key string, keyType int8) *protocol.FindCoordinatorResponseCoordinator {
+	coordinator := &protocol.FindCoordinatorResponseCoordinator{Key: key, NodeId: -1, Host: "", Port: -1}
+
+	if keyType != coordinatorKeyTypeGroup && keyType != coordinatorKeyTypeTransaction {
+		coordinator.ErrorCode = errorCodeInvalidRequest
+		coordinator.ErrorMessage = fmt.Sprintf("unknown coordinator key type %d", keyType)
+		return coordinator
+	}
+	if keyType == coordinatorKeyTypeGroup && key == "" {
+		coordinator.ErrorCode = errorCodeInvalidGroupId
+		return coordinator
+	}
+	if keyType == coordinatorKeyTypeGroup {
+		// the group's offsets live in __consumer_offsets, so it has to exist
+		// before the broker can act as coordinator
+		if _, err := ensureConsumerOffsetsTopic(); err != nil {
+			fmt.Printf("Error while creating %s. %s\n", consumerOffsetsTopic, err)
+			coordinator.ErrorCode = errorCodeCoordinatorNotAvailable
+			return coordinator
+		}
+	}
+
+	coordinator.NodeId = serverConfig.nodeId
+	coordinator.Host = serverConfig.advertisedHost
+	coordinator.Port = serverConfig.advertisedPort
+	return coordinator
+}
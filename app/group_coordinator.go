@@ -0,0 +1,653 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Group coordinator for the classic consumer group protocol. This broker is
+// the coordinator of every group. Members join with JoinGroup, the leader
+// they elect sends everyone's assignment back with SyncGroup, and members
+// heartbeat until something makes the group rebalance again.
+
+const (
+	groupStateEmpty = iota
+	groupStatePreparingRebalance
+	groupStateCompletingRebalance
+	groupStateStable
+)
+
+type GroupProtocol struct {
+	name     string
+	metadata []byte
+}
+
+type GroupMember struct {
+	memberId           string
+	groupInstanceId    string
+	clientId           string
+	sessionTimeoutMs   int32
+	rebalanceTimeoutMs int32
+	protocolType       string
+	protocols          []*GroupProtocol
+	assignment         []byte
+	// set while the member waits for the rebalance to finish
+	awaitingJoin chan *JoinGroupResult
+	// set while a follower waits for the leader's assignments
+	awaitingSync chan *SyncGroupResult
+	sessionTimer *time.Timer
+}
+
+type JoinGroupResult struct {
+	errorCode    int16
+	generationId int32
+	protocolType string
+	protocolName string
+	leaderId     string
+	memberId     string
+	// only sent to the leader, which computes the assignments
+	members []*GroupMember
+}
+
+type SyncGroupResult struct {
+	errorCode    int16
+	protocolType string
+	protocolName string
+	assignment   []byte
+}
+
+type ConsumerGroup struct {
+	mu           sync.Mutex
+	groupId      string
+	state        int
+	generationId int32
+	protocolType string
+	protocolName string
+	leaderId     string
+	members      map[string]*GroupMember
+	// member ids handed out with MEMBER_ID_REQUIRED that haven't joined yet
+	pendingMembers map[string]bool
+	// group.instance.id of static members to their current member id
+	staticMembers  map[string]string
+	rebalanceTimer *time.Timer
+	// the first rebalance of an empty group waits for more members to show up
+	initialRebalance bool
+}
+
+var (
+	consumerGroups     = map[string]*ConsumerGroup{}
+	consumerGroupsLock sync.Mutex
+)
+
+// getConsumerGroup returns the group with the given id, creating an empty
+// one when create is set. It returns nil for unknown groups otherwise.
+func getConsumerGroup(groupId string, create bool) *ConsumerGroup {
+	consumerGroupsLock.Lock()
+	defer consumerGroupsLock.Unlock()
+
+	group, ok := consumerGroups[groupId]
+	if !ok && create {
+		group = &ConsumerGroup{
+			groupId:        groupId,
+			state:          groupStateEmpty,
+			members:        map[string]*GroupMember{},
+			pendingMembers: map[string]bool{},
+			staticMembers:  map[string]string{},
+		}
+		consumerGroups[groupId] = group
+	}
+	return group
+}
+
+func (member *GroupMember) protocolMetadata(protocolName string) []byte {
+	for _, protocol := range member.protocols {
+		if protocol.name == protocolName {
+			return protocol.metadata
+		}
+	}
+	return nil
+}
+
+func (member *GroupMember) sameProtocols(other *GroupMember) bool {
+	if len(member.protocols) != len(other.protocols) {
+		return false
+	}
+	for i, protocol := range member.protocols {
+		if protocol.name != other.protocols[i].name || string(protocol.metadata) != string(other.protocols[i].metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func failedJoin(memberId string, errorCode int16) chan *JoinGroupResult {
+	result := make(chan *JoinGroupResult, 1)
+	result <- &JoinGroupResult{errorCode: errorCode, generationId: -1, memberId: memberId}
+	return result
+}
+
+func failedSync(errorCode int16) chan *SyncGroupResult {
+	result := make(chan *SyncGroupResult, 1)
+	result <- &SyncGroupResult{errorCode: errorCode}
+	return result
+}
+
+// join adds member to the group, or updates it if it is already in, and
+// returns a channel the JoinGroup response is sent on once the rebalance
+// completes. A member without an id gets one assigned, and when
+// requireKnownMemberId is set it has to join again with it first.
+func (group *ConsumerGroup) join(member *GroupMember, requireKnownMemberId bool) chan *JoinGroupResult {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	if member.sessionTimeoutMs < serverConfig.groupMinSessionTimeoutMs || member.sessionTimeoutMs > serverConfig.groupMaxSessionTimeoutMs {
+		return failedJoin(member.memberId, errorCodeInvalidSessionTimeout)
+	}
+	if !group.supportsProtocols(member) {
+		return failedJoin(member.memberId, errorCodeInconsistentGroupProtocol)
+	}
+
+	if member.memberId == "" {
+		member.memberId = fmt.Sprintf("%s-%s", member.clientId, uuid.NewString())
+
+		if member.groupInstanceId != "" {
+			// a static member coming back replaces its previous incarnation
+			if oldMemberId, ok := group.staticMembers[member.groupInstanceId]; ok {
+				if oldMember, ok := group.members[oldMemberId]; ok {
+					group.replaceMember(oldMember, member)
+				}
+			}
+			group.staticMembers[member.groupInstanceId] = member.memberId
+		} else if requireKnownMemberId {
+			group.pendingMembers[member.memberId] = true
+			pendingMemberId := member.memberId
+			time.AfterFunc(time.Duration(member.sessionTimeoutMs)*time.Millisecond, func() {
+				group.mu.Lock()
+				defer group.mu.Unlock()
+				delete(group.pendingMembers, pendingMemberId)
+				group.maybeCompleteJoin()
+			})
+			return failedJoin(member.memberId, errorCodeMemberIdRequired)
+		}
+
+		if int32(len(group.members)) >= serverConfig.groupMaxSize {
+			return failedJoin(member.memberId, errorCodeGroupMaxSizeReached)
+		}
+		return group.addMember(member)
+	}
+
+	if member.groupInstanceId != "" && group.staticMembers[member.groupInstanceId] != member.memberId {
+		return failedJoin(member.memberId, errorCodeFencedInstanceId)
+	}
+
+	if group.pendingMembers[member.memberId] {
+		delete(group.pendingMembers, member.memberId)
+		if int32(len(group.members)) >= serverConfig.groupMaxSize {
+			return failedJoin(member.memberId, errorCodeGroupMaxSizeReached)
+		}
+		return group.addMember(member)
+	}
+
+	existing, ok := group.members[member.memberId]
+	if !ok {
+		return failedJoin(member.memberId, errorCodeUnknownMemberId)
+	}
+
+	result := make(chan *JoinGroupResult, 1)
+	protocolsChanged := !existing.sameProtocols(member)
+	existing.sessionTimeoutMs = member.sessionTimeoutMs
+	existing.rebalanceTimeoutMs = member.rebalanceTimeoutMs
+	existing.protocols = member.protocols
+	// the JoinGroup it sent before is superseded, answer it so its
+	// connection isn't left waiting
+	if existing.awaitingJoin != nil {
+		existing.awaitingJoin <- &JoinGroupResult{errorCode: errorCodeRebalanceInProgress, generationId: -1, memberId: existing.memberId}
+	}
+	existing.awaitingJoin = result
+
+	switch group.state {
+	case groupStatePreparingRebalance:
+		group.maybeCompleteJoin()
+	case groupStateCompletingRebalance, groupStateStable:
+		// a follower that rejoins without changes just gets the current
+		// generation again, anything else needs a new rebalance
+		if !protocolsChanged && (group.state == groupStateCompletingRebalance || member.memberId != group.leaderId) {
+			existing.awaitingJoin = nil
+			result <- group.joinResult(existing)
+			break
+		}
+		group.prepareRebalance()
+		group.maybeCompleteJoin()
+	}
+	return result
+}
+
+func (group *ConsumerGroup) addMember(member *GroupMember) chan *JoinGroupResult {
+	result := make(chan *JoinGroupResult, 1)
+	member.awaitingJoin = result
+
+	if len(group.members) == 0 {
+		group.protocolType = member.protocolType
+	}
+	group.members[member.memberId] = member
+	if group.leaderId == "" {
+		group.leaderId = member.memberId
+	}
+
+	if group.state != groupStatePreparingRebalance {
+		group.prepareRebalance()
+	}
+	group.maybeCompleteJoin()
+	return result
+}
+
+// replaceMember swaps a static member for its new incarnation, the old one is
+// fenced off.
+func (group *ConsumerGroup) replaceMember(oldMember *GroupMember, member *GroupMember) {
+	if oldMember.sessionTimer != nil {
+		oldMember.sessionTimer.Stop()
+	}
+	if oldMember.awaitingJoin != nil {
+		oldMember.awaitingJoin <- &JoinGroupResult{errorCode: errorCodeFencedInstanceId, generationId: -1, memberId: oldMember.memberId}
+	}
+	if oldMember.awaitingSync != nil {
+		oldMember.awaitingSync <- &SyncGroupResult{errorCode: errorCodeFencedInstanceId}
+	}
+	delete(group.members, oldMember.memberId)
+	if group.leaderId == oldMember.memberId {
+		group.leaderId = member.memberId
+	}
+}
+
+// supportsProtocols checks that member uses the group's protocol type and
+// shares at least one protocol with every other member.
+func (group *ConsumerGroup) supportsProtocols(member *GroupMember) bool {
+	if member.protocolType == "" || len(member.protocols) == 0 {
+		return false
+	}
+	if len(group.members) == 0 {
+		return true
+	}
+	if member.protocolType != group.protocolType {
+		return false
+	}
+
+	for _, protocol := range member.protocols {
+		supported := true
+		for _, other := range group.members {
+			if other.memberId != member.memberId && other.protocolMetadata(protocol.name) == nil {
+				supported = false
+				break
+			}
+		}
+		if supported {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareRebalance moves the group to PreparingRebalance and gives members
+// until the largest rebalance timeout to join again.
+func (group *ConsumerGroup) prepareRebalance() {
+	// followers still waiting for their assignment have to join again
+	for _, member := range group.members {
+		if member.awaitingSync != nil {
+			member.awaitingSync <- &SyncGroupResult{errorCode: errorCodeRebalanceInProgress}
+			member.awaitingSync = nil
+		}
+	}
+
+	group.initialRebalance = group.state == groupStateEmpty && serverConfig.groupInitialRebalanceDelayMs > 0
+	group.state = groupStatePreparingRebalance
+
+	delayMs := int32(0)
+	for _, member := range group.members {
+		delayMs = max(delayMs, member.rebalanceTimeoutMs)
+	}
+	if group.initialRebalance {
+		delayMs = serverConfig.groupInitialRebalanceDelayMs
+	}
+
+	if group.rebalanceTimer != nil {
+		group.rebalanceTimer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Duration(delayMs)*time.Millisecond, func() {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+		if group.rebalanceTimer == timer && group.state == groupStatePreparingRebalance {
+			group.completeJoin()
+		}
+	})
+	group.rebalanceTimer = timer
+}
+
+// maybeCompleteJoin finishes the join phase early once every member has
+// joined again.
+func (group *ConsumerGroup) maybeCompleteJoin() {
+	if group.state != groupStatePreparingRebalance || group.initialRebalance || len(group.pendingMembers) > 0 {
+		return
+	}
+	for _, member := range group.members {
+		if member.awaitingJoin == nil {
+			return
+		}
+	}
+	group.completeJoin()
+}
+
+// completeJoin starts a new generation with the members that joined in time,
+// picks the protocol and answers every pending JoinGroup.
+func (group *ConsumerGroup) completeJoin() {
+	if group.rebalanceTimer != nil {
+		group.rebalanceTimer.Stop()
+		group.rebalanceTimer = nil
+	}
+	group.initialRebalance = false
+
+	for _, member := range group.members {
+		if member.awaitingJoin == nil {
+			fmt.Printf("Removing member %s of group %s, it did not join the rebalance in time\n", member.memberId, group.groupId)
+			group.removeMember(member)
+		}
+	}
+	group.pendingMembers = map[string]bool{}
+
+	group.generationId++
+	if len(group.members) == 0 {
+		group.state = groupStateEmpty
+		group.protocolType = ""
+		group.protocolName = ""
+		group.leaderId = ""
+		return
+	}
+
+	if _, ok := group.members[group.leaderId]; !ok {
+		group.leaderId = group.sortedMembers()[0].memberId
+	}
+	group.protocolName = group.selectProtocol()
+	group.state = groupStateCompletingRebalance
+
+	for _, member := range group.members {
+		member.awaitingJoin <- group.joinResult(member)
+		member.awaitingJoin = nil
+		group.resetSessionTimer(member)
+	}
+}
+
+func (group *ConsumerGroup) joinResult(member *GroupMember) *JoinGroupResult {
+	result := &JoinGroupResult{
+		errorCode:    errorCodeNone,
+		generationId: group.generationId,
+		protocolType: group.protocolType,
+		protocolName: group.protocolName,
+		leaderId:     group.leaderId,
+		memberId:     member.memberId,
+		members:      []*GroupMember{},
+	}
+	if member.memberId == group.leaderId {
+		result.members = group.sortedMembers()
+	}
+	return result
+}
+
+func (group *ConsumerGroup) sortedMembers() []*GroupMember {
+	members := make([]*GroupMember, 0, len(group.members))
+	for _, member := range group.members {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].memberId < members[j].memberId
+	})
+	return members
+}
+
+// selectProtocol picks the protocol every member supports that most members
+// prefer, ties go to the leader's preference.
+func (group *ConsumerGroup) selectProtocol() string {
+	votes := map[string]int{}
+	for _, member := range group.members {
+		for _, protocol := range member.protocols {
+			if group.supportedByAll(protocol.name) {
+				votes[protocol.name]++
+				break
+			}
+		}
+	}
+
+	selected := ""
+	for _, protocol := range group.members[group.leaderId].protocols {
+		if votes[protocol.name] > votes[selected] {
+			selected = protocol.name
+		}
+	}
+	return selected
+}
+
+func (group *ConsumerGroup) supportedByAll(protocolName string) bool {
+	for _, member := range group.members {
+		if member.protocolMetadata(protocolName) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// removeMember drops a member, answering any request it still has waiting.
+// The caller decides whether the group needs to rebalance.
+func (group *ConsumerGroup) removeMember(member *GroupMember) {
+	if member.sessionTimer != nil {
+		member.sessionTimer.Stop()
+	}
+	if member.awaitingJoin != nil {
+		member.awaitingJoin <- &JoinGroupResult{errorCode: errorCodeUnknownMemberId, generationId: -1, memberId: member.memberId}
+		member.awaitingJoin = nil
+	}
+	if member.awaitingSync != nil {
+		member.awaitingSync <- &SyncGroupResult{errorCode: errorCodeUnknownMemberId}
+		member.awaitingSync = nil
+	}
+
+	delete(group.members, member.memberId)
+	if member.groupInstanceId != "" && group.staticMembers[member.groupInstanceId] == member.memberId {
+		delete(group.staticMembers, member.groupInstanceId)
+	}
+	if group.leaderId == member.memberId {
+		group.leaderId = ""
+		if len(group.members) > 0 {
+			group.leaderId = group.sortedMembers()[0].memberId
+		}
+	}
+}
+
+// rebalanceAfterRemoval starts a rebalance once a member is gone, which
+// empties the group right away if it was the last one.
+func (group *ConsumerGroup) rebalanceAfterRemoval() {
+	switch group.state {
+	case groupStateStable, groupStateCompletingRebalance:
+		group.prepareRebalance()
+		group.maybeCompleteJoin()
+	case groupStatePreparingRebalance:
+		group.maybeCompleteJoin()
+	}
+}
+
+// resetSessionTimer restarts the member's session, it is removed from the
+// group if it doesn't heartbeat again within its session timeout.
+func (group *ConsumerGroup) resetSessionTimer(member *GroupMember) {
+	if member.sessionTimer != nil {
+		member.sessionTimer.Stop()
+	}
+	member.sessionTimer = time.AfterFunc(time.Duration(member.sessionTimeoutMs)*time.Millisecond, func() {
+		group.mu.Lock()
+		defer group.mu.Unlock()
+
+		// members waiting in a rebalance are handled by the rebalance timeout
+		if group.members[member.memberId] != member || member.awaitingJoin != nil {
+			return
+		}
+		fmt.Printf("Member %s of group %s expired\n", member.memberId, group.groupId)
+		group.removeMember(member)
+		group.rebalanceAfterRemoval()
+	})
+}
+
+// validateMember checks a request from an existing member of the current
+// generation.
+func (group *ConsumerGroup) validateMember(memberId string, groupInstanceId string, generationId int32) (*GroupMember, int16) {
+	if groupInstanceId != "" {
+		if currentMemberId, ok := group.staticMembers[groupInstanceId]; ok && currentMemberId != memberId {
+			return nil, errorCodeFencedInstanceId
+		}
+	}
+	member, ok := group.members[memberId]
+	if !ok {
+		return nil, errorCodeUnknownMemberId
+	}
+	if generationId != group.generationId {
+		return member, errorCodeIllegalGeneration
+	}
+	return member, errorCodeNone
+}
+
+// sync hands out the assignments. The leader's request carries everyone's
+// assignment, followers wait for it unless it has already arrived.
+func (group *ConsumerGroup) sync(memberId string, groupInstanceId string, generationId int32, protocolType string, protocolName string, assignments map[string][]byte) chan *SyncGroupResult {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	member, errorCode := group.validateMember(memberId, groupInstanceId, generationId)
+	if errorCode != errorCodeNone {
+		return failedSync(errorCode)
+	}
+	if (protocolType != "" && protocolType != group.protocolType) || (protocolName != "" && protocolName != group.protocolName) {
+		return failedSync(errorCodeInconsistentGroupProtocol)
+	}
+
+	result := make(chan *SyncGroupResult, 1)
+	group.resetSessionTimer(member)
+
+	switch group.state {
+	case groupStatePreparingRebalance:
+		return failedSync(errorCodeRebalanceInProgress)
+	case groupStateCompletingRebalance:
+		// same for a retried SyncGroup
+		if member.awaitingSync != nil {
+			member.awaitingSync <- &SyncGroupResult{errorCode: errorCodeRebalanceInProgress}
+		}
+		member.awaitingSync = result
+		if memberId != group.leaderId {
+			break
+		}
+
+		for _, groupMember := range group.members {
+			groupMember.assignment = assignments[groupMember.memberId]
+			if groupMember.assignment == nil {
+				groupMember.assignment = []byte{}
+			}
+		}
+		group.state = groupStateStable
+		for _, groupMember := range group.members {
+			if groupMember.awaitingSync != nil {
+				groupMember.awaitingSync <- group.syncResult(groupMember)
+				groupMember.awaitingSync = nil
+			}
+		}
+	case groupStateStable:
+		result <- group.syncResult(member)
+	}
+	return result
+}
+
+func (group *ConsumerGroup) syncResult(member *GroupMember) *SyncGroupResult {
+	return &SyncGroupResult{
+		errorCode:    errorCodeNone,
+		protocolType: group.protocolType,
+		protocolName: group.protocolName,
+		assignment:   member.assignment,
+	}
+}
+
+// heartbeat keeps a member's session alive, and tells it when it has to
+// join again because the group is rebalancing.
+func (group *ConsumerGroup) heartbeat(memberId string, groupInstanceId string, generationId int32) int16 {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	member, errorCode := group.validateMember(memberId, groupInstanceId, generationId)
+	if member == nil {
+		return errorCode
+	}
+	if group.state == groupStatePreparingRebalance {
+		return errorCodeRebalanceInProgress
+	}
+	if errorCode != errorCodeNone {
+		return errorCode
+	}
+
+	group.resetSessionTimer(member)
+	return errorCodeNone
+}
+
+// leave removes a member that is shutting down, so the group rebalances
+// without waiting for its session to time out.
+func (group *ConsumerGroup) leave(memberId string, groupInstanceId string) int16 {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	// static members may leave with just their group.instance.id
+	if memberId == "" && groupInstanceId != "" {
+		memberId = group.staticMembers[groupInstanceId]
+	}
+	if groupInstanceId != "" && group.staticMembers[groupInstanceId] != memberId {
+		return errorCodeFencedInstanceId
+	}
+
+	if group.pendingMembers[memberId] {
+		delete(group.pendingMembers, memberId)
+		group.maybeCompleteJoin()
+		return errorCodeNone
+	}
+
+	member, ok := group.members[memberId]
+	if !ok {
+		return errorCodeUnknownMemberId
+	}
+	group.removeMember(member)
+	group.rebalanceAfterRemoval()
+	return errorCodeNone
+}
+
+// validateOffsetCommit checks that a commit comes from a member of the
+// current generation. Commits without a generation come from consumers that
+// assign partitions themselves, which is only allowed while the group has no
+// members.
+func (group *ConsumerGroup) validateOffsetCommit(memberId string, groupInstanceId string, generationId int32) int16 {
+	group.mu.Lock()
+	defer group.mu.Unlock()
+
+	if groupInstanceId != "" {
+		if currentMemberId, ok := group.staticMembers[groupInstanceId]; ok && currentMemberId != memberId {
+			return errorCodeFencedInstanceId
+		}
+	}
+	if generationId < 0 && group.state == groupStateEmpty {
+		return errorCodeNone
+	}
+	if group.state == groupStateCompletingRebalance {
+		return errorCodeRebalanceInProgress
+	}
+
+	member, errorCode := group.validateMember(memberId, groupInstanceId, generationId)
+	if errorCode != errorCodeNone {
+		return errorCode
+	}
+	// a commit is as good as a heartbeat
+	group.resetSessionTimer(member)
+	return errorCodeNone
+}
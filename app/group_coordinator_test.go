@@ -0,0 +1,275 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestConsumerGroup returns an empty group that rebalances as soon as
+// every member joined.
+func newTestConsumerGroup(t *testing.T) *ConsumerGroup {
+	t.Helper()
+
+	config := serverConfig
+	t.Cleanup(func() { serverConfig = config })
+	serverConfig = defaultConfig()
+	serverConfig.groupInitialRebalanceDelayMs = 0
+
+	return &ConsumerGroup{
+		groupId:        "group",
+		state:          groupStateEmpty,
+		members:        map[string]*GroupMember{},
+		pendingMembers: map[string]bool{},
+		staticMembers:  map[string]string{},
+	}
+}
+
+func testGroupMember(memberId string, protocolNames ...string) *GroupMember {
+	member := &GroupMember{
+		memberId:           memberId,
+		clientId:           "client",
+		sessionTimeoutMs:   10_000,
+		rebalanceTimeoutMs: 10_000,
+		protocolType:       "consumer",
+	}
+	for _, name := range protocolNames {
+		member.protocols = append(member.protocols, &GroupProtocol{name: name, metadata: []byte(name)})
+	}
+	return member
+}
+
+func receive[T any](t *testing.T, result chan T) T {
+	t.Helper()
+	select {
+	case value := <-result:
+		return value
+	case <-time.After(time.Second):
+		t.Fatal("no response")
+	}
+	panic("unreachable")
+}
+
+func waiting[T any](t *testing.T, result chan T) {
+	t.Helper()
+	select {
+	case value := <-result:
+		t.Fatalf("response %+v while it should wait", value)
+	default:
+	}
+}
+
+// joinStable takes an empty group to a stable generation with count new
+// members and returns their ids, the first one leads.
+func joinStable(t *testing.T, group *ConsumerGroup, count int) []string {
+	t.Helper()
+
+	memberIds := []string{}
+	for i := 0; i < count; i++ {
+		joins := []chan *JoinGroupResult{group.join(testGroupMember("", "range"), false)}
+		// the members already in join again for the rebalance it starts
+		for _, memberId := range memberIds {
+			joins = append(joins, group.join(testGroupMember(memberId, "range"), false))
+		}
+		for j, join := range joins {
+			result := receive(t, join)
+			if result.errorCode != errorCodeNone {
+				t.Fatalf("join error %d", result.errorCode)
+			}
+			if j == 0 {
+				memberIds = append(memberIds, result.memberId)
+			}
+		}
+	}
+	if memberIds[0] != group.leaderId {
+		t.Fatalf("leader %s, want the first member %s", group.leaderId, memberIds[0])
+	}
+
+	syncs := []chan *SyncGroupResult{}
+	for _, memberId := range memberIds[1:] {
+		syncs = append(syncs, group.sync(memberId, "", group.generationId, "", "", nil))
+	}
+	assignments := map[string][]byte{}
+	for _, memberId := range memberIds {
+		assignments[memberId] = []byte(memberId)
+	}
+	syncs = append(syncs, group.sync(memberIds[0], "", group.generationId, "", "", assignments))
+	for _, sync := range syncs {
+		if result := receive(t, sync); result.errorCode != errorCodeNone {
+			t.Fatalf("sync error %d", result.errorCode)
+		}
+	}
+	if group.state != groupStateStable {
+		t.Fatalf("state %d, want stable", group.state)
+	}
+	return memberIds
+}
+
+func TestConsumerGroupRebalance(t *testing.T) {
+	group := newTestConsumerGroup(t)
+
+	// a member without an id is given one and has to join again with it
+	first := receive(t, group.join(testGroupMember("", "range"), true))
+	if first.errorCode != errorCodeMemberIdRequired || first.memberId == "" {
+		t.Fatalf("join without a member id: error %d, member id %q", first.errorCode, first.memberId)
+	}
+	leaderId := first.memberId
+
+	join := receive(t, group.join(testGroupMember(leaderId, "range"), true))
+	if join.errorCode != errorCodeNone || join.generationId != 1 || join.leaderId != leaderId || len(join.members) != 1 || join.protocolName != "range" {
+		t.Fatalf("first join %+v", join)
+	}
+	sync := receive(t, group.sync(leaderId, "", 1, "consumer", "range", map[string][]byte{leaderId: []byte("a")}))
+	if sync.errorCode != errorCodeNone || string(sync.assignment) != "a" || group.state != groupStateStable {
+		t.Fatalf("leader sync %+v in state %d", sync, group.state)
+	}
+	if errorCode := group.heartbeat(leaderId, "", 1); errorCode != errorCodeNone {
+		t.Fatalf("heartbeat error %d", errorCode)
+	}
+
+	// a second member makes the group rebalance, the leader finds out by heartbeat
+	followerJoin := group.join(testGroupMember("", "range"), false)
+	waiting(t, followerJoin)
+	if errorCode := group.heartbeat(leaderId, "", 1); errorCode != errorCodeRebalanceInProgress {
+		t.Fatalf("heartbeat during the rebalance: error %d", errorCode)
+	}
+	leaderJoin := receive(t, group.join(testGroupMember(leaderId, "range"), false))
+	follower := receive(t, followerJoin)
+	if leaderJoin.generationId != 2 || follower.generationId != 2 || len(leaderJoin.members) != 2 || len(follower.members) != 0 {
+		t.Fatalf("second generation: leader %+v, follower %+v", leaderJoin, follower)
+	}
+	followerId := follower.memberId
+
+	// the follower waits for the leader's assignment
+	followerSync := group.sync(followerId, "", 2, "", "", nil)
+	waiting(t, followerSync)
+	receive(t, group.sync(leaderId, "", 2, "", "", map[string][]byte{followerId: []byte("b")}))
+	if sync := receive(t, followerSync); sync.errorCode != errorCodeNone || string(sync.assignment) != "b" {
+		t.Fatalf("follower sync %+v", sync)
+	}
+
+	// the leader leaving hands the group to the follower
+	if errorCode := group.leave(leaderId, ""); errorCode != errorCodeNone {
+		t.Fatalf("leave error %d", errorCode)
+	}
+	if errorCode := group.heartbeat(followerId, "", 2); errorCode != errorCodeRebalanceInProgress {
+		t.Fatalf("heartbeat after the leader left: error %d", errorCode)
+	}
+	join = receive(t, group.join(testGroupMember(followerId, "range"), false))
+	if join.generationId != 3 || join.leaderId != followerId {
+		t.Fatalf("join after the leader left %+v", join)
+	}
+
+	// the last member leaving empties the group
+	group.leave(followerId, "")
+	if group.state != groupStateEmpty || len(group.members) != 0 {
+		t.Fatalf("state %d with %d members after everyone left", group.state, len(group.members))
+	}
+}
+
+func TestConsumerGroupErrors(t *testing.T) {
+	group := newTestConsumerGroup(t)
+	memberIds := joinStable(t, group, 2)
+	followerId := memberIds[1]
+
+	tooShort := testGroupMember("", "range")
+	tooShort.sessionTimeoutMs = 1
+	otherType := testGroupMember("", "range")
+	otherType.protocolType = "connect"
+
+	joins := []struct {
+		name      string
+		member    *GroupMember
+		errorCode int16
+	}{
+		{"session timeout below the minimum", tooShort, errorCodeInvalidSessionTimeout},
+		{"other protocol type", otherType, errorCodeInconsistentGroupProtocol},
+		{"no protocol in common", testGroupMember("", "roundrobin"), errorCodeInconsistentGroupProtocol},
+		{"unknown member id", testGroupMember("stranger", "range"), errorCodeUnknownMemberId},
+	}
+	for _, test := range joins {
+		if result := receive(t, group.join(test.member, false)); result.errorCode != test.errorCode {
+			t.Fatalf("%s: join error %d, want %d", test.name, result.errorCode, test.errorCode)
+		}
+	}
+
+	generationId := group.generationId
+	syncs := []struct {
+		name         string
+		memberId     string
+		generationId int32
+		protocolName string
+		errorCode    int16
+	}{
+		{"unknown member id", "stranger", generationId, "", errorCodeUnknownMemberId},
+		{"old generation", followerId, generationId - 1, "", errorCodeIllegalGeneration},
+		{"other protocol", followerId, generationId, "roundrobin", errorCodeInconsistentGroupProtocol},
+		{"stable group", followerId, generationId, "range", errorCodeNone},
+	}
+	for _, test := range syncs {
+		if result := receive(t, group.sync(test.memberId, "", test.generationId, "", test.protocolName, nil)); result.errorCode != test.errorCode {
+			t.Fatalf("%s: sync error %d, want %d", test.name, result.errorCode, test.errorCode)
+		}
+	}
+
+	if errorCode := group.heartbeat(followerId, "", generationId-1); errorCode != errorCodeIllegalGeneration {
+		t.Fatalf("heartbeat of an old generation: error %d", errorCode)
+	}
+	if errorCode := group.leave("stranger", ""); errorCode != errorCodeUnknownMemberId {
+		t.Fatalf("leave of an unknown member: error %d", errorCode)
+	}
+}
+
+func TestConsumerGroupRetriedRequests(t *testing.T) {
+	group := newTestConsumerGroup(t)
+	leaderId := joinStable(t, group, 1)[0]
+
+	// the follower joins, gets its id, and joins again with it while the
+	// first JoinGroup still waits for the leader
+	followerJoin := receive(t, group.join(testGroupMember("", "range"), true))
+	followerId := followerJoin.memberId
+	staleJoin := group.join(testGroupMember(followerId, "range"), true)
+	waiting(t, staleJoin)
+	retriedJoin := group.join(testGroupMember(followerId, "range"), true)
+	if result := receive(t, staleJoin); result.errorCode != errorCodeRebalanceInProgress {
+		t.Fatalf("superseded join error %d", result.errorCode)
+	}
+	waiting(t, retriedJoin)
+	receive(t, group.join(testGroupMember(leaderId, "range"), false))
+	if result := receive(t, retriedJoin); result.errorCode != errorCodeNone || result.generationId != 2 {
+		t.Fatalf("retried join %+v", result)
+	}
+
+	// same for a SyncGroup sent again while waiting for the leader
+	staleSync := group.sync(followerId, "", 2, "", "", nil)
+	waiting(t, staleSync)
+	retriedSync := group.sync(followerId, "", 2, "", "", nil)
+	if result := receive(t, staleSync); result.errorCode != errorCodeRebalanceInProgress {
+		t.Fatalf("superseded sync error %d", result.errorCode)
+	}
+	receive(t, group.sync(leaderId, "", 2, "", "", map[string][]byte{followerId: []byte("b")}))
+	if result := receive(t, retriedSync); result.errorCode != errorCodeNone || string(result.assignment) != "b" {
+		t.Fatalf("retried sync %+v", result)
+	}
+}
+
+func TestConsumerGroupStaticMembers(t *testing.T) {
+	group := newTestConsumerGroup(t)
+
+	member := testGroupMember("", "range")
+	member.groupInstanceId = "instance"
+	first := receive(t, group.join(member, true))
+	if first.errorCode != errorCodeNone {
+		t.Fatalf("static join error %d", first.errorCode)
+	}
+
+	// a restarted instance replaces the old member, which is fenced
+	restarted := testGroupMember("", "range")
+	restarted.groupInstanceId = "instance"
+	second := group.join(restarted, true)
+	if result := receive(t, second); result.errorCode != errorCodeNone || result.memberId == first.memberId {
+		t.Fatalf("restarted instance join %+v", result)
+	}
+	if errorCode := group.heartbeat(first.memberId, "instance", group.generationId); errorCode != errorCodeFencedInstanceId {
+		t.Fatalf("heartbeat of the replaced member: error %d", errorCode)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// Committed offsets of consumer groups. Commits are appended to the compacted
+// __consumer_offsets topic, keyed and encoded the way kafka does it, and kept
+// in memory for OffsetFetch. The cache is rebuilt from the topic at startup.
+
+const consumerOffsetsTopic = "__consumer_offsets"
+
+type TopicPartition struct {
+	topic     string
+	partition int32
+}
+
+type CommittedOffset struct {
+	offset          int64
+	leaderEpoch     int32
+	metadata        string
+	commitTimestamp int64
+}
+
+var (
+	committedOffsets     = map[string]map[TopicPartition]*CommittedOffset{}
+	committedOffsetsLock sync.RWMutex
+)
+
+// javaStringHashCode is java's String.hashCode, which kafka uses to spread
+// groups over the partitions of __consumer_offsets.
+func javaStringHashCode(s string) int32 {
+	hash := int32(0)
+	for _, char := range utf16.Encode([]rune(s)) {
+		hash = 31*hash + int32(char)
+	}
+	return hash
+}
+
+// ensureConsumerOffsetsTopic creates __consumer_offsets the first time a
+// group needs it, and returns its number of partitions.
+func ensureConsumerOffsetsTopic() (int32, error) {
+	topic := findTopicByName(getClusterTopics(), consumerOffsetsTopic)
+	if topic == nil {
+		var errorCode int16
+		var err error
+		topic, errorCode, err = createInternalTopic(consumerOffsetsTopic)
+		if errorCode == errorCodeTopicAlreadyExists {
+			topic = findTopicByName(getClusterTopics(), consumerOffsetsTopic)
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	return int32(len(topic.partitions)), nil
+}
+
+func consumerOffsetsPartitionFor(groupId string) (int32, error) {
+	numPartitions, err := ensureConsumerOffsetsTopic()
+	if err != nil {
+		return 0, err
+	}
+	if numPartitions == 0 {
+		return 0, fmt.Errorf("%s has no partitions", consumerOffsetsTopic)
+	}
+
+	hash := javaStringHashCode(groupId)
+	// kafka's Utils.abs
+	if hash == math.MinInt32 {
+		hash = 0
+	} else if hash < 0 {
+		hash = -hash
+	}
+	return hash % numPartitions, nil
+}
+
+// encodeOffsetCommitKey writes an offset commit key, version 1.
+func encodeOffsetCommitKey(groupId string, topicPartition TopicPartition) ([]byte, error) {
+	encoder := protocol.NewEncoder(false)
+	encoder.WriteInt16(1)
+	encoder.WriteString(groupId)
+	encoder.WriteString(topicPartition.topic)
+	encoder.WriteInt32(topicPartition.partition)
+	return encoder.Bytes(), encoder.Err()
+}
+
+// encodeOffsetCommitValue writes an offset commit value, version 3.
+func encodeOffsetCommitValue(committedOffset *CommittedOffset) ([]byte, error) {
+	encoder := protocol.NewEncoder(false)
+	encoder.WriteInt16(3)
+	encoder.WriteInt64(committedOffset.offset)
+	encoder.WriteInt32(committedOffset.leaderEpoch)
+	encoder.WriteString(committedOffset.metadata)
+	encoder.WriteInt64(committedOffset.commitTimestamp)
+	return encoder.Bytes(), encoder.Err()
+}
+
+// commitOffsets makes the offsets durable in __consumer_offsets before they
+// are visible to OffsetFetch.
+func commitOffsets(groupId string, offsets map[TopicPartition]*CommittedOffset) error {
+	if len(offsets) == 0 {
+		return nil
+	}
+
+	partition, err := consumerOffsetsPartitionFor(groupId)
+	if err != nil {
+		return err
+	}
+	offsetsLog, err := getPartitionLog(consumerOffsetsTopic, partition)
+	if err != nil {
+		return err
+	}
+
+	keys := [][]byte{}
+	values := [][]byte{}
+	for topicPartition, committedOffset := range offsets {
+		key, err := encodeOffsetCommitKey(groupId, topicPartition)
+		if err != nil {
+			return err
+		}
+		value, err := encodeOffsetCommitValue(committedOffset)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if _, err := offsetsLog.append(newRecordBatch(0, time.Now().UnixMilli(), keys, values)); err != nil {
+		return err
+	}
+
+	committedOffsetsLock.Lock()
+	defer committedOffsetsLock.Unlock()
+
+	groupOffsets, ok := committedOffsets[groupId]
+	if !ok {
+		groupOffsets = map[TopicPartition]*CommittedOffset{}
+		committedOffsets[groupId] = groupOffsets
+	}
+	for topicPartition, committedOffset := range offsets {
+		groupOffsets[topicPartition] = committedOffset
+	}
+	return nil
+}
+
+// getCommittedOffsets returns a copy of everything the group has committed.
+func getCommittedOffsets(groupId string) map[TopicPartition]*CommittedOffset {
+	committedOffsetsLock.RLock()
+	defer committedOffsetsLock.RUnlock()
+
+	offsets := map[TopicPartition]*CommittedOffset{}
+	for topicPartition, committedOffset := range committedOffsets[groupId] {
+		offsets[topicPartition] = committedOffset
+	}
+	return offsets
+}
+
+// loadCommittedOffsets replays __consumer_offsets into the cache.
+func loadCommittedOffsets() error {
+	topic := findTopicByName(getClusterTopics(), consumerOffsetsTopic)
+	if topic == nil {
+		return nil
+	}
+
+	committedOffsetsLock.Lock()
+	defer committedOffsetsLock.Unlock()
+
+	// a bad record is skipped, the first one is reported once the rest is
+	// replayed
+	var replayErr error
+	for _, partition := range topic.partitions {
+		offsetsLog, err := getPartitionLog(consumerOffsetsTopic, partition.partitionIndex)
+		if err != nil {
+			return err
+		}
+		logStartOffset, _ := offsetsLog.offsets()
+		records, err := offsetsLog.read(logStartOffset, math.MaxInt32, true)
+		if err != nil {
+			return err
+		}
+
+		forEachRecord(records, func(batch *ClusterMetadata, record *Record) {
+			err := replayOffsetCommit(record.key, record.value)
+			if err != nil && replayErr == nil {
+				replayErr = fmt.Errorf("offset commit at %s-%d offset %d: %w", consumerOffsetsTopic, partition.partitionIndex, int64(batch.baseOffset)+record.offsetDelta, err)
+			}
+		})
+	}
+	return replayErr
+}
+
+// replayOffsetCommit applies one __consumer_offsets record to the cache. A
+// null value is a tombstone for the offset. Group metadata records are
+// skipped, group membership doesn't survive a restart.
+func replayOffsetCommit(key []byte, value []byte) error {
+	keyDecoder := protocol.NewDecoder(key, false)
+	keyVersion := keyDecoder.ReadInt16()
+	if keyDecoder.Err() == nil && keyVersion != 0 && keyVersion != 1 {
+		return nil
+	}
+
+	groupId, _ := keyDecoder.ReadNullableString()
+	topicPartition := TopicPartition{}
+	topicPartition.topic, _ = keyDecoder.ReadNullableString()
+	topicPartition.partition = keyDecoder.ReadInt32()
+	if err := keyDecoder.Err(); err != nil {
+		return err
+	}
+
+	committedOffset := &CommittedOffset{leaderEpoch: -1}
+	if value != nil {
+		valueDecoder := protocol.NewDecoder(value, false)
+		valueVersion := valueDecoder.ReadInt16()
+		committedOffset.offset = valueDecoder.ReadInt64()
+		if valueVersion >= 3 {
+			committedOffset.leaderEpoch = valueDecoder.ReadInt32()
+		}
+		committedOffset.metadata, _ = valueDecoder.ReadNullableString()
+		committedOffset.commitTimestamp = valueDecoder.ReadInt64()
+		if err := valueDecoder.Err(); err != nil {
+			return err
+		}
+	}
+
+	groupOffsets, ok := committedOffsets[groupId]
+	if !ok {
+		groupOffsets = map[TopicPartition]*CommittedOffset{}
+		committedOffsets[groupId] = groupOffsets
+	}
+	if value == nil {
+		delete(groupOffsets, topicPartition)
+		return nil
+	}
+	groupOffsets[topicPartition] = committedOffset
+	return nil
+}
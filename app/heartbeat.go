@@ -0,0 +1,33 @@
+package main
+
+import "github.com/codecrafters-io/kafka-starter-go/app/protocol"
+
+// Heartbeat
+
+type HeartbeatRequest struct {
+	RequestHeader
+	protocol.HeartbeatRequest
+}
+
+func (request *HeartbeatRequest) parse(decoder *protocol.Decoder) error {
+	return request.HeartbeatRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *HeartbeatRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	heartbeatResponse := protocol.HeartbeatResponse{}
+	heartbeatResponse.Default()
+
+	group := getConsumerGroup(request.GroupId, false)
+	if request.GroupId == "" {
+		heartbeatResponse.ErrorCode = errorCodeInvalidGroupId
+	} else if group == nil {
+		heartbeatResponse.ErrorCode = errorCodeUnknownMemberId
+	} else {
+		heartbeatResponse.ErrorCode = group.heartbeat(request.MemberId, request.GroupInstanceId, request.GenerationId)
+	}
+
+	commonResponse.body = protocol.NewEncoder(heartbeatResponse.IsFlexible(request.apiVersion))
+	heartbeatResponse.Encode(commonResponse.body, request.apiVersion)
+}
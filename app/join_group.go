@@ -0,0 +1,73 @@
+package main
+
+import "github.com/codecrafters-io/kafka-starter-go/app/protocol"
+
+// JoinGroup
+
+type JoinGroupRequest struct {
+	RequestHeader
+	protocol.JoinGroupRequest
+}
+
+func (request *JoinGroupRequest) parse(decoder *protocol.Decoder) error {
+	if err := request.JoinGroupRequest.Decode(decoder, request.apiVersion); err != nil {
+		return err
+	}
+	// v0 has no separate rebalance timeout, the session timeout is used for both
+	if request.apiVersion == 0 {
+		request.RebalanceTimeoutMs = request.SessionTimeoutMs
+	}
+	return nil
+}
+
+// generateResponse blocks until the group's rebalance completes, which can
+// take up to the rebalance timeout of its members.
+func (request *JoinGroupRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	joinGroupResponse := protocol.JoinGroupResponse{}
+	joinGroupResponse.Default()
+	joinGroupResponse.MemberId = request.MemberId
+	joinGroupResponse.Members = []*protocol.JoinGroupResponseMember{}
+
+	if request.GroupId == "" {
+		joinGroupResponse.ErrorCode = errorCodeInvalidGroupId
+		commonResponse.body = protocol.NewEncoder(joinGroupResponse.IsFlexible(request.apiVersion))
+		joinGroupResponse.Encode(commonResponse.body, request.apiVersion)
+		return
+	}
+
+	member := &GroupMember{
+		memberId:           request.MemberId,
+		groupInstanceId:    request.GroupInstanceId,
+		clientId:           request.clientId,
+		sessionTimeoutMs:   request.SessionTimeoutMs,
+		rebalanceTimeoutMs: request.RebalanceTimeoutMs,
+		protocolType:       request.ProtocolType,
+	}
+	for _, groupProtocol := range request.Protocols {
+		member.protocols = append(member.protocols, &GroupProtocol{name: groupProtocol.Name, metadata: groupProtocol.Metadata})
+	}
+
+	group := getConsumerGroup(request.GroupId, true)
+	// v4+ clients are asked to join again with the member id they are given,
+	// so the coordinator knows about them before they block in a rebalance
+	result := <-group.join(member, request.apiVersion >= 4)
+
+	joinGroupResponse.ErrorCode = result.errorCode
+	joinGroupResponse.GenerationId = result.generationId
+	joinGroupResponse.ProtocolType = result.protocolType
+	joinGroupResponse.ProtocolName = result.protocolName
+	joinGroupResponse.Leader = result.leaderId
+	joinGroupResponse.MemberId = result.memberId
+	for _, groupMember := range result.members {
+		joinGroupResponse.Members = append(joinGroupResponse.Members, &protocol.JoinGroupResponseMember{
+			MemberId:        groupMember.memberId,
+			GroupInstanceId: groupMember.groupInstanceId,
+			Metadata:        groupMember.protocolMetadata(result.protocolName),
+		})
+	}
+
+	commonResponse.body = protocol.NewEncoder(joinGroupResponse.IsFlexible(request.apiVersion))
+	joinGroupResponse.Encode(commonResponse.body, request.apiVersion)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "github.com/codecrafters-io/kafka-starter-go/app/protocol"
+
+// LeaveGroup
+
+type LeaveGroupRequest struct {
+	RequestHeader
+	protocol.LeaveGroupRequest
+}
+
+func (request *LeaveGroupRequest) parse(decoder *protocol.Decoder) error {
+	return request.LeaveGroupRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *LeaveGroupRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	leaveGroupResponse := protocol.LeaveGroupResponse{}
+	leaveGroupResponse.Default()
+
+	group := getConsumerGroup(request.GroupId, false)
+	if request.GroupId == "" {
+		leaveGroupResponse.ErrorCode = errorCodeInvalidGroupId
+	} else if request.apiVersion < 3 {
+		// v0-v2 leave a single member, v3+ a batch of them
+		leaveGroupResponse.ErrorCode = errorCodeUnknownMemberId
+		if group != nil {
+			leaveGroupResponse.ErrorCode = group.leave(request.MemberId, "")
+		}
+	} else {
+		for _, member := range request.Members {
+			memberResponse := &protocol.LeaveGroupResponseMemberResponse{
+				MemberId:        member.MemberId,
+				GroupInstanceId: member.GroupInstanceId,
+				ErrorCode:       errorCodeUnknownMemberId,
+			}
+			if group != nil {
+				memberResponse.ErrorCode = group.leave(member.MemberId, member.GroupInstanceId)
+			}
+			leaveGroupResponse.Members = append(leaveGroupResponse.Members, memberResponse)
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(leaveGroupResponse.IsFlexible(request.apiVersion))
+	leaveGroupResponse.Encode(commonResponse.body, request.apiVersion)
+}
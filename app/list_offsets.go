@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// ListOffsets
+
+// special timestamps a ListOffsets partition can ask for instead of a time
+const (
+	listOffsetsLatestTimestamp        int64 = -1
+	listOffsetsEarliestTimestamp      int64 = -2
+	listOffsetsMaxTimestamp           int64 = -3
+	listOffsetsEarliestLocalTimestamp int64 = -4
+	listOffsetsLatestTieredTimestamp  int64 = -5
+)
+
+const (
+	isolationLevelReadUncommitted int8 = 0
+	isolationLevelReadCommitted   int8 = 1
+)
+
+type ListOffsetsRequest struct {
+	RequestHeader
+	protocol.ListOffsetsRequest
+}
+
+func (request *ListOffsetsRequest) parse(decoder *protocol.Decoder) error {
+	return request.ListOffsetsRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *ListOffsetsRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	listOffsetsResponse := protocol.ListOffsetsResponse{}
+	listOffsetsResponse.Default()
+	clusterTopics := getClusterTopics()
+
+	for _, topic := range request.Topics {
+		topicResponse := &protocol.ListOffsetsResponseListOffsetsTopicResponse{Name: topic.Name}
+		clusterTopic := findTopicByName(clusterTopics, topic.Name)
+
+		for _, listPartition := range topic.Partitions {
+			partition := &protocol.ListOffsetsResponseListOffsetsPartitionResponse{}
+			partition.Default()
+			partition.PartitionIndex = listPartition.PartitionIndex
+			partition.ErrorCode = errorCodeNone
+			partition.OldStyleOffsets = []int64{}
+			topicResponse.Partitions = append(topicResponse.Partitions, partition)
+
+			if clusterTopic == nil || !topicHasPartition(clusterTopic, listPartition.PartitionIndex) {
+				partition.ErrorCode = errorCodeUnknownTopicOrPartition
+				continue
+			}
+
+			partitionLog, err := getPartitionLog(clusterTopic.name, listPartition.PartitionIndex)
+			if err != nil {
+				fmt.Printf("Error while getting topic log. %s\n", err)
+				partition.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
+
+			if request.apiVersion == 0 {
+				partition.OldStyleOffsets = partitionLog.legacyOffsetsBefore(listPartition.Timestamp, int(listPartition.MaxNumOffsets))
+				continue
+			}
+
+			offset, timestamp, err := request.lookupOffset(partitionLog, listPartition.Timestamp)
+			if err != nil {
+				fmt.Printf("Error while looking up offset in topic log. %s\n", err)
+				partition.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
+			partition.Offset = offset
+			partition.Timestamp = timestamp
+		}
+
+		listOffsetsResponse.Topics = append(listOffsetsResponse.Topics, topicResponse)
+	}
+
+	commonResponse.body = protocol.NewEncoder(listOffsetsResponse.IsFlexible(request.apiVersion))
+	listOffsetsResponse.Encode(commonResponse.body, request.apiVersion)
+}
+
+// lookupOffset resolves one of the special timestamps, or a real one, to an
+// offset and the timestamp of the record at it. Offsets a consumer with the
+// request's isolation level can't read yet are never returned.
+func (request *ListOffsetsRequest) lookupOffset(partitionLog *PartitionLog, timestamp int64) (int64, int64, error) {
+	logStartOffset, highWatermark := partitionLog.offsets()
+	maxOffset := highWatermark
+	if request.IsolationLevel == isolationLevelReadCommitted {
+		maxOffset = partitionLog.lastStableOffset()
+	}
+
+	switch timestamp {
+	case listOffsetsLatestTimestamp:
+		return maxOffset, -1, nil
+	case listOffsetsEarliestTimestamp, listOffsetsEarliestLocalTimestamp:
+		return logStartOffset, -1, nil
+	case listOffsetsLatestTieredTimestamp:
+		// nothing is ever moved to tiered storage
+		return -1, -1, nil
+	case listOffsetsMaxTimestamp:
+		return partitionLog.maxTimestampOffset(maxOffset)
+	default:
+		return partitionLog.offsetForTimestamp(timestamp, maxOffset)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+const (
+	recordBatchCompressionMask   = 0x7
+	recordBatchLogAppendTimeFlag = 0x8
+	recordBatchControlFlag       = 0x20
+	// records count, the last field of the batch header
+	recordBatchRecordsCountPosition = 57
+)
+
+// startLogCleaner compacts the partitions of cleanup.policy=compact topics
+// once every log.cleaner.backoff.ms.
+func startLogCleaner() {
+	if !serverConfig.logCleanerEnable {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(serverConfig.logCleanerBackoffMs) * time.Millisecond)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			cleanLogs()
+		}
+	}()
+}
+
+func cleanLogs() {
+	topicConfigs := map[string]map[string]string{}
+	for _, partitionLog := range openPartitionLogs() {
+		if partitionLog.topicName == "__cluster_metadata" {
+			continue
+		}
+
+		configs, ok := topicConfigs[partitionLog.topicName]
+		if !ok {
+			configs = getTopicConfigs(partitionLog.topicName)
+			topicConfigs[partitionLog.topicName] = configs
+		}
+		if !strings.Contains(configs["cleanup.policy"], "compact") {
+			continue
+		}
+
+		deleteRetentionMs := topicConfigInt64(configs, "delete.retention.ms", serverConfig.logCleanerDeleteRetentionMs)
+
+		removed, err := partitionLog.compact(deleteRetentionMs)
+		if err != nil {
+			fmt.Printf("Error while compacting %s. %s\n", partitionLog.dir, err)
+			continue
+		}
+		if removed > 0 {
+			fmt.Printf("Compacted %s, removed %d records\n", partitionLog.dir, removed)
+		}
+	}
+}
+
+// compact rewrites the closed segments of the log so that only the latest
+// record of every key is left. Records keep their offsets, so the log gets
+// gaps instead of being renumbered. Tombstones, records with a null value,
+// are removed once their batch is older than deleteRetentionMs so consumers
+// have had time to see them. It returns the number of records removed.
+func (partitionLog *PartitionLog) compact(deleteRetentionMs int64) (int, error) {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	// the active segment is still being written and is never compacted
+	closedSegments := partitionLog.segments[:len(partitionLog.segments)-1]
+	if len(closedSegments) == 0 {
+		return 0, nil
+	}
+	// nothing to do unless segments were closed since the last run, or
+	// tombstones kept then may have expired by now
+	cleanedUpTo := partitionLog.activeSegment().baseOffset
+	if cleanedUpTo == partitionLog.cleanerCheckpoint && !partitionLog.hasTombstones {
+		return 0, nil
+	}
+
+	segmentsData := make([][]byte, len(closedSegments))
+	latestOffsets := map[string]int64{}
+	// base offset of the last batch of every producer
+	lastProducerBatches := map[int64]int64{}
+	for i, segment := range closedSegments {
+		data, err := os.ReadFile(segment.logFileName())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return 0, err
+		}
+		segmentsData[i] = data
+
+		forEachBatch(data, func(batch *ClusterMetadata, batchBytes []byte) {
+			if int64(batch.producerId) >= 0 {
+				lastProducerBatches[int64(batch.producerId)] = int64(batch.baseOffset)
+			}
+		})
+		forEachRecord(data, func(batch *ClusterMetadata, record *Record) {
+			if record.key != nil {
+				latestOffsets[string(record.key)] = int64(batch.baseOffset) + record.offsetDelta
+			}
+		})
+	}
+
+	tombstoneCutoff := time.Now().UnixMilli() - deleteRetentionMs
+	hasTombstones := false
+	keep := func(batch *ClusterMetadata, record *Record) bool {
+		// records without a key can't be superseded
+		if record.key == nil {
+			return true
+		}
+		if latestOffsets[string(record.key)] != int64(batch.baseOffset)+record.offsetDelta {
+			return false
+		}
+		if record.value == nil {
+			if int64(batch.maxTimestamp) < tombstoneCutoff {
+				return false
+			}
+			hasTombstones = true
+		}
+		return true
+	}
+
+	removed := 0
+	for i, segment := range closedSegments {
+		cleaned := &bytes.Buffer{}
+		segmentRemoved := 0
+		position := 0
+		forEachBatch(segmentsData[i], func(batch *ClusterMetadata, batchBytes []byte) {
+			position += len(batchBytes)
+			// the last batch of a segment holds its next offset, and the last
+			// batch of a producer its sequence and epoch
+			retainEmpty := position == len(segmentsData[i]) ||
+				int64(batch.producerId) >= 0 && lastProducerBatches[int64(batch.producerId)] == int64(batch.baseOffset)
+			compactedBatch, batchRemoved := compactBatch(batch, batchBytes, keep, retainEmpty)
+			cleaned.Write(compactedBatch)
+			segmentRemoved += batchRemoved
+		})
+		if segmentRemoved == 0 {
+			continue
+		}
+
+		cleanedSegment, err := segment.replace(cleaned.Bytes())
+		if err != nil {
+			return removed, err
+		}
+		partitionLog.segments[i] = cleanedSegment
+		removed += segmentRemoved
+	}
+
+	// empty segments are dropped, except the first which keeps the log start
+	// offset where it was. Compaction keeps the last batch of a segment, so
+	// only segments that were empty before are
+	segments := partitionLog.segments[:1]
+	for i, segment := range partitionLog.segments[1:] {
+		if segment.size == 0 && i+1 < len(closedSegments) {
+			if err := segment.delete(); err != nil {
+				return removed, err
+			}
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	partitionLog.segments = segments
+
+	partitionLog.cleanerCheckpoint = cleanedUpTo
+	partitionLog.hasTombstones = hasTombstones
+	return removed, nil
+}
+
+// forEachBatch calls visit with the header and bytes of every complete batch
+// in data.
+func forEachBatch(data []byte, visit func(batch *ClusterMetadata, batchBytes []byte)) {
+	for position := 0; position+recordBatchHeaderSize <= len(data); {
+		batch := &ClusterMetadata{}
+		if err := readRecordBatchHeader(protocol.NewDecoder(data[position:position+recordBatchHeaderSize], false), batch); err != nil {
+			return
+		}
+
+		batchSize := recordBatchLogOverhead + int(batch.batchLength)
+		if batchSize < recordBatchHeaderSize || position+batchSize > len(data) {
+			return
+		}
+		visit(batch, data[position:position+batchSize])
+		position += batchSize
+	}
+}
+
+// forEachRecord calls visit with every record of the data batches in data,
+// decompressing the compressed ones. Control batches are skipped.
+func forEachRecord(data []byte, visit func(batch *ClusterMetadata, record *Record)) {
+	forEachBatch(data, func(batch *ClusterMetadata, batchBytes []byte) {
+		if !isCompactableBatch(batch) {
+			return
+		}
+		records, err := recordBatchRecords(batchBytes)
+		if err != nil {
+			fmt.Printf("Error while decompressing the batch at offset %d. %s\n", batch.baseOffset, err)
+			return
+		}
+
+		recordsDecoder := protocol.NewDecoder(records, false)
+		for i := uint32(0); i < batch.recordsLength && recordsDecoder.Remaining() > 0; i++ {
+			record, err := readRecord(recordsDecoder)
+			if err != nil {
+				fmt.Printf("Error while reading record %d of the batch at offset %d. %s\n", i, batch.baseOffset, err)
+				return
+			}
+			visit(batch, record)
+		}
+	})
+}
+
+// isCompactableBatch reports whether the records of a batch can be compacted.
+// Control batches hold transaction markers rather than keyed records.
+func isCompactableBatch(batch *ClusterMetadata) bool {
+	return batch.attributes&recordBatchControlFlag == 0
+}
+
+// compactBatch returns the batch with only the records keep accepts, along
+// with the number of records left out. The header is kept, including
+// lastOffsetDelta, so the offsets of the batch don't change, and the records
+// left are compressed with the batch's codec again. A batch with no records
+// left is dropped, unless retainEmpty is set: like kafka, its header is then
+// kept with no records, uncompressed.
+func compactBatch(batch *ClusterMetadata, batchBytes []byte, keep func(batch *ClusterMetadata, record *Record) bool, retainEmpty bool) ([]byte, int) {
+	if !isCompactableBatch(batch) {
+		return batchBytes, 0
+	}
+	batchRecords, err := recordBatchRecords(batchBytes)
+	if err != nil {
+		return batchBytes, 0
+	}
+
+	records := &bytes.Buffer{}
+	kept, removed := uint32(0), 0
+	recordsDecoder := protocol.NewDecoder(batchRecords, false)
+	for i := uint32(0); i < batch.recordsLength && recordsDecoder.Remaining() > 0; i++ {
+		record, err := readRecord(recordsDecoder)
+		if err != nil {
+			// a batch that can't be read is left as it is
+			return batchBytes, 0
+		}
+		if keep(batch, record) {
+			records.Write(record.raw)
+			kept++
+		} else {
+			removed++
+		}
+	}
+	if removed == 0 {
+		return batchBytes, 0
+	}
+	codec := recordBatchCodec(batchBytes)
+	if kept == 0 {
+		if !retainEmpty {
+			return nil, removed
+		}
+		codec = compressionNone
+	}
+
+	compacted, err := rebuildRecordBatch(batchBytes, codec, records.Bytes(), kept)
+	if err != nil {
+		fmt.Printf("Error while compressing the batch at offset %d. %s\n", batch.baseOffset, err)
+		return batchBytes, 0
+	}
+	return compacted, removed
+}
+
+// replace swaps the segment's log for data and returns the reopened segment
+// with its indexes rebuilt. The new log is written next to the old one first
+// so a crash never leaves a half written segment behind.
+func (segment *LogSegment) replace(data []byte) (*LogSegment, error) {
+	cleanedFileName := segment.fileName(".log.cleaned")
+	if err := os.WriteFile(cleanedFileName, data, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(cleanedFileName, segment.logFileName()); err != nil {
+		return nil, err
+	}
+
+	// positions have moved, the indexes have to be rebuilt
+	if err := segment.offsetIndex.reset(); err != nil {
+		return nil, err
+	}
+	if err := segment.timeIndex.reset(); err != nil {
+		return nil, err
+	}
+	return openLogSegment(segment.dir, segment.baseOffset)
+}
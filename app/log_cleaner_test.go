@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/binary"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+func testBatchHeader(t *testing.T, batchBytes []byte) *ClusterMetadata {
+	t.Helper()
+	batch := &ClusterMetadata{}
+	if err := readRecordBatchHeader(protocol.NewDecoder(batchBytes[:recordBatchHeaderSize], false), batch); err != nil {
+		t.Fatal(err)
+	}
+	return batch
+}
+
+// testRecordKeys returns the key and offset delta of every record of a batch.
+func testRecordKeys(t *testing.T, batchBytes []byte) ([]string, []int64) {
+	t.Helper()
+	batch := testBatchHeader(t, batchBytes)
+	records, err := recordBatchRecords(batchBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, offsetDeltas := []string{}, []int64{}
+	decoder := protocol.NewDecoder(records, false)
+	for i := uint32(0); i < batch.recordsLength; i++ {
+		record, err := readRecord(decoder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, string(record.key))
+		offsetDeltas = append(offsetDeltas, record.offsetDelta)
+	}
+	return keys, offsetDeltas
+}
+
+func TestCompactBatch(t *testing.T) {
+	keys := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	values := [][]byte{[]byte("1"), []byte("2"), nil, []byte("4")}
+	plainBatch := newRecordBatch(40, 1_700_000_000_000, keys, values)
+	zstdBatch, err := rebuildRecordBatch(plainBatch, compressionZstd, plainBatch[recordBatchHeaderSize:], uint32(len(values)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	controlBatch := slices.Clone(plainBatch)
+	binary.BigEndian.PutUint16(controlBatch[recordBatchAttributesPosition:], recordBatchControlFlag)
+	setRecordBatchCrc(controlBatch)
+
+	tests := []struct {
+		name         string
+		batch        []byte
+		keep         []string
+		retainEmpty  bool
+		removed      int
+		keys         []string
+		offsetDeltas []int64
+		codec        int16
+		dropped      bool
+	}{
+		{"nothing removed", plainBatch, []string{"a", "b", "c", "d"}, false, 0, []string{"a", "b", "c", "d"}, []int64{0, 1, 2, 3}, compressionNone, false},
+		{"some removed", plainBatch, []string{"b", "d"}, false, 2, []string{"b", "d"}, []int64{1, 3}, compressionNone, false},
+		{"tombstone kept", plainBatch, []string{"c"}, false, 3, []string{"c"}, []int64{2}, compressionNone, false},
+		{"compressed", zstdBatch, []string{"a", "d"}, false, 2, []string{"a", "d"}, []int64{0, 3}, compressionZstd, false},
+		{"all removed", plainBatch, nil, false, 4, nil, nil, compressionNone, true},
+		{"all removed, header retained", plainBatch, nil, true, 4, []string{}, []int64{}, compressionNone, false},
+		{"all removed from a compressed batch, header retained", zstdBatch, nil, true, 4, []string{}, []int64{}, compressionNone, false},
+		{"control batch untouched", controlBatch, nil, false, 0, nil, nil, compressionNone, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			batch := testBatchHeader(t, test.batch)
+			keep := func(batch *ClusterMetadata, record *Record) bool {
+				return slices.Contains(test.keep, string(record.key))
+			}
+
+			compacted, removed := compactBatch(batch, test.batch, keep, test.retainEmpty)
+			if removed != test.removed {
+				t.Fatalf("removed %d records, want %d", removed, test.removed)
+			}
+			if test.dropped {
+				if compacted != nil {
+					t.Fatalf("batch of %d bytes left, want it dropped", len(compacted))
+				}
+				return
+			}
+			if !recordBatchCrcMatches(compacted) {
+				t.Fatal("crc of the compacted batch does not match")
+			}
+			if removed == 0 {
+				if !slices.Equal(compacted, test.batch) {
+					t.Fatal("batch changed with nothing removed")
+				}
+				return
+			}
+
+			header := testBatchHeader(t, compacted)
+			if header.baseOffset != batch.baseOffset || header.lastOffsetDelta != batch.lastOffsetDelta {
+				t.Fatalf("offsets %d+%d, want %d+%d", header.baseOffset, header.lastOffsetDelta, batch.baseOffset, batch.lastOffsetDelta)
+			}
+			if codec := recordBatchCodec(compacted); codec != test.codec {
+				t.Fatalf("codec %d, want %d", codec, test.codec)
+			}
+			keys, offsetDeltas := testRecordKeys(t, compacted)
+			if !slices.Equal(keys, test.keys) || !slices.Equal(offsetDeltas, test.offsetDeltas) {
+				t.Fatalf("records %v at %v, want %v at %v", keys, offsetDeltas, test.keys, test.offsetDeltas)
+			}
+		})
+	}
+}
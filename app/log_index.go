@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Sparse indexes kept next to each log segment, in the same format kafka uses.
+//
+// .index entries are 8 bytes: the offset relative to the segment base offset
+// (int32) and the position of the batch in the .log file (int32).
+// .timeindex entries are 12 bytes: a timestamp (int64) and the relative offset
+// (int32) of the first batch at or after it.
+
+const (
+	offsetIndexEntrySize = 8
+	timeIndexEntrySize   = 12
+)
+
+type OffsetIndexEntry struct {
+	offset   int64
+	position int64
+}
+
+type TimeIndexEntry struct {
+	timestamp int64
+	offset    int64
+}
+
+type OffsetIndex struct {
+	fileName   string
+	baseOffset int64
+	entries    []OffsetIndexEntry
+}
+
+type TimeIndex struct {
+	fileName   string
+	baseOffset int64
+	entries    []TimeIndexEntry
+}
+
+func loadOffsetIndex(fileName string, baseOffset int64) (*OffsetIndex, error) {
+	index := &OffsetIndex{fileName: fileName, baseOffset: baseOffset}
+
+	fileData, err := os.ReadFile(fileName)
+	if err != nil {
+		return index, err
+	}
+	if len(fileData)%offsetIndexEntrySize != 0 {
+		return index, fmt.Errorf("index file %s has a partial entry", fileName)
+	}
+
+	for position := 0; position < len(fileData); position += offsetIndexEntrySize {
+		entry := OffsetIndexEntry{
+			offset:   baseOffset + int64(binary.BigEndian.Uint32(fileData[position:])),
+			position: int64(binary.BigEndian.Uint32(fileData[position+4:])),
+		}
+		if len(index.entries) > 0 {
+			lastEntry := index.entries[len(index.entries)-1]
+			if entry.offset <= lastEntry.offset || entry.position <= lastEntry.position {
+				return index, fmt.Errorf("index file %s is not sorted", fileName)
+			}
+		}
+		index.entries = append(index.entries, entry)
+	}
+
+	return index, nil
+}
+
+func loadTimeIndex(fileName string, baseOffset int64) (*TimeIndex, error) {
+	index := &TimeIndex{fileName: fileName, baseOffset: baseOffset}
+
+	fileData, err := os.ReadFile(fileName)
+	if err != nil {
+		return index, err
+	}
+	if len(fileData)%timeIndexEntrySize != 0 {
+		return index, fmt.Errorf("time index file %s has a partial entry", fileName)
+	}
+
+	for position := 0; position < len(fileData); position += timeIndexEntrySize {
+		entry := TimeIndexEntry{
+			timestamp: int64(binary.BigEndian.Uint64(fileData[position:])),
+			offset:    baseOffset + int64(binary.BigEndian.Uint32(fileData[position+8:])),
+		}
+		if len(index.entries) > 0 {
+			lastEntry := index.entries[len(index.entries)-1]
+			if entry.timestamp <= lastEntry.timestamp || entry.offset < lastEntry.offset {
+				return index, fmt.Errorf("time index file %s is not sorted", fileName)
+			}
+		}
+		index.entries = append(index.entries, entry)
+	}
+
+	return index, nil
+}
+
+// lookup returns the position of the last indexed batch ending at or before
+// offset, the batch holding offset is at that position or after it.
+func (index *OffsetIndex) lookup(offset int64) int64 {
+	i := sort.Search(len(index.entries), func(i int) bool {
+		return index.entries[i].offset > offset
+	})
+	if i == 0 {
+		return 0
+	}
+	return index.entries[i-1].position
+}
+
+// lookup returns the offset to start scanning from for the first batch with
+// a timestamp at or after timestamp.
+func (index *TimeIndex) lookup(timestamp int64) int64 {
+	i := sort.Search(len(index.entries), func(i int) bool {
+		return index.entries[i].timestamp >= timestamp
+	})
+	if i == 0 {
+		return index.baseOffset
+	}
+	return index.entries[i-1].offset
+}
+
+func (index *OffsetIndex) append(offset int64, position int64) error {
+	if len(index.entries) > 0 && offset <= index.entries[len(index.entries)-1].offset {
+		return nil
+	}
+
+	entry := make([]byte, offsetIndexEntrySize)
+	binary.BigEndian.PutUint32(entry, uint32(offset-index.baseOffset))
+	binary.BigEndian.PutUint32(entry[4:], uint32(position))
+	if err := appendToFile(index.fileName, entry); err != nil {
+		return err
+	}
+
+	index.entries = append(index.entries, OffsetIndexEntry{offset: offset, position: position})
+	return nil
+}
+
+// maybeAppend only adds an entry when timestamp is larger than the last one,
+// the time index has to stay sorted even if producers send older timestamps.
+func (index *TimeIndex) maybeAppend(timestamp int64, offset int64) error {
+	if len(index.entries) > 0 && timestamp <= index.entries[len(index.entries)-1].timestamp {
+		return nil
+	}
+
+	entry := make([]byte, timeIndexEntrySize)
+	binary.BigEndian.PutUint64(entry, uint64(timestamp))
+	binary.BigEndian.PutUint32(entry[8:], uint32(offset-index.baseOffset))
+	if err := appendToFile(index.fileName, entry); err != nil {
+		return err
+	}
+
+	index.entries = append(index.entries, TimeIndexEntry{timestamp: timestamp, offset: offset})
+	return nil
+}
+
+// reset empties the index, both in memory and on disk, before a rebuild.
+func (index *OffsetIndex) reset() error {
+	index.entries = nil
+	return truncateFile(index.fileName)
+}
+
+func (index *TimeIndex) reset() error {
+	index.entries = nil
+	return truncateFile(index.fileName)
+}
+
+// appendToFile appends data to the index file, an index without a file name
+// is only kept in memory.
+func appendToFile(fileName string, data []byte) error {
+	if fileName == "" {
+		return nil
+	}
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(data)
+	return err
+}
+
+func truncateFile(fileName string) error {
+	err := os.Truncate(fileName, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
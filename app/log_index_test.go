@@ -0,0 +1,75 @@
+package main
+
+import "testing"
+
+func TestOffsetIndexLookup(t *testing.T) {
+	index := &OffsetIndex{
+		baseOffset: 100,
+		entries: []OffsetIndexEntry{
+			{offset: 110, position: 4096},
+			{offset: 120, position: 8192},
+			{offset: 135, position: 12400},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		offset   int64
+		position int64
+	}{
+		{"base offset", 100, 0},
+		{"before the first entry", 109, 0},
+		{"first entry", 110, 4096},
+		{"between entries", 115, 4096},
+		{"last entry", 135, 12400},
+		{"after the last entry", 1000, 12400},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if position := index.lookup(test.offset); position != test.position {
+				t.Fatalf("lookup(%d) = %d, want %d", test.offset, position, test.position)
+			}
+		})
+	}
+
+	empty := &OffsetIndex{baseOffset: 100}
+	if position := empty.lookup(150); position != 0 {
+		t.Fatalf("lookup on an empty index = %d, want 0", position)
+	}
+}
+
+func TestTimeIndexLookup(t *testing.T) {
+	index := &TimeIndex{
+		baseOffset: 100,
+		entries: []TimeIndexEntry{
+			{timestamp: 1000, offset: 110},
+			{timestamp: 2000, offset: 120},
+			{timestamp: 3000, offset: 135},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		offset    int64
+	}{
+		{"before the first entry", 500, 100},
+		{"first entry", 1000, 100},
+		{"between entries", 1500, 110},
+		{"second entry", 2000, 110},
+		{"last entry", 3000, 120},
+		{"after the last entry", 5000, 135},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if offset := index.lookup(test.timestamp); offset != test.offset {
+				t.Fatalf("lookup(%d) = %d, want %d", test.timestamp, offset, test.offset)
+			}
+		})
+	}
+
+	empty := &TimeIndex{baseOffset: 100}
+	if offset := empty.lookup(1500); offset != 100 {
+		t.Fatalf("lookup on an empty index = %d, want the base offset", offset)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Like kafka, a clean shutdown leaves a marker in the log dir. Without it the
+// logs are checked from their recovery point, the offset below which
+// everything was synced to disk, kept in the recovery point checkpoint file.
+const (
+	cleanShutdownFileName           = ".kafka_cleanshutdown"
+	recoveryPointCheckpointFileName = "recovery-point-offset-checkpoint"
+)
+
+func cleanShutdownFile() string {
+	return fmt.Sprintf("%s/%s", serverConfig.logDir, cleanShutdownFileName)
+}
+
+func recoveryPointCheckpointFile() string {
+	return fmt.Sprintf("%s/%s", serverConfig.logDir, recoveryPointCheckpointFileName)
+}
+
+// hadCleanShutdown reports whether the broker stopped cleanly last time.
+func hadCleanShutdown() bool {
+	_, err := os.Stat(cleanShutdownFile())
+	return err == nil
+}
+
+// removeCleanShutdownMarker is called once the logs are loaded, so a crash
+// from now on is noticed at the next start.
+func removeCleanShutdownMarker() error {
+	err := os.Remove(cleanShutdownFile())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// readRecoveryPoints reads the recovery point checkpoint, keyed by partition
+// directory name. A missing checkpoint recovers every log from the start.
+func readRecoveryPoints() (map[string]int64, error) {
+	recoveryPoints := map[string]int64{}
+
+	file, err := os.Open(recoveryPointCheckpointFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return recoveryPoints, nil
+	}
+	if err != nil {
+		return recoveryPoints, err
+	}
+	defer file.Close()
+
+	// version, entry count, then one "topic partition offset" line per entry
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		if lineNumber < 2 {
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			return recoveryPoints, fmt.Errorf("malformed recovery point line %q", scanner.Text())
+		}
+		offset, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return recoveryPoints, err
+		}
+		recoveryPoints[fmt.Sprintf("%s-%s", fields[0], fields[1])] = offset
+	}
+	return recoveryPoints, scanner.Err()
+}
+
+// RecoveryPoint is the offset below which a partition log is on disk.
+type RecoveryPoint struct {
+	topicName      string
+	partitionIndex int32
+	offset         int64
+}
+
+// writeRecoveryPoints writes the checkpoint through a temporary file, so a
+// crash while writing keeps the previous one.
+func writeRecoveryPoints(recoveryPoints []RecoveryPoint) error {
+	slices.SortFunc(recoveryPoints, func(a, b RecoveryPoint) int {
+		return cmp.Or(strings.Compare(a.topicName, b.topicName), cmp.Compare(a.partitionIndex, b.partitionIndex))
+	})
+
+	var checkpoint strings.Builder
+	fmt.Fprintf(&checkpoint, "0\n%d\n", len(recoveryPoints))
+	for _, recoveryPoint := range recoveryPoints {
+		fmt.Fprintf(&checkpoint, "%s %d %d\n", recoveryPoint.topicName, recoveryPoint.partitionIndex, recoveryPoint.offset)
+	}
+
+	tmpFile := recoveryPointCheckpointFile() + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(checkpoint.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, recoveryPointCheckpointFile())
+}
+
+// checkpointRecoveryPoints syncs every open log and records how far each one
+// is on disk.
+func checkpointRecoveryPoints() error {
+	partitionLogs := openPartitionLogs()
+	recoveryPoints := make([]RecoveryPoint, 0, len(partitionLogs))
+	for _, partitionLog := range partitionLogs {
+		recoveryPoint, err := partitionLog.flush()
+		if err != nil {
+			return err
+		}
+		recoveryPoints = append(recoveryPoints, recoveryPoint)
+	}
+	return writeRecoveryPoints(recoveryPoints)
+}
+
+// startRecoveryPointCheckpointer checkpoints the recovery points once every
+// log.flush.offset.checkpoint.interval.ms.
+func startRecoveryPointCheckpointer() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(serverConfig.logFlushCheckpointIntervalMs) * time.Millisecond)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := checkpointRecoveryPoints(); err != nil {
+				fmt.Printf("Error while checkpointing recovery points. %s\n", err)
+			}
+		}
+	}()
+}
+
+// shutdown syncs every log, leaving them locked so nothing is appended after
+// the recovery points are written, and marks the shutdown as clean. On an
+// error the logs are unlocked again.
+func shutdown() error {
+	partitionLogsLock.Lock()
+	lockedLogs := make([]*PartitionLog, 0, len(partitionLogs))
+	unlock := func() {
+		for _, partitionLog := range lockedLogs {
+			partitionLog.mu.Unlock()
+		}
+		partitionLogsLock.Unlock()
+	}
+
+	recoveryPoints := make([]RecoveryPoint, 0, len(partitionLogs))
+	for _, partitionLog := range partitionLogs {
+		partitionLog.mu.Lock()
+		lockedLogs = append(lockedLogs, partitionLog)
+		if err := partitionLog.syncSegments(); err != nil {
+			unlock()
+			return err
+		}
+		partitionLog.recoveryPoint = partitionLog.nextOffset
+		recoveryPoints = append(recoveryPoints, partitionLog.currentRecoveryPoint())
+	}
+
+	if err := writeRecoveryPoints(recoveryPoints); err != nil {
+		unlock()
+		return err
+	}
+	if err := os.WriteFile(cleanShutdownFile(), []byte{}, 0o644); err != nil {
+		unlock()
+		return err
+	}
+	return nil
+}
+
+// recover checks every segment holding offsets from recoveryPoint on, the
+// ones that may not have made it to disk whole. A segment truncated by the
+// check is the new end of the log, the segments after it are deleted.
+// A watched log is left alone: its tail may be a batch the other process is
+// still writing, which a later refresh picks up.
+func (partitionLog *PartitionLog) recover(recoveryPoint int64) error {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	if partitionLog.watched {
+		partitionLog.recoveryPoint = partitionLog.nextOffset
+		return nil
+	}
+
+	for i := partitionLog.segmentIndexFor(recoveryPoint); i < len(partitionLog.segments); i++ {
+		segment := partitionLog.segments[i]
+		if err := segment.rebuildIndexes(); err != nil {
+			return err
+		}
+		if !segment.truncated || i == len(partitionLog.segments)-1 {
+			continue
+		}
+
+		for _, deletedSegment := range partitionLog.segments[i+1:] {
+			fmt.Printf("Deleting %s, it follows a truncated segment\n", deletedSegment.logFileName())
+			if err := deletedSegment.delete(); err != nil {
+				return err
+			}
+		}
+		partitionLog.segments = partitionLog.segments[:i+1]
+	}
+
+	partitionLog.nextOffset = partitionLog.activeSegment().nextOffset
+	partitionLog.recoveryPoint = partitionLog.nextOffset
+	return nil
+}
+
+// flush syncs the log to disk and moves its recovery point to its end. It
+// returns the recovery point, taken under the lock as appends move it.
+func (partitionLog *PartitionLog) flush() (RecoveryPoint, error) {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	if err := partitionLog.syncSegments(); err != nil {
+		return RecoveryPoint{}, err
+	}
+	partitionLog.recoveryPoint = partitionLog.nextOffset
+	return partitionLog.currentRecoveryPoint(), nil
+}
+
+// currentRecoveryPoint returns the log's recovery point, the caller holds the
+// log's lock.
+func (partitionLog *PartitionLog) currentRecoveryPoint() RecoveryPoint {
+	return RecoveryPoint{
+		topicName:      partitionLog.topicName,
+		partitionIndex: partitionLog.partitionIndex,
+		offset:         partitionLog.recoveryPoint,
+	}
+}
+
+// syncSegments syncs the segments written since the recovery point, the
+// caller holds the log's lock.
+func (partitionLog *PartitionLog) syncSegments() error {
+	for i := partitionLog.segmentIndexFor(partitionLog.recoveryPoint); i < len(partitionLog.segments); i++ {
+		if err := partitionLog.segments[i].sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
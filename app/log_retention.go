@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startRetentionCleaner deletes expired segments of every partition log once
+// every log.retention.check.interval.ms.
+func startRetentionCleaner() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(serverConfig.logRetentionCheckIntervalMs) * time.Millisecond)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			enforceRetention()
+		}
+	}()
+}
+
+func enforceRetention() {
+	topicConfigs := map[string]map[string]string{}
+	for _, partitionLog := range openPartitionLogs() {
+		// the metadata log is never cleaned, topics would disappear with it
+		if partitionLog.topicName == "__cluster_metadata" {
+			continue
+		}
+
+		configs, ok := topicConfigs[partitionLog.topicName]
+		if !ok {
+			configs = getTopicConfigs(partitionLog.topicName)
+			topicConfigs[partitionLog.topicName] = configs
+		}
+
+		// compacted topics only delete data when the policy also has delete
+		cleanupPolicy := configs["cleanup.policy"]
+		if cleanupPolicy != "" && !strings.Contains(cleanupPolicy, "delete") {
+			continue
+		}
+
+		retentionMs := topicConfigInt64(configs, "retention.ms", serverConfig.logRetentionMs)
+		retentionBytes := topicConfigInt64(configs, "retention.bytes", serverConfig.logRetentionBytes)
+
+		deleted, err := partitionLog.enforceRetention(retentionMs, retentionBytes)
+		if err != nil {
+			fmt.Printf("Error while enforcing retention on %s. %s\n", partitionLog.dir, err)
+			continue
+		}
+		if deleted > 0 {
+			logStartOffset, _ := partitionLog.offsets()
+			fmt.Printf("Deleted %d segments of %s, log start offset is now %d\n", deleted, partitionLog.dir, logStartOffset)
+		}
+	}
+}
+
+// topicConfigInt64 returns a numeric topic config, or defaultValue when the
+// topic doesn't override it.
+func topicConfigInt64(configs map[string]string, name string, defaultValue int64) int64 {
+	value, ok := configs[name]
+	if !ok {
+		return defaultValue
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("Ignoring invalid topic config %s=%s\n", name, value)
+		return defaultValue
+	}
+	return number
+}
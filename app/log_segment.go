@@ -0,0 +1,481 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// LogSegment is one .log file of a partition together with its .index and
+// .timeindex files. All three are named after the first offset in the segment.
+type LogSegment struct {
+	dir                      string
+	baseOffset               int64
+	nextOffset               int64
+	size                     int64
+	maxTimestamp             int64
+	firstBatchTimestamp      int64
+	bytesSinceLastIndexEntry int
+	offsetIndex              *OffsetIndex
+	timeIndex                *TimeIndex
+	// recovery cut a partial or corrupt batch off the end of the log
+	truncated bool
+	// written by another process too, so it is never truncated and its
+	// indexes are only kept in memory
+	watched bool
+}
+
+func (segment *LogSegment) fileName(suffix string) string {
+	return fmt.Sprintf("%s/%020d%s", segment.dir, segment.baseOffset, suffix)
+}
+
+func (segment *LogSegment) logFileName() string {
+	return segment.fileName(".log")
+}
+
+// openLogSegment loads a segment and its indexes. Indexes that are missing or
+// don't match the log are rebuilt from the log.
+func openLogSegment(dir string, baseOffset int64) (*LogSegment, error) {
+	segment := &LogSegment{
+		dir:                 dir,
+		baseOffset:          baseOffset,
+		nextOffset:          baseOffset,
+		maxTimestamp:        -1,
+		firstBatchTimestamp: -1,
+	}
+
+	logFile, err := os.Stat(segment.logFileName())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if logFile != nil {
+		segment.size = logFile.Size()
+	}
+
+	offsetIndex, offsetIndexErr := loadOffsetIndex(segment.fileName(".index"), baseOffset)
+	timeIndex, timeIndexErr := loadTimeIndex(segment.fileName(".timeindex"), baseOffset)
+	segment.offsetIndex = offsetIndex
+	segment.timeIndex = timeIndex
+
+	if offsetIndexErr != nil || timeIndexErr != nil || !segment.indexesMatchLog() {
+		// missing indexes are expected for segments too small to have entries
+		if segment.size > 0 && len(segment.offsetIndex.entries) > 0 {
+			fmt.Printf("Rebuilding indexes of %s\n", segment.logFileName())
+		}
+		return segment, segment.rebuildIndexes()
+	}
+
+	if segment.size > 0 {
+		firstBatch, err := segment.readBatchHeader(0)
+		if err != nil {
+			return nil, err
+		}
+		segment.firstBatchTimestamp = int64(firstBatch.maxTimestamp)
+	}
+
+	// the indexes are fine, only the batches after the last entry need reading.
+	// The batch of the last entry is already indexed, indexing it again doesn't
+	// add an entry.
+	position := segment.offsetIndex.lookup(1<<63 - 1)
+	if err := segment.recover(position); err != nil {
+		return nil, err
+	}
+	if len(segment.offsetIndex.entries) > 0 && segment.size <= position {
+		// the indexed batch itself was cut off, the index points past the log
+		return segment, segment.rebuildIndexes()
+	}
+	if len(segment.timeIndex.entries) > 0 {
+		segment.maxTimestamp = max(segment.maxTimestamp, segment.timeIndex.entries[len(segment.timeIndex.entries)-1].timestamp)
+	}
+	return segment, nil
+}
+
+// openWatchedLogSegment opens a segment another process writes. Unlike
+// openLogSegment it never truncates it or writes index files: the batches are
+// read like refresh does, stopping before a batch that is only partly written.
+func openWatchedLogSegment(dir string, baseOffset int64) (*LogSegment, error) {
+	segment := &LogSegment{
+		dir:                 dir,
+		baseOffset:          baseOffset,
+		nextOffset:          baseOffset,
+		maxTimestamp:        -1,
+		firstBatchTimestamp: -1,
+		offsetIndex:         &OffsetIndex{baseOffset: baseOffset},
+		timeIndex:           &TimeIndex{baseOffset: baseOffset},
+		watched:             true,
+	}
+	return segment, segment.refresh()
+}
+
+// indexesMatchLog is a cheap sanity check that the last index entries point
+// at batches which really are in the log.
+func (segment *LogSegment) indexesMatchLog() bool {
+	if len(segment.offsetIndex.entries) == 0 {
+		// an empty index is only right for a segment too small to have
+		// entries, which is also cheap to rebuild, so always rebuild it
+		return segment.size == 0
+	}
+
+	lastEntry := segment.offsetIndex.entries[len(segment.offsetIndex.entries)-1]
+	batch, err := segment.readBatchHeader(lastEntry.position)
+	if err != nil {
+		return false
+	}
+	return int64(batch.baseOffset)+int64(batch.lastOffsetDelta) == lastEntry.offset
+}
+
+func (segment *LogSegment) rebuildIndexes() error {
+	if err := segment.offsetIndex.reset(); err != nil {
+		return err
+	}
+	if err := segment.timeIndex.reset(); err != nil {
+		return err
+	}
+	segment.nextOffset = segment.baseOffset
+	segment.maxTimestamp = -1
+	segment.firstBatchTimestamp = -1
+	segment.bytesSinceLastIndexEntry = 0
+
+	return segment.recover(0)
+}
+
+// recover indexes the batches from position to the end of the log, checking
+// their lengths and crcs. The log is truncated at the first batch that is cut
+// short or corrupt, which is what an unclean shutdown leaves behind.
+func (segment *LogSegment) recover(position int64) error {
+	file, err := os.OpenFile(segment.logFileName(), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for position < segment.size {
+		batch, batchBytes, err := readBatchAt(file, position, segment.size)
+		if errors.Is(err, errCorruptRecordBatch) || errors.Is(err, io.ErrUnexpectedEOF) {
+			fmt.Printf("Truncating %s at position %d, %s\n", segment.logFileName(), position, err)
+			if err := file.Truncate(position); err != nil {
+				return err
+			}
+			segment.size = position
+			segment.truncated = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := segment.indexBatch(batch, position); err != nil {
+			return err
+		}
+		position += int64(len(batchBytes))
+	}
+	return nil
+}
+
+// readBatchAt reads the whole batch at position of a log of the given size.
+// It fails with io.ErrUnexpectedEOF for a batch that doesn't fit in the log
+// and errCorruptRecordBatch for one with a bad crc.
+func readBatchAt(file *os.File, position int64, size int64) (*ClusterMetadata, []byte, error) {
+	if position+recordBatchHeaderSize > size {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	batch, err := readBatchHeaderAt(file, position)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	batchSize := recordBatchLogOverhead + int64(batch.batchLength)
+	if batchSize < recordBatchHeaderSize || position+batchSize > size {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	batchBytes := make([]byte, batchSize)
+	if _, err := file.ReadAt(batchBytes, position); err != nil {
+		return nil, nil, err
+	}
+	if batch.magicByte != 2 || !recordBatchCrcMatches(batchBytes) {
+		return nil, nil, errCorruptRecordBatch
+	}
+	return batch, batchBytes, nil
+}
+
+// indexBatch adds index entries for a batch once enough bytes were written
+// since the last entry, and keeps the segment's offsets up to date.
+func (segment *LogSegment) indexBatch(batch *ClusterMetadata, position int64) error {
+	lastOffset := int64(batch.baseOffset) + int64(batch.lastOffsetDelta)
+	segment.maxTimestamp = max(segment.maxTimestamp, int64(batch.maxTimestamp))
+	if position == 0 {
+		segment.firstBatchTimestamp = int64(batch.maxTimestamp)
+	}
+
+	if segment.bytesSinceLastIndexEntry > serverConfig.logIndexIntervalBytes {
+		if err := segment.offsetIndex.append(lastOffset, position); err != nil {
+			return err
+		}
+		if err := segment.timeIndex.maybeAppend(segment.maxTimestamp, lastOffset); err != nil {
+			return err
+		}
+		segment.bytesSinceLastIndexEntry = 0
+	}
+	segment.bytesSinceLastIndexEntry += recordBatchLogOverhead + int(batch.batchLength)
+	segment.nextOffset = lastOffset + 1
+	return nil
+}
+
+func (segment *LogSegment) readBatchHeader(position int64) (*ClusterMetadata, error) {
+	file, err := os.Open(segment.logFileName())
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readBatchHeaderAt(file, position)
+}
+
+func readBatchHeaderAt(file *os.File, position int64) (*ClusterMetadata, error) {
+	headerBytes := make([]byte, recordBatchHeaderSize)
+	if _, err := file.ReadAt(headerBytes, position); err != nil {
+		return nil, err
+	}
+
+	batch := &ClusterMetadata{}
+	if err := readRecordBatchHeader(protocol.NewDecoder(headerBytes, false), batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// scan calls visit with the header of every complete batch from position to
+// the end of the segment, until visit returns false.
+func (segment *LogSegment) scan(position int64, visit func(batch *ClusterMetadata, position int64) bool) error {
+	file, err := os.Open(segment.logFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for position+recordBatchHeaderSize <= segment.size {
+		batch, err := readBatchHeaderAt(file, position)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		batchSize := recordBatchLogOverhead + int64(batch.batchLength)
+		if position+batchSize > segment.size {
+			return nil
+		}
+		if !visit(batch, position) {
+			return nil
+		}
+		position += batchSize
+	}
+	return nil
+}
+
+// append writes batches that already have their offsets assigned.
+func (segment *LogSegment) append(records []byte) error {
+	file, err := os.OpenFile(segment.logFileName(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(records); err != nil {
+		return err
+	}
+
+	for position := 0; position < len(records); {
+		batch := &ClusterMetadata{}
+		if err := readRecordBatchHeader(protocol.NewDecoder(records[position:position+recordBatchHeaderSize], false), batch); err != nil {
+			return err
+		}
+		if err := segment.indexBatch(batch, segment.size+int64(position)); err != nil {
+			return err
+		}
+		position += recordBatchLogOverhead + int(batch.batchLength)
+	}
+	segment.size += int64(len(records))
+	return nil
+}
+
+// refresh indexes the batches another process wrote to the end of the log
+// file. A batch that is only partly written is picked up by a later refresh.
+func (segment *LogSegment) refresh() error {
+	logFile, err := os.Stat(segment.logFileName())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if logFile.Size() <= segment.size {
+		return nil
+	}
+
+	end := segment.size
+	segment.size = logFile.Size()
+	var indexErr error
+	err = segment.scan(end, func(batch *ClusterMetadata, position int64) bool {
+		indexErr = segment.indexBatch(batch, position)
+		end = position + recordBatchLogOverhead + int64(batch.batchLength)
+		return indexErr == nil
+	})
+	segment.size = end
+	if err != nil {
+		return err
+	}
+	return indexErr
+}
+
+// read returns the batch holding fetchOffset and the ones after it that are
+// below highWatermark, stopping before maxBytes is exceeded. With minOneBatch
+// the first batch is returned even if it is larger than maxBytes. The bool
+// reports whether maxBytes cut the read short.
+func (segment *LogSegment) read(fetchOffset int64, maxBytes int, minOneBatch bool, highWatermark int64) ([]byte, bool, error) {
+	start, end := int64(-1), int64(0)
+	limitReached := false
+	err := segment.scan(segment.offsetIndex.lookup(fetchOffset), func(batch *ClusterMetadata, position int64) bool {
+		// anything at or past the high watermark may still be being written
+		if int64(batch.baseOffset) >= highWatermark {
+			return false
+		}
+
+		batchSize := recordBatchLogOverhead + int64(batch.batchLength)
+		if int64(batch.baseOffset)+int64(batch.lastOffsetDelta) >= fetchOffset {
+			if start < 0 {
+				start = position
+			}
+			if position+batchSize-start > int64(maxBytes) && !(minOneBatch && position == start) {
+				limitReached = true
+				return false
+			}
+			end = position + batchSize
+		}
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if start < 0 || end <= start {
+		return []byte{}, limitReached, nil
+	}
+
+	file, err := os.Open(segment.logFileName())
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	records := make([]byte, end-start)
+	if _, err := file.ReadAt(records, start); err != nil {
+		return nil, false, err
+	}
+
+	// serve the batches before a corrupt one, the next read reports it
+	valid := validRecordBatchesLength(records)
+	if valid < len(records) {
+		fmt.Printf("Corrupt record batch in %s at position %d\n", segment.logFileName(), start+int64(valid))
+		if valid == 0 {
+			return nil, false, errCorruptRecordBatch
+		}
+		return records[:valid], true, nil
+	}
+	return records, limitReached, nil
+}
+
+// sync flushes the segment's log file to disk.
+func (segment *LogSegment) sync() error {
+	file, err := os.OpenFile(segment.logFileName(), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// delete removes the segment's log and index files.
+func (segment *LogSegment) delete() error {
+	for _, suffix := range []string{".log", ".index", ".timeindex"} {
+		err := os.Remove(segment.fileName(suffix))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// offsetForTimestamp returns the offset and timestamp of the first record
+// below maxOffset with a timestamp at or after timestamp, or -1 for both if
+// the segment has none.
+func (segment *LogSegment) offsetForTimestamp(timestamp int64, maxOffset int64) (int64, int64, error) {
+	var found *ClusterMetadata
+	foundPosition := int64(0)
+	startPosition := segment.offsetIndex.lookup(segment.timeIndex.lookup(timestamp))
+	err := segment.scan(startPosition, func(batch *ClusterMetadata, position int64) bool {
+		if int64(batch.baseOffset) >= maxOffset {
+			return false
+		}
+		if int64(batch.maxTimestamp) >= timestamp {
+			found = batch
+			foundPosition = position
+			return false
+		}
+		return true
+	})
+	if err != nil || found == nil {
+		return -1, -1, err
+	}
+
+	file, err := os.Open(segment.logFileName())
+	if err != nil {
+		return -1, -1, err
+	}
+	defer file.Close()
+
+	batchBytes := make([]byte, recordBatchLogOverhead+int64(found.batchLength))
+	if _, err := file.ReadAt(batchBytes, foundPosition); err != nil {
+		return -1, -1, err
+	}
+	offset, recordTimestamp := findRecordByTimestamp(found, batchBytes, timestamp)
+	return offset, recordTimestamp, nil
+}
+
+// findRecordByTimestamp returns the offset and timestamp of the first record
+// in the batch with a timestamp at or after timestamp.
+func findRecordByTimestamp(batch *ClusterMetadata, batchBytes []byte, timestamp int64) (int64, int64) {
+	// with LogAppendTime every record has the batch's max timestamp
+	if batch.attributes&recordBatchLogAppendTimeFlag != 0 {
+		return int64(batch.baseOffset), int64(batch.maxTimestamp)
+	}
+	records, err := recordBatchRecords(batchBytes)
+	if err != nil {
+		return int64(batch.baseOffset), int64(batch.maxTimestamp)
+	}
+
+	recordsDecoder := protocol.NewDecoder(records, false)
+	for i := uint32(0); i < batch.recordsLength && recordsDecoder.Remaining() > 0; i++ {
+		record, err := readRecord(recordsDecoder)
+		if err != nil {
+			break
+		}
+		recordTimestamp := int64(batch.baseTimestamp) + record.timestampDelta
+		if recordTimestamp >= timestamp {
+			return int64(batch.baseOffset) + record.offsetDelta, recordTimestamp
+		}
+	}
+	return int64(batch.baseOffset), int64(batch.maxTimestamp)
+}
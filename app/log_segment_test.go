@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// testBatches returns count batches of one record each, starting at offset 0,
+// and their sizes.
+func testBatches(count int) ([]byte, []int) {
+	log := []byte{}
+	sizes := []int{}
+	for i := 0; i < count; i++ {
+		batch := newRecordBatch(int64(i), 1_700_000_000_000+int64(i), nil, [][]byte{[]byte("value")})
+		log = append(log, batch...)
+		sizes = append(sizes, len(batch))
+	}
+	return log, sizes
+}
+
+func TestLogSegmentRecover(t *testing.T) {
+	log, sizes := testBatches(3)
+	twoBatches := sizes[0] + sizes[1]
+
+	tests := []struct {
+		name       string
+		log        func() []byte
+		size       int
+		nextOffset int64
+		truncated  bool
+	}{
+		{
+			name:       "whole log",
+			log:        func() []byte { return slices.Clone(log) },
+			size:       len(log),
+			nextOffset: 3,
+		},
+		{
+			name:       "last batch cut short",
+			log:        func() []byte { return slices.Clone(log[:len(log)-5]) },
+			size:       twoBatches,
+			nextOffset: 2,
+			truncated:  true,
+		},
+		{
+			name:       "partial header at the end",
+			log:        func() []byte { return slices.Clone(log[:twoBatches+10]) },
+			size:       twoBatches,
+			nextOffset: 2,
+			truncated:  true,
+		},
+		{
+			name: "corrupt batch in the middle",
+			log: func() []byte {
+				corrupt := slices.Clone(log)
+				corrupt[sizes[0]+recordBatchHeaderSize]++
+				return corrupt
+			},
+			size:       sizes[0],
+			nextOffset: 1,
+			truncated:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			segment := &LogSegment{dir: dir}
+			if err := os.WriteFile(segment.logFileName(), test.log(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			segment, err := openLogSegment(dir, 0)
+			if err != nil {
+				t.Fatalf("openLogSegment: %s", err)
+			}
+			if segment.size != int64(test.size) || segment.nextOffset != test.nextOffset || segment.truncated != test.truncated {
+				t.Fatalf("size %d, nextOffset %d, truncated %t, want %d, %d, %t",
+					segment.size, segment.nextOffset, segment.truncated, test.size, test.nextOffset, test.truncated)
+			}
+
+			logFile, err := os.Stat(segment.logFileName())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if logFile.Size() != int64(test.size) {
+				t.Fatalf("log file is %d bytes, want %d", logFile.Size(), test.size)
+			}
+		})
+	}
+}
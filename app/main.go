@@ -1,31 +1,36 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 func handleConnection(connection net.Conn) {
 	defer connection.Close()
 
+	// requests are length prefixed, so a single read can hold part of a
+	// request or several pipelined ones. Buffer the connection and cut
+	// frames out of it instead.
+	reader := bufio.NewReader(connection)
+
 	for {
 
 		// ----------- New Method -------------
-		buf := make([]byte, 1024)
-		bbuffer := bytes.Buffer{}
-
-		n, err := connection.Read(buf)
+		frame, err := readRequestFrame(reader, serverConfig.socketRequestMaxBytes)
 		if err != nil {
 			fmt.Println("Closing Connection, Error reading request: ", err.Error())
 			return
 		}
-		bbuffer.Write(buf[:n])
 
-		request, err := parseRequest(&bbuffer)
+		request, err := parseRequest(frame)
 		if err != nil {
-			fmt.Println("Error parsing request: ", err.Error())
+			fmt.Println("Closing Connection, Error parsing request: ", err.Error())
+			return
 		}
 		// fmt.Println(request, bbuffer)
 
@@ -35,17 +40,16 @@ func handleConnection(connection net.Conn) {
 			return
 		}
 
-		bbuffer.Reset()
-		includeTagField := true
-		switch request.(type) {
-		case *ApiVersionsRequest:
-			includeTagField = false
-		case *DescribePartitionsRequest:
-			includeTagField = true
-		default:
-			includeTagField = true
+		if response == nil {
+			continue
+		}
+
+		bbuffer := &bytes.Buffer{}
+		header := request.requestHeader()
+		if err := response.bytes(bbuffer, responseHeaderVersion(header.apiKey, header.apiVersion)); err != nil {
+			fmt.Println("Closing Connection, Error encoding response: ", err.Error())
+			return
 		}
-		response.bytes(&bbuffer, includeTagField)
 		_, err = connection.Write(bbuffer.Bytes())
 		if err != nil {
 			fmt.Println("Error writing response: ", err.Error())
@@ -103,7 +107,42 @@ func handleConnection(connection net.Conn) {
 }
 
 func main() {
-	PORT := 9092
+	if len(os.Args) > 1 {
+		config, err := loadConfig(os.Args[1])
+		if err != nil {
+			fmt.Printf("Error while loading config %s, using defaults. Error Details: %s\n", os.Args[1], err)
+		}
+		serverConfig = config
+	}
+
+	if err := loadPartitionLogs(); err != nil {
+		fmt.Printf("Error while loading partition logs. Error Details: %s\n", err)
+	}
+	if err := loadMetadataImage(); err != nil {
+		fmt.Printf("Error while loading metadata image. Error Details: %s\n", err)
+	}
+	if err := loadCommittedOffsets(); err != nil {
+		fmt.Printf("Error while loading committed offsets. Error Details: %s\n", err)
+	}
+
+	startRetentionCleaner()
+	startLogCleaner()
+	startMetadataLogWatcher()
+	startRecoveryPointCheckpointer()
+
+	// a clean shutdown spares the next start the log recovery
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		<-signals
+		if err := shutdown(); err != nil {
+			fmt.Printf("Error while shutting down. Error Details: %s\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}()
+
+	PORT := serverConfig.port
 	fmt.Printf("Starting Akfak on port %d...\n", PORT)
 
 	l, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", PORT))
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+	"github.com/google/uuid"
+)
+
+// Metadata
+
+type MetadataRequest struct {
+	RequestHeader
+	protocol.MetadataRequest
+}
+
+func (request *MetadataRequest) parse(decoder *protocol.Decoder) error {
+	return request.MetadataRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *MetadataRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	// authorized operations are not supported, the INT32_MIN default means omitted
+	metadataResponse := protocol.MetadataResponse{}
+	metadataResponse.Default()
+	metadataResponse.ClusterId = getClusterId()
+	metadataResponse.ControllerId = serverConfig.nodeId
+	metadataResponse.Brokers = metadataBrokers(getMetadataImage())
+
+	clusterTopics := getClusterTopics()
+
+	// a null array asks for every topic, an empty one too on v0
+	if request.Topics == nil || request.apiVersion == 0 && len(request.Topics) == 0 {
+		for _, topic := range clusterTopics {
+			metadataResponse.Topics = append(metadataResponse.Topics, newMetadataResponseTopic(topic))
+		}
+	}
+
+	for _, requestTopic := range request.Topics {
+		// v10+ looks topics up by id when the name is null
+		if request.apiVersion >= 10 && requestTopic.Name == "" {
+			topic := findTopicById(clusterTopics, requestTopic.TopicId)
+			if topic == nil {
+				metadataResponse.Topics = append(metadataResponse.Topics, newMetadataErrorTopic(errorCodeUnknownTopicId, "", requestTopic.TopicId))
+				continue
+			}
+			metadataResponse.Topics = append(metadataResponse.Topics, newMetadataResponseTopic(topic))
+			continue
+		}
+
+		topic := findTopicByName(clusterTopics, requestTopic.Name)
+		if topic == nil && request.AllowAutoTopicCreation && serverConfig.autoCreateTopicsEnable {
+			var errorCode int16
+			var err error
+			if isInternalTopic(requestTopic.Name) {
+				topic, errorCode, err = createInternalTopic(requestTopic.Name)
+			} else {
+				topic, errorCode, err = createTopic(requestTopic.Name, serverConfig.numPartitions, serverConfig.defaultReplicationFactor, nil)
+			}
+			if err != nil {
+				fmt.Printf("Error while auto creating topic %s. %s\n", requestTopic.Name, err)
+			}
+			if errorCode == errorCodeTopicAlreadyExists {
+				// created by another request in the meantime
+				topic = findTopicByName(getClusterTopics(), requestTopic.Name)
+			} else if errorCode != errorCodeNone {
+				metadataResponse.Topics = append(metadataResponse.Topics, newMetadataErrorTopic(errorCode, requestTopic.Name, uuid.Nil))
+				continue
+			}
+		}
+
+		if topic == nil {
+			metadataResponse.Topics = append(metadataResponse.Topics, newMetadataErrorTopic(errorCodeUnknownTopicOrPartition, requestTopic.Name, uuid.Nil))
+			continue
+		}
+		metadataResponse.Topics = append(metadataResponse.Topics, newMetadataResponseTopic(topic))
+	}
+
+	commonResponse.body = protocol.NewEncoder(metadataResponse.IsFlexible(request.apiVersion))
+	metadataResponse.Encode(commonResponse.body, request.apiVersion)
+}
+
+// metadataBrokers lists the unfenced brokers registered in the metadata
+// image by node id, with their first endpoint. The local broker is always
+// listed with its advertised listener, even before its registration is in
+// the image.
+func metadataBrokers(image *MetadataImage) []*protocol.MetadataResponseBroker {
+	brokers := []*protocol.MetadataResponseBroker{}
+	localBroker := &protocol.MetadataResponseBroker{
+		NodeId: serverConfig.nodeId,
+		Host:   serverConfig.advertisedHost,
+		Port:   serverConfig.advertisedPort,
+	}
+	brokers = append(brokers, localBroker)
+
+	for _, brokerId := range slices.Sorted(maps.Keys(image.brokers)) {
+		broker := image.brokers[brokerId]
+		if brokerId == serverConfig.nodeId {
+			localBroker.Rack = broker.rack
+			continue
+		}
+		if broker.fenced || len(broker.endPoints) == 0 {
+			continue
+		}
+		brokers = append(brokers, &protocol.MetadataResponseBroker{
+			NodeId: brokerId,
+			Host:   broker.endPoints[0].host,
+			Port:   int32(broker.endPoints[0].port),
+			Rack:   broker.rack,
+		})
+	}
+
+	slices.SortFunc(brokers, func(a, b *protocol.MetadataResponseBroker) int {
+		return cmp.Compare(a.NodeId, b.NodeId)
+	})
+	return brokers
+}
+
+func newMetadataResponseTopic(topic *Topic) *protocol.MetadataResponseTopic {
+	metadataTopic := newMetadataErrorTopic(errorCodeNone, topic.name, topic.topicId)
+	metadataTopic.IsInternal = topic.isInternal
+
+	for _, partition := range topic.partitions {
+		metadataTopic.Partitions = append(metadataTopic.Partitions, &protocol.MetadataResponsePartition{
+			ErrorCode:       errorCodeNone,
+			PartitionIndex:  partition.partitionIndex,
+			LeaderId:        partition.leaderId,
+			LeaderEpoch:     partition.leaderEpoch,
+			ReplicaNodes:    partition.replicaNodes,
+			IsrNodes:        partition.isrNodes,
+			OfflineReplicas: partition.offlineReplicas,
+		})
+	}
+
+	return metadataTopic
+}
+
+// newMetadataErrorTopic returns a topic of the response without partitions.
+func newMetadataErrorTopic(errorCode int16, name string, topicId uuid.UUID) *protocol.MetadataResponseTopic {
+	metadataTopic := &protocol.MetadataResponseTopic{}
+	metadataTopic.Default()
+	metadataTopic.ErrorCode = errorCode
+	metadataTopic.Name = name
+	metadataTopic.TopicId = topicId
+	return metadataTopic
+}
+
+// getClusterId reads the cluster id kafka-storage format wrote into
+// meta.properties, clients accept a null cluster id if there is none.
+func getClusterId() string {
+	properties, err := readPropertiesFile(fmt.Sprintf("%s/meta.properties", serverConfig.logDir))
+	if err != nil {
+		return ""
+	}
+	return properties["cluster.id"]
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// MetadataImage is the cluster metadata as of some offset of the metadata log.
+// Images are never modified once published: replaying a batch copies the
+// image and the topics and brokers it changes, so connection goroutines can
+// keep reading the image they got while the next one is built.
+type MetadataImage struct {
+	// offset of the next batch to replay
+	nextOffset int64
+	// in the order they were created
+	topics       []*Topic
+	topicsByName map[string]*Topic
+	topicsById   map[uuid.UUID]*Topic
+	brokers      map[int32]*BrokerRegistration
+	features     map[string]uint16
+	configs      map[ConfigResource]map[string]string
+	// records of the metadata transaction in progress, applied on its end
+	inTransaction      bool
+	transactionRecords []*Record
+}
+
+type BrokerRegistration struct {
+	brokerId             int32
+	brokerEpoch          int64
+	incarnationId        uuid.UUID
+	endPoints            []BrokerEndpoint
+	rack                 string
+	fenced               bool
+	inControlledShutdown bool
+	logDirs              []uuid.UUID
+}
+
+type ConfigResource struct {
+	// 2 is a topic, 4 a broker
+	resourceType uint8
+	resourceName string
+}
+
+var (
+	currentMetadataImage atomic.Pointer[MetadataImage]
+	// serialises replaying the metadata log
+	metadataImageLock sync.Mutex
+)
+
+func newMetadataImage() *MetadataImage {
+	return &MetadataImage{
+		topicsByName: map[string]*Topic{},
+		topicsById:   map[uuid.UUID]*Topic{},
+		brokers:      map[int32]*BrokerRegistration{},
+		features:     map[string]uint16{},
+		configs:      map[ConfigResource]map[string]string{},
+	}
+}
+
+// getMetadataImage returns the latest metadata image.
+func getMetadataImage() *MetadataImage {
+	if image := currentMetadataImage.Load(); image != nil {
+		return image
+	}
+	return newMetadataImage()
+}
+
+// updateMetadataImage replays the batches appended to the metadata log since
+// the current image and publishes the result.
+func updateMetadataImage() error {
+	metadataImageLock.Lock()
+	defer metadataImageLock.Unlock()
+
+	// batches before a corrupt one are still applied
+	image := getMetadataImage()
+	clusterMetadataLogs, err := readClusterMetadata(image.nextOffset)
+
+	for _, clusterMetadata := range clusterMetadataLogs {
+		lastOffset := int64(clusterMetadata.baseOffset) + int64(clusterMetadata.lastOffsetDelta)
+		if lastOffset < image.nextOffset {
+			continue
+		}
+		image = image.replay(clusterMetadata)
+	}
+
+	currentMetadataImage.Store(image)
+	return err
+}
+
+// copy returns an image sharing the topics and brokers of this one, they
+// have to be copied again before they are changed.
+func (image *MetadataImage) copy() *MetadataImage {
+	return &MetadataImage{
+		nextOffset:         image.nextOffset,
+		topics:             slices.Clone(image.topics),
+		topicsByName:       maps.Clone(image.topicsByName),
+		topicsById:         maps.Clone(image.topicsById),
+		brokers:            maps.Clone(image.brokers),
+		features:           maps.Clone(image.features),
+		configs:            maps.Clone(image.configs),
+		inTransaction:      image.inTransaction,
+		transactionRecords: slices.Clone(image.transactionRecords),
+	}
+}
+
+// replay returns the image with the records of a batch applied.
+func (image *MetadataImage) replay(clusterMetadata *ClusterMetadata) *MetadataImage {
+	next := image.copy()
+	next.nextOffset = int64(clusterMetadata.baseOffset) + int64(clusterMetadata.lastOffsetDelta) + 1
+
+	for _, record := range clusterMetadata.records {
+		switch record.recordType {
+		case 23:
+			// beginTransactionRecord
+			next.inTransaction = true
+			next.transactionRecords = nil
+		case 24:
+			// endTransactionRecord
+			for _, transactionRecord := range next.transactionRecords {
+				next.apply(transactionRecord)
+			}
+			next.inTransaction = false
+			next.transactionRecords = nil
+		case 25:
+			// abortTransactionRecord
+			next.inTransaction = false
+			next.transactionRecords = nil
+		default:
+			if next.inTransaction {
+				next.transactionRecords = append(next.transactionRecords, record)
+			} else {
+				next.apply(record)
+			}
+		}
+	}
+	return next
+}
+
+// apply applies one record to an image that isn't published yet.
+func (image *MetadataImage) apply(record *Record) {
+	switch record.recordType {
+	case 0:
+		registerBrokerRecord := record.RegisterBrokerRecord
+		image.brokers[registerBrokerRecord.brokerId] = &BrokerRegistration{
+			brokerId:             registerBrokerRecord.brokerId,
+			brokerEpoch:          registerBrokerRecord.brokerEpoch,
+			incarnationId:        registerBrokerRecord.incarnationId,
+			endPoints:            registerBrokerRecord.endPoints,
+			rack:                 registerBrokerRecord.rack,
+			fenced:               registerBrokerRecord.fenced,
+			inControlledShutdown: registerBrokerRecord.inControlledShutdown,
+			logDirs:              registerBrokerRecord.logDirs,
+		}
+	case 1:
+		delete(image.brokers, record.UnregisterBrokerRecord.brokerId)
+	case 2:
+		if _, ok := image.topicsById[record.TopicRecord.topicId]; ok {
+			// written twice, keep the partitions it already has
+			break
+		}
+		topic := &Topic{
+			errorCode:  0,
+			name:       record.TopicRecord.name,
+			topicId:    record.TopicRecord.topicId,
+			isInternal: isInternalTopic(record.TopicRecord.name),
+		}
+		image.topics = append(image.topics, topic)
+		image.topicsByName[topic.name] = topic
+		image.topicsById[topic.topicId] = topic
+	case 3:
+		topic := image.copyTopic(record.PartitionRecord.topicId)
+		if topic == nil {
+			break
+		}
+		partition := &Partition{
+			errorCode:              0,
+			partitionIndex:         record.PartitionRecord.partitionId,
+			leaderId:               record.PartitionRecord.leader,
+			leaderEpoch:            record.PartitionRecord.leaderEpoch,
+			replicaNodes:           record.PartitionRecord.replicaArray,
+			isrNodes:               record.PartitionRecord.inSyncReplicaArray,
+			eligibleLeaderReplicas: nonNilInt32s(record.PartitionRecord.eligibleLeaderReplicas),
+			lastKnownElr:           nonNilInt32s(record.PartitionRecord.lastKnownElr),
+			offlineReplicas:        []int32{},
+		}
+		// a record for an existing partition replaces it
+		i := slices.IndexFunc(topic.partitions, func(p *Partition) bool {
+			return p.partitionIndex == partition.partitionIndex
+		})
+		if i >= 0 {
+			topic.partitions[i] = partition
+		} else {
+			topic.partitions = append(topic.partitions, partition)
+		}
+	case 4:
+		configRecord := record.ConfigRecord
+		resource := ConfigResource{resourceType: configRecord.resourceType, resourceName: configRecord.resourceName}
+		configs := maps.Clone(image.configs[resource])
+		if configs == nil {
+			configs = map[string]string{}
+		}
+		if configRecord.valueIsNull {
+			delete(configs, configRecord.name)
+		} else {
+			configs[configRecord.name] = configRecord.value
+		}
+		image.configs[resource] = configs
+	case 5:
+		topic := image.copyTopic(record.PartitionChangeRecord.topicId)
+		if topic != nil {
+			applyPartitionChange(topic, &record.PartitionChangeRecord)
+		}
+	case 8, 9:
+		// fence and unfence broker
+		broker := image.copyBroker(record.FenceBrokerRecord.id)
+		if broker != nil {
+			broker.fenced = record.recordType == 8
+		}
+	case 10:
+		topic, ok := image.topicsById[record.RemoveTopicRecord.topicId]
+		if !ok {
+			break
+		}
+		image.topics = slices.DeleteFunc(image.topics, func(t *Topic) bool {
+			return t == topic
+		})
+		delete(image.topicsByName, topic.name)
+		delete(image.topicsById, topic.topicId)
+		// the configs of a topic go with it
+		delete(image.configs, ConfigResource{resourceType: 2, resourceName: topic.name})
+	case 12:
+		// level 0 removes the feature
+		if record.FeatureLevelRecord.featureLevel == 0 {
+			delete(image.features, record.FeatureLevelRecord.name)
+		} else {
+			image.features[record.FeatureLevelRecord.name] = record.FeatureLevelRecord.featureLevel
+		}
+	case 17:
+		change := record.BrokerRegistrationChangeRecord
+		broker := image.copyBroker(change.brokerId)
+		if broker == nil {
+			break
+		}
+		if change.fenced != 0 {
+			broker.fenced = change.fenced > 0
+		}
+		if change.inControlledShutdown != 0 {
+			broker.inControlledShutdown = change.inControlledShutdown > 0
+		}
+		if change.logDirs != nil {
+			broker.logDirs = change.logDirs
+		}
+	}
+}
+
+// copyTopic replaces a topic of the image with a copy that can be changed.
+func (image *MetadataImage) copyTopic(topicId uuid.UUID) *Topic {
+	topic, ok := image.topicsById[topicId]
+	if !ok {
+		return nil
+	}
+
+	copied := *topic
+	copied.partitions = make([]*Partition, len(topic.partitions))
+	for i, partition := range topic.partitions {
+		copiedPartition := *partition
+		copied.partitions[i] = &copiedPartition
+	}
+
+	image.topics[slices.Index(image.topics, topic)] = &copied
+	image.topicsByName[copied.name] = &copied
+	image.topicsById[copied.topicId] = &copied
+	return &copied
+}
+
+// copyBroker replaces a broker of the image with a copy that can be changed.
+func (image *MetadataImage) copyBroker(brokerId int32) *BrokerRegistration {
+	broker, ok := image.brokers[brokerId]
+	if !ok {
+		return nil
+	}
+	copied := *broker
+	image.brokers[brokerId] = &copied
+	return &copied
+}
+
+// applyPartitionChange updates a partition with the fields a
+// PartitionChangeRecord changes. A new leader starts a new leader epoch.
+func applyPartitionChange(topic *Topic, change *PartitionChangeRecord) {
+	for _, partition := range topic.partitions {
+		if partition.partitionIndex != change.partitionId {
+			continue
+		}
+		if change.isr != nil {
+			partition.isrNodes = change.isr
+		}
+		if change.leader != noLeaderChange {
+			partition.leaderId = change.leader
+			partition.leaderEpoch++
+		}
+		if change.replicas != nil {
+			partition.replicaNodes = change.replicas
+		}
+		if change.eligibleLeaderReplicas != nil {
+			partition.eligibleLeaderReplicas = change.eligibleLeaderReplicas
+		}
+		if change.lastKnownElr != nil {
+			partition.lastKnownElr = change.lastKnownElr
+		}
+		return
+	}
+}
+
+func nonNilInt32s(array []int32) []int32 {
+	if array == nil {
+		return []int32{}
+	}
+	return array
+}
+
+// loadMetadataImage builds the first image from the whole metadata log.
+func loadMetadataImage() error {
+	if err := updateMetadataImage(); err != nil {
+		return err
+	}
+	image := getMetadataImage()
+	fmt.Printf("Loaded metadata image up to offset %d with %d topics\n", lastAppliedMetadataOffset(), len(image.topics))
+	return nil
+}
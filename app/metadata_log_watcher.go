@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// startMetadataLogWatcher tails the metadata log once every
+// metadata.log.watch.interval.ms, so batches written by a KRaft controller or
+// by tooling are served without a restart.
+func startMetadataLogWatcher() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(serverConfig.metadataLogWatchIntervalMs) * time.Millisecond)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := watchMetadataLog(); err != nil {
+				fmt.Printf("Error while watching the cluster metadata log. %s\n", err)
+			}
+		}
+	}()
+}
+
+func watchMetadataLog() error {
+	metadataLog, err := getPartitionLog("__cluster_metadata", 0)
+	if err != nil {
+		return err
+	}
+	if _, err := metadataLog.refresh(); err != nil {
+		return err
+	}
+
+	// also catches up after an earlier replay failed
+	_, nextOffset := metadataLog.offsets()
+	if getMetadataImage().nextOffset >= nextOffset {
+		return nil
+	}
+	if err := updateMetadataImage(); err != nil {
+		return err
+	}
+	fmt.Printf("Applied cluster metadata up to offset %d\n", lastAppliedMetadataOffset())
+	return nil
+}
+
+// lastAppliedMetadataOffset returns the offset of the last metadata record
+// the served metadata includes, -1 before any.
+func lastAppliedMetadataOffset() int64 {
+	return getMetadataImage().nextOffset - 1
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+	"github.com/google/uuid"
+)
+
+// Decoders for the KRaft metadata records other than the topic, partition,
+// config and feature records. Every record is flexible, fields added in later
+// versions are only read for those versions and optional fields are tagged.
+// Record types this broker doesn't know are kept undecoded.
+
+type BrokerEndpoint struct {
+	name             string
+	host             string
+	port             uint16
+	securityProtocol int16
+}
+
+type BrokerFeature struct {
+	name                string
+	minSupportedVersion int16
+	maxSupportedVersion int16
+}
+
+type RegisterBrokerRecord struct {
+	frameVersion         uint8
+	recordType           uint8
+	version              uint8
+	brokerId             int32
+	isMigratingZkBroker  bool
+	incarnationId        uuid.UUID
+	brokerEpoch          int64
+	endPoints            []BrokerEndpoint
+	features             []BrokerFeature
+	rack                 string
+	fenced               bool
+	inControlledShutdown bool
+	logDirs              []uuid.UUID
+}
+
+type UnregisterBrokerRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	brokerId     int32
+	brokerEpoch  int64
+}
+
+// PartitionChangeRecord only carries what changed, the other fields are
+// left at their "no change" values: nil arrays, leader -2 and
+// leaderRecoveryState -1.
+type PartitionChangeRecord struct {
+	frameVersion           uint8
+	recordType             uint8
+	version                uint8
+	partitionId            int32
+	topicId                uuid.UUID
+	isr                    []int32
+	leader                 int32
+	replicas               []int32
+	removingReplicas       []int32
+	addingReplicas         []int32
+	leaderRecoveryState    int8
+	eligibleLeaderReplicas []int32
+	lastKnownElr           []int32
+	directories            []uuid.UUID
+}
+
+// leader of a PartitionChangeRecord that doesn't change the leader
+const noLeaderChange int32 = -2
+
+type AccessControlEntryRecord struct {
+	frameVersion   uint8
+	recordType     uint8
+	version        uint8
+	id             uuid.UUID
+	resourceType   int8
+	resourceName   string
+	patternType    int8
+	principal      string
+	host           string
+	operation      int8
+	permissionType int8
+}
+
+type RemoveAccessControlEntryRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	id           uuid.UUID
+}
+
+// FenceBrokerRecord is used for both fencing and unfencing, the recordType
+// tells them apart.
+type FenceBrokerRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	id           int32
+	epoch        int64
+}
+
+type ClientQuotaEntity struct {
+	entityType string
+	// empty for the default entity
+	entityName string
+}
+
+type ClientQuotaRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	entity       []ClientQuotaEntity
+	key          string
+	value        float64
+	remove       bool
+}
+
+type ProducerIdsRecord struct {
+	frameVersion   uint8
+	recordType     uint8
+	version        uint8
+	brokerId       int32
+	brokerEpoch    int64
+	nextProducerId int64
+}
+
+type BrokerRegistrationChangeRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	brokerId     int32
+	brokerEpoch  int64
+	// 0 is no change, 1 fenced and -1 unfenced
+	fenced int8
+	// 0 is no change, 1 in controlled shutdown
+	inControlledShutdown int8
+	logDirs              []uuid.UUID
+}
+
+// TransactionRecord is a BeginTransactionRecord, EndTransactionRecord or
+// AbortTransactionRecord. The records between a begin and an end marker are
+// applied together, those before an abort marker are dropped.
+type TransactionRecord struct {
+	frameVersion uint8
+	recordType   uint8
+	version      uint8
+	// name of a begun transaction, reason of an aborted one
+	name string
+}
+
+// readNullableInt32Array reads a COMPACT_ARRAY of int32, null as nil.
+func readNullableInt32Array(decoder *protocol.Decoder) []int32 {
+	length := decoder.ReadNullableArrayLength()
+	if length < 0 {
+		return nil
+	}
+	array := make([]int32, 0, length)
+	for i := 0; i < length && decoder.Err() == nil; i++ {
+		array = append(array, decoder.ReadInt32())
+	}
+	return array
+}
+
+// readUuidArray reads a COMPACT_ARRAY of uuid, null as nil.
+func readUuidArray(decoder *protocol.Decoder) []uuid.UUID {
+	length := decoder.ReadNullableArrayLength()
+	if length < 0 {
+		return nil
+	}
+	array := make([]uuid.UUID, 0, length)
+	for i := 0; i < length && decoder.Err() == nil; i++ {
+		array = append(array, decoder.ReadUuid())
+	}
+	return array
+}
+
+// readTaggedInt32Array reads an int32 array held in a tagged field.
+func readTaggedInt32Array(value []byte) ([]int32, error) {
+	decoder := protocol.NewDecoder(value, true)
+	array := readNullableInt32Array(decoder)
+	return array, decoder.Err()
+}
+
+func decodeRegisterBrokerRecord(decoder *protocol.Decoder, header *Record) RegisterBrokerRecord {
+	record := RegisterBrokerRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version, fenced: true}
+	record.brokerId = decoder.ReadInt32()
+	if record.version >= 2 {
+		record.isMigratingZkBroker = decoder.ReadBool()
+	}
+	record.incarnationId = decoder.ReadUuid()
+	record.brokerEpoch = decoder.ReadInt64()
+
+	endPointsLength := decoder.ReadArrayLength()
+	for i := 0; i < endPointsLength && decoder.Err() == nil; i++ {
+		endPoint := BrokerEndpoint{}
+		endPoint.name = decoder.ReadString()
+		endPoint.host = decoder.ReadString()
+		endPoint.port = decoder.ReadUint16()
+		endPoint.securityProtocol = decoder.ReadInt16()
+		decoder.ReadTaggedFields()
+		record.endPoints = append(record.endPoints, endPoint)
+	}
+
+	featuresLength := decoder.ReadArrayLength()
+	for i := 0; i < featuresLength && decoder.Err() == nil; i++ {
+		feature := BrokerFeature{}
+		feature.name = decoder.ReadString()
+		feature.minSupportedVersion = decoder.ReadInt16()
+		feature.maxSupportedVersion = decoder.ReadInt16()
+		decoder.ReadTaggedFields()
+		record.features = append(record.features, feature)
+	}
+
+	record.rack, _ = decoder.ReadNullableString()
+	record.fenced = decoder.ReadBool()
+	if record.version >= 1 {
+		record.inControlledShutdown = decoder.ReadBool()
+	}
+	if record.version >= 3 {
+		record.logDirs = readUuidArray(decoder)
+	}
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeUnregisterBrokerRecord(decoder *protocol.Decoder, header *Record) UnregisterBrokerRecord {
+	record := UnregisterBrokerRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.brokerId = decoder.ReadInt32()
+	record.brokerEpoch = decoder.ReadInt64()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodePartitionChangeRecord(decoder *protocol.Decoder, header *Record) (PartitionChangeRecord, error) {
+	record := PartitionChangeRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version, leader: noLeaderChange, leaderRecoveryState: -1}
+	record.partitionId = decoder.ReadInt32()
+	record.topicId = decoder.ReadUuid()
+
+	// everything else is tagged
+	for tag, value := range decoder.ReadTaggedFields() {
+		tagDecoder := protocol.NewDecoder(value, true)
+		switch tag {
+		case 0:
+			record.isr = readNullableInt32Array(tagDecoder)
+		case 1:
+			record.leader = tagDecoder.ReadInt32()
+		case 2:
+			record.replicas = readNullableInt32Array(tagDecoder)
+		case 3:
+			record.removingReplicas = readNullableInt32Array(tagDecoder)
+		case 4:
+			record.addingReplicas = readNullableInt32Array(tagDecoder)
+		case 5:
+			record.leaderRecoveryState = tagDecoder.ReadInt8()
+		case 6:
+			if record.version >= 2 {
+				record.eligibleLeaderReplicas = readNullableInt32Array(tagDecoder)
+			}
+		case 7:
+			if record.version >= 2 {
+				record.lastKnownElr = readNullableInt32Array(tagDecoder)
+			}
+		case 8:
+			if record.version >= 1 {
+				record.directories = readUuidArray(tagDecoder)
+			}
+		}
+		if err := tagDecoder.Err(); err != nil {
+			return record, fmt.Errorf("tagged field %d: %w", tag, err)
+		}
+	}
+	return record, nil
+}
+
+func decodeAccessControlEntryRecord(decoder *protocol.Decoder, header *Record) AccessControlEntryRecord {
+	record := AccessControlEntryRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.id = decoder.ReadUuid()
+	record.resourceType = decoder.ReadInt8()
+	record.resourceName = decoder.ReadString()
+	record.patternType = decoder.ReadInt8()
+	record.principal = decoder.ReadString()
+	record.host = decoder.ReadString()
+	record.operation = decoder.ReadInt8()
+	record.permissionType = decoder.ReadInt8()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeRemoveAccessControlEntryRecord(decoder *protocol.Decoder, header *Record) RemoveAccessControlEntryRecord {
+	record := RemoveAccessControlEntryRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.id = decoder.ReadUuid()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeFenceBrokerRecord(decoder *protocol.Decoder, header *Record) FenceBrokerRecord {
+	record := FenceBrokerRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.id = decoder.ReadInt32()
+	record.epoch = decoder.ReadInt64()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeClientQuotaRecord(decoder *protocol.Decoder, header *Record) ClientQuotaRecord {
+	record := ClientQuotaRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	entityLength := decoder.ReadArrayLength()
+	for i := 0; i < entityLength && decoder.Err() == nil; i++ {
+		entity := ClientQuotaEntity{}
+		entity.entityType = decoder.ReadString()
+		entity.entityName, _ = decoder.ReadNullableString()
+		decoder.ReadTaggedFields()
+		record.entity = append(record.entity, entity)
+	}
+	record.key = decoder.ReadString()
+	record.value = decoder.ReadFloat64()
+	record.remove = decoder.ReadBool()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeProducerIdsRecord(decoder *protocol.Decoder, header *Record) ProducerIdsRecord {
+	record := ProducerIdsRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.brokerId = decoder.ReadInt32()
+	record.brokerEpoch = decoder.ReadInt64()
+	record.nextProducerId = decoder.ReadInt64()
+	decoder.ReadTaggedFields()
+	return record
+}
+
+func decodeBrokerRegistrationChangeRecord(decoder *protocol.Decoder, header *Record) (BrokerRegistrationChangeRecord, error) {
+	record := BrokerRegistrationChangeRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	record.brokerId = decoder.ReadInt32()
+	record.brokerEpoch = decoder.ReadInt64()
+	for tag, value := range decoder.ReadTaggedFields() {
+		tagDecoder := protocol.NewDecoder(value, true)
+		switch tag {
+		case 0:
+			record.fenced = tagDecoder.ReadInt8()
+		case 1:
+			if record.version >= 1 {
+				record.inControlledShutdown = tagDecoder.ReadInt8()
+			}
+		case 2:
+			if record.version >= 2 {
+				record.logDirs = readUuidArray(tagDecoder)
+			}
+		}
+		if err := tagDecoder.Err(); err != nil {
+			return record, fmt.Errorf("tagged field %d: %w", tag, err)
+		}
+	}
+	return record, nil
+}
+
+// decodeTransactionRecord reads the begin, end and abort markers, the name
+// and reason are tagged.
+func decodeTransactionRecord(decoder *protocol.Decoder, header *Record) (TransactionRecord, error) {
+	record := TransactionRecord{frameVersion: header.frameVersion, recordType: header.recordType, version: header.version}
+	if value, ok := decoder.ReadTaggedFields()[0]; ok {
+		tagDecoder := protocol.NewDecoder(value, true)
+		record.name, _ = tagDecoder.ReadNullableString()
+		return record, tagDecoder.Err()
+	}
+	return record, nil
+}
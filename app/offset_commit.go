@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// OffsetCommit
+
+type OffsetCommitRequest struct {
+	RequestHeader
+	protocol.OffsetCommitRequest
+}
+
+func (request *OffsetCommitRequest) parse(decoder *protocol.Decoder) error {
+	return request.OffsetCommitRequest.Decode(decoder, request.apiVersion)
+}
+
+func (request *OffsetCommitRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	offsetCommitResponse := protocol.OffsetCommitResponse{}
+	offsetCommitResponse.Default()
+
+	groupErrorCode := errorCodeNone
+	if request.GroupId == "" {
+		groupErrorCode = errorCodeInvalidGroupId
+	} else {
+		group := getConsumerGroup(request.GroupId, true)
+		groupErrorCode = group.validateOffsetCommit(request.MemberId, request.GroupInstanceId, request.GenerationIdOrMemberEpoch)
+	}
+
+	clusterTopics := getClusterTopics()
+	now := time.Now().UnixMilli()
+	offsets := map[TopicPartition]*CommittedOffset{}
+	committedPartitions := []*protocol.OffsetCommitResponsePartition{}
+
+	for _, topic := range request.Topics {
+		topicResponse := &protocol.OffsetCommitResponseTopic{Name: topic.Name}
+		clusterTopic := findTopicByName(clusterTopics, topic.Name)
+
+		for _, partition := range topic.Partitions {
+			partitionResponse := &protocol.OffsetCommitResponsePartition{PartitionIndex: partition.PartitionIndex, ErrorCode: groupErrorCode}
+			topicResponse.Partitions = append(topicResponse.Partitions, partitionResponse)
+
+			if groupErrorCode != errorCodeNone {
+				continue
+			}
+			if clusterTopic == nil || !topicHasPartition(clusterTopic, partition.PartitionIndex) {
+				partitionResponse.ErrorCode = errorCodeUnknownTopicOrPartition
+				continue
+			}
+			if int32(len(partition.CommittedMetadata)) > serverConfig.offsetMetadataMaxBytes {
+				partitionResponse.ErrorCode = errorCodeOffsetMetadataTooLarge
+				continue
+			}
+
+			commitTimestamp := partition.CommitTimestamp
+			if commitTimestamp < 0 {
+				commitTimestamp = now
+			}
+			offsets[TopicPartition{topic: topic.Name, partition: partition.PartitionIndex}] = &CommittedOffset{
+				offset:          partition.CommittedOffset,
+				leaderEpoch:     partition.CommittedLeaderEpoch,
+				metadata:        partition.CommittedMetadata,
+				commitTimestamp: commitTimestamp,
+			}
+			committedPartitions = append(committedPartitions, partitionResponse)
+		}
+
+		offsetCommitResponse.Topics = append(offsetCommitResponse.Topics, topicResponse)
+	}
+
+	if err := commitOffsets(request.GroupId, offsets); err != nil {
+		fmt.Printf("Error while committing offsets of group %s. %s\n", request.GroupId, err)
+		for _, partitionResponse := range committedPartitions {
+			partitionResponse.ErrorCode = errorCodeCoordinatorNotAvailable
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(offsetCommitResponse.IsFlexible(request.apiVersion))
+	offsetCommitResponse.Encode(commonResponse.body, request.apiVersion)
+}
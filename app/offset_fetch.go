@@ -0,0 +1,97 @@
+package main
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// OffsetFetch
+
+type OffsetFetchRequest struct {
+	RequestHeader
+	protocol.OffsetFetchRequest
+}
+
+func (request *OffsetFetchRequest) parse(decoder *protocol.Decoder) error {
+	return request.OffsetFetchRequest.Decode(decoder, request.apiVersion)
+}
+
+// fetchGroupOffsets looks up the requested partitions, or every committed
+// partition when topics is nil. Partitions without a commit get offset -1.
+func fetchGroupOffsets(groupId string, topics []*protocol.OffsetFetchRequestTopics) *protocol.OffsetFetchResponseGroup {
+	groupResponse := &protocol.OffsetFetchResponseGroup{GroupId: groupId}
+	offsets := getCommittedOffsets(groupId)
+
+	if topics == nil {
+		partitionIndexes := map[string][]int32{}
+		for topicPartition := range offsets {
+			partitionIndexes[topicPartition.topic] = append(partitionIndexes[topicPartition.topic], topicPartition.partition)
+		}
+		for _, name := range slices.Sorted(maps.Keys(partitionIndexes)) {
+			slices.Sort(partitionIndexes[name])
+			topics = append(topics, &protocol.OffsetFetchRequestTopics{Name: name, PartitionIndexes: partitionIndexes[name]})
+		}
+	}
+
+	for _, topic := range topics {
+		topicResponse := &protocol.OffsetFetchResponseTopics{Name: topic.Name}
+		for _, partitionIndex := range topic.PartitionIndexes {
+			partitionResponse := &protocol.OffsetFetchResponsePartitions{
+				PartitionIndex:       partitionIndex,
+				CommittedOffset:      -1,
+				CommittedLeaderEpoch: -1,
+			}
+			if committedOffset, ok := offsets[TopicPartition{topic: topic.Name, partition: partitionIndex}]; ok {
+				partitionResponse.CommittedOffset = committedOffset.offset
+				partitionResponse.CommittedLeaderEpoch = committedOffset.leaderEpoch
+				partitionResponse.Metadata = committedOffset.metadata
+			}
+			topicResponse.Partitions = append(topicResponse.Partitions, partitionResponse)
+		}
+		groupResponse.Topics = append(groupResponse.Topics, topicResponse)
+	}
+	return groupResponse
+}
+
+func (request *OffsetFetchRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	offsetFetchResponse := protocol.OffsetFetchResponse{}
+	offsetFetchResponse.Default()
+
+	// v0-v7 fetch a single group, v8+ a batch of them
+	if request.apiVersion < 8 {
+		var topics []*protocol.OffsetFetchRequestTopics
+		if request.Topics != nil {
+			topics = []*protocol.OffsetFetchRequestTopics{}
+		}
+		for _, topic := range request.Topics {
+			topics = append(topics, &protocol.OffsetFetchRequestTopics{Name: topic.Name, PartitionIndexes: topic.PartitionIndexes})
+		}
+
+		groupResponse := fetchGroupOffsets(request.GroupId, topics)
+		offsetFetchResponse.ErrorCode = groupResponse.ErrorCode
+		for _, topic := range groupResponse.Topics {
+			topicResponse := &protocol.OffsetFetchResponseTopic{Name: topic.Name}
+			for _, partition := range topic.Partitions {
+				topicResponse.Partitions = append(topicResponse.Partitions, &protocol.OffsetFetchResponsePartition{
+					PartitionIndex:       partition.PartitionIndex,
+					CommittedOffset:      partition.CommittedOffset,
+					CommittedLeaderEpoch: partition.CommittedLeaderEpoch,
+					Metadata:             partition.Metadata,
+					ErrorCode:            partition.ErrorCode,
+				})
+			}
+			offsetFetchResponse.Topics = append(offsetFetchResponse.Topics, topicResponse)
+		}
+	} else {
+		for _, group := range request.Groups {
+			offsetFetchResponse.Groups = append(offsetFetchResponse.Groups, fetchGroupOffsets(group.GroupId, group.Topics))
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(offsetFetchResponse.IsFlexible(request.apiVersion))
+	offsetFetchResponse.Encode(commonResponse.body, request.apiVersion)
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 )
 
 // func parseHeader(buffer []byte) Request {
@@ -63,50 +67,99 @@ import (
 // 	encodeHexRequest(request)
 // }
 
-func (response *Response) bytes(buffer *bytes.Buffer, useVersion2 bool) {
-	message := &bytes.Buffer{}
-	binary.Write(message, binary.BigEndian, response.correlationId)
-	if useVersion2 {
-		addTagField(message)
+func (response *Response) bytes(buffer *bytes.Buffer, headerVersion int16) error {
+	if err := response.body.Err(); err != nil {
+		return err
 	}
-	binary.Write(message, binary.BigEndian, response.BytesData.Bytes())
+
+	// v1 adds the tagged fields
+	message := protocol.NewEncoder(headerVersion >= 1)
+	message.WriteInt32(response.correlationId)
+	message.WriteTaggedFields(nil)
+	message.WriteRaw(response.body.Bytes())
 	response.messageSize = int32(message.Len())
 
-	binary.Write(buffer, binary.BigEndian, response.messageSize)
-	binary.Write(buffer, binary.BigEndian, message.Bytes())
+	frame := protocol.NewEncoder(false)
+	frame.WriteInt32(response.messageSize)
+	frame.WriteRaw(message.Bytes())
+	buffer.Write(frame.Bytes())
+	return nil
 }
 
-func parseRequest(buffer *bytes.Buffer) (RequestInterface, error) {
+// readRequestFrame reads one length-prefixed request off the connection. The
+// returned frame still starts with the 4 byte messageSize so parseRequest can
+// read the header the same way it always has.
+func readRequestFrame(reader *bufio.Reader, maxRequestSize int32) ([]byte, error) {
+	sizeBytes := make([]byte, 4)
+	if _, err := io.ReadFull(reader, sizeBytes); err != nil {
+		return nil, err
+	}
+
+	messageSize := int32(binary.BigEndian.Uint32(sizeBytes))
+	// apiKey, apiVersion and correlationId are always present
+	if messageSize < 8 {
+		return nil, fmt.Errorf("invalid request size %d", messageSize)
+	}
+	if messageSize > maxRequestSize {
+		return nil, fmt.Errorf("request size %d is larger than the maximum of %d", messageSize, maxRequestSize)
+	}
+
+	frame := make([]byte, 4+int(messageSize))
+	copy(frame, sizeBytes)
+	if _, err := io.ReadFull(reader, frame[4:]); err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+func parseRequest(frame []byte) (RequestInterface, error) {
+	decoder := protocol.NewDecoder(frame, false)
 	header := RequestHeader{}
-	binary.Read(buffer, binary.BigEndian, &header.messageSize)
-	binary.Read(buffer, binary.BigEndian, &header.apiKey)
-	binary.Read(buffer, binary.BigEndian, &header.apiVersion)
-	binary.Read(buffer, binary.BigEndian, &header.correlationId)
-	header.clientId = readNullableString(buffer)
-	ignoreTagField(buffer)
-
-	switch header.apiKey {
-	case 1:
-		request := FetchRequest{RequestHeader: header}
-		request.parse(buffer)
-		return &request, nil
-	case 18:
-		request := ApiVersionsRequest{RequestHeader: header}
-		request.parse(buffer)
-		return &request, nil
-	case 75:
-		request := DescribePartitionsRequest{RequestHeader: header}
-		request.parse(buffer)
-		return &request, nil
-	default:
+	header.messageSize = decoder.ReadInt32()
+	header.apiKey = decoder.ReadInt16()
+	header.apiVersion = decoder.ReadInt16()
+	header.correlationId = decoder.ReadInt32()
+	headerVersion := requestHeaderVersion(header.apiKey, header.apiVersion)
+	// clientId stays a NULLABLE_STRING in flexible headers
+	if headerVersion >= 1 {
+		header.clientId, _ = decoder.ReadNullableString()
+	}
+	if headerVersion >= 2 {
+		decoder.Flexible = true
+		header.taggedFields = decoder.ReadTaggedFields()
+	}
+	if err := decoder.Err(); err != nil {
+		return nil, fmt.Errorf("reading request header: %w", err)
+	}
+
+	spec, ok := apiSpecs[header.apiKey]
+	if !ok {
 		err := fmt.Errorf("%d ApiKey is not Supported", header.apiKey)
 		return nil, err
 	}
+	// like kafka, other versions close the connection, but ApiVersions
+	// answers them so that clients can pick a version
+	if header.apiKey != 18 && !spec.supportsVersion(header.apiVersion) {
+		return nil, fmt.Errorf("%s v%d is not supported", spec.name, header.apiVersion)
+	}
+	request := spec.newRequest(header)
 
+	if err := request.parse(decoder); err != nil {
+		return nil, fmt.Errorf("reading %T: %w", request, err)
+	}
+	return request, nil
 }
 
 func processAndGenerateResponse(request RequestInterface) (ResponseInterface, error) {
 	switch request := request.(type) {
+	case *ProduceRequest:
+		response := Response{}
+		if !request.generateResponse(&response) {
+			// acks=0, nothing is sent back
+			return nil, nil
+		}
+		return &response, nil
 	case *ApiVersionsRequest:
 		response := Response{}
 		request.generateResponse(&response)
@@ -119,6 +172,54 @@ func processAndGenerateResponse(request RequestInterface) (ResponseInterface, er
 		response := Response{}
 		request.generateResponse(&response)
 		return &response, nil
+	case *ListOffsetsRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *MetadataRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *OffsetCommitRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *OffsetFetchRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *CreateTopicsRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *DeleteTopicsRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *CreatePartitionsRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *FindCoordinatorRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *JoinGroupRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *HeartbeatRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *LeaveGroupRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
+	case *SyncGroupRequest:
+		response := Response{}
+		request.generateResponse(&response)
+		return &response, nil
 	default:
 		err := errors.New("Request type is not supported")
 		return nil, err
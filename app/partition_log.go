@@ -0,0 +1,502 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordBatch v2 header layout, see https://kafka.apache.org/documentation/#recordbatch
+const (
+	recordBatchBaseOffsetPosition      = 0
+	recordBatchLengthPosition          = 8
+	recordBatchMagicPosition           = 16
+	recordBatchCrcPosition             = 17
+	recordBatchAttributesPosition      = 21
+	recordBatchLastOffsetDeltaPosition = 23
+	recordBatchMaxTimestampPosition    = 35
+	// baseOffset + batchLength, batchLength counts everything after these
+	recordBatchLogOverhead = 12
+	// size of a RecordBatch v2 header up to and including the records count
+	recordBatchHeaderSize = 61
+)
+
+type PartitionLog struct {
+	mu             sync.RWMutex
+	topicName      string
+	partitionIndex int32
+	dir            string
+	logStartOffset int64
+	nextOffset     int64
+	// sorted by base offset, the last one is the active segment appends go to
+	segments []*LogSegment
+	// where the log cleaner stopped last time, and whether it kept tombstones
+	cleanerCheckpoint int64
+	hasTombstones     bool
+	// everything below it is synced to disk
+	recoveryPoint int64
+	// written by another process too, the KRaft controller writing the
+	// metadata log beside us, so every segment is opened watched
+	watched bool
+}
+
+// suffix of the directories of deleted partitions
+const deletedDirSuffix = "-delete"
+
+var (
+	partitionLogs     = map[string]*PartitionLog{}
+	partitionLogsLock sync.Mutex
+)
+
+// getPartitionLog returns the open log for a partition, loading it from disk
+// the first time it is asked for.
+func getPartitionLog(topicName string, partitionIndex int32) (*PartitionLog, error) {
+	partitionLogsLock.Lock()
+	defer partitionLogsLock.Unlock()
+
+	partitionName := fmt.Sprintf("%s-%d", topicName, partitionIndex)
+	if partitionLog, ok := partitionLogs[partitionName]; ok {
+		return partitionLog, nil
+	}
+
+	partitionLog := &PartitionLog{
+		topicName:      topicName,
+		partitionIndex: partitionIndex,
+		dir:            fmt.Sprintf("%s/%s", serverConfig.logDir, partitionName),
+		watched:        topicName == "__cluster_metadata",
+	}
+	if err := partitionLog.load(); err != nil {
+		return nil, err
+	}
+
+	partitionLogs[partitionName] = partitionLog
+	return partitionLog, nil
+}
+
+// openPartitionLogs returns every partition log loaded so far.
+func openPartitionLogs() []*PartitionLog {
+	partitionLogsLock.Lock()
+	defer partitionLogsLock.Unlock()
+
+	logs := make([]*PartitionLog, 0, len(partitionLogs))
+	for _, partitionLog := range partitionLogs {
+		logs = append(logs, partitionLog)
+	}
+	return logs
+}
+
+// deletePartitionLog drops the log of a partition. Its directory is renamed
+// to <topic>-<partition>.<id>-delete the way kafka does it, so a topic of the
+// same name can be created right away, and removed in the background.
+func deletePartitionLog(topicName string, partitionIndex int32) error {
+	partitionLogsLock.Lock()
+	defer partitionLogsLock.Unlock()
+
+	partitionName := fmt.Sprintf("%s-%d", topicName, partitionIndex)
+	dir := fmt.Sprintf("%s/%s", serverConfig.logDir, partitionName)
+	if partitionLog, ok := partitionLogs[partitionName]; ok {
+		// wait for appends and cleaning in progress
+		partitionLog.mu.Lock()
+		defer partitionLog.mu.Unlock()
+		delete(partitionLogs, partitionName)
+	}
+
+	deletedDir := fmt.Sprintf("%s.%s%s", dir, strings.ReplaceAll(uuid.NewString(), "-", ""), deletedDirSuffix)
+	if err := os.Rename(dir, deletedDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	go removeDeletedDir(deletedDir)
+	return nil
+}
+
+func removeDeletedDir(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		fmt.Printf("Error while removing deleted partition directory %s. %s\n", dir, err)
+	}
+}
+
+// loadPartitionLogs opens every partition directory in the log dir, so their
+// indexes are checked, and rebuilt if needed, at startup instead of on first use.
+func loadPartitionLogs() error {
+	entries, err := os.ReadDir(serverConfig.logDir)
+	if err != nil {
+		return err
+	}
+
+	// after a crash the logs are checked from their recovery point on
+	cleanShutdown := hadCleanShutdown()
+	recoveryPoints := map[string]int64{}
+	if !cleanShutdown {
+		fmt.Printf("Recovering partition logs, the last shutdown was not clean\n")
+		recoveryPoints, err = readRecoveryPoints()
+		if err != nil {
+			fmt.Printf("Error while reading recovery points, recovering logs from the start. %s\n", err)
+			recoveryPoints = map[string]int64{}
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasSuffix(entry.Name(), deletedDirSuffix) {
+			// the broker stopped before a deleted partition was removed
+			go removeDeletedDir(fmt.Sprintf("%s/%s", serverConfig.logDir, entry.Name()))
+			continue
+		}
+
+		separator := strings.LastIndex(entry.Name(), "-")
+		if !entry.IsDir() || separator <= 0 {
+			continue
+		}
+		partitionIndex, err := strconv.ParseInt(entry.Name()[separator+1:], 10, 32)
+		if err != nil {
+			continue
+		}
+
+		partitionLog, err := getPartitionLog(entry.Name()[:separator], int32(partitionIndex))
+		if err != nil {
+			fmt.Printf("Error while loading partition log %s. %s\n", entry.Name(), err)
+			continue
+		}
+		if cleanShutdown {
+			partitionLog.recoveryPoint = partitionLog.nextOffset
+		} else if err := partitionLog.recover(recoveryPoints[entry.Name()]); err != nil {
+			fmt.Printf("Error while recovering partition log %s. %s\n", entry.Name(), err)
+		}
+	}
+	return removeCleanShutdownMarker()
+}
+
+// load opens every segment of the partition, rebuilding their indexes if needed.
+func (partitionLog *PartitionLog) load() error {
+	if err := os.MkdirAll(partitionLog.dir, 0o755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(partitionLog.dir)
+	if err != nil {
+		return err
+	}
+
+	baseOffsets := []int64{}
+	for _, entry := range entries {
+		// left behind by a compaction that didn't finish
+		if strings.HasSuffix(entry.Name(), ".cleaned") && !partitionLog.watched {
+			os.Remove(fmt.Sprintf("%s/%s", partitionLog.dir, entry.Name()))
+			continue
+		}
+
+		name, found := strings.CutSuffix(entry.Name(), ".log")
+		if !found {
+			continue
+		}
+		baseOffset, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		baseOffsets = append(baseOffsets, baseOffset)
+	}
+	if len(baseOffsets) == 0 {
+		baseOffsets = append(baseOffsets, 0)
+	}
+	slices.Sort(baseOffsets)
+
+	for _, baseOffset := range baseOffsets {
+		segment, err := partitionLog.openSegment(baseOffset)
+		if err != nil {
+			return err
+		}
+		partitionLog.segments = append(partitionLog.segments, segment)
+	}
+
+	partitionLog.logStartOffset = partitionLog.segments[0].baseOffset
+	partitionLog.nextOffset = partitionLog.activeSegment().nextOffset
+	return nil
+}
+
+// openSegment opens a segment of the log, without ever truncating it or
+// writing its indexes if the log is watched.
+func (partitionLog *PartitionLog) openSegment(baseOffset int64) (*LogSegment, error) {
+	if partitionLog.watched {
+		return openWatchedLogSegment(partitionLog.dir, baseOffset)
+	}
+	return openLogSegment(partitionLog.dir, baseOffset)
+}
+
+func (partitionLog *PartitionLog) activeSegment() *LogSegment {
+	return partitionLog.segments[len(partitionLog.segments)-1]
+}
+
+// maybeRoll starts a new segment when appending recordsSize bytes would make
+// the active one larger than log.segment.bytes, or when it is older than
+// log.roll.ms. Empty segments are never rolled.
+func (partitionLog *PartitionLog) maybeRoll(recordsSize int) error {
+	segment := partitionLog.activeSegment()
+	if segment.size == 0 {
+		return nil
+	}
+
+	tooLarge := segment.size+int64(recordsSize) > serverConfig.logSegmentBytes
+	tooOld := segment.firstBatchTimestamp >= 0 && time.Now().UnixMilli()-segment.firstBatchTimestamp > serverConfig.logRollMs
+	if !tooLarge && !tooOld {
+		return nil
+	}
+
+	newSegment, err := partitionLog.openSegment(partitionLog.nextOffset)
+	if err != nil {
+		return err
+	}
+	partitionLog.segments = append(partitionLog.segments, newSegment)
+	return nil
+}
+
+// segmentIndexFor returns the index of the segment that holds offset, which is
+// the last one with a base offset at or below it.
+func (partitionLog *PartitionLog) segmentIndexFor(offset int64) int {
+	i := sort.Search(len(partitionLog.segments), func(i int) bool {
+		return partitionLog.segments[i].baseOffset > offset
+	})
+	return max(i-1, 0)
+}
+
+// append assigns offsets to the given record batches and writes them to the
+// end of the log. It returns the offset of the first record appended.
+func (partitionLog *PartitionLog) append(records []byte) (int64, error) {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	baseOffset := partitionLog.nextOffset
+	nextOffset := baseOffset
+
+	// baseOffset is not covered by the batch crc, so it can be rewritten in place
+	for position := 0; position < len(records); {
+		batch := records[position:]
+		binary.BigEndian.PutUint64(batch[recordBatchBaseOffsetPosition:], uint64(nextOffset))
+		nextOffset += int64(binary.BigEndian.Uint32(batch[recordBatchLastOffsetDeltaPosition:])) + 1
+		position += recordBatchLogOverhead + int(binary.BigEndian.Uint32(batch[recordBatchLengthPosition:]))
+	}
+
+	if err := partitionLog.maybeRoll(len(records)); err != nil {
+		return 0, err
+	}
+	if err := partitionLog.activeSegment().append(records); err != nil {
+		return 0, err
+	}
+
+	partitionLog.nextOffset = nextOffset
+	return baseOffset, nil
+}
+
+// refresh picks up batches another process appended to the log, like a KRaft
+// controller writing the metadata log beside us, including segments it rolled.
+// It returns whether the log grew.
+func (partitionLog *PartitionLog) refresh() (bool, error) {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	previousNextOffset := partitionLog.nextOffset
+	if err := partitionLog.activeSegment().refresh(); err != nil {
+		return false, err
+	}
+
+	entries, err := os.ReadDir(partitionLog.dir)
+	if err != nil {
+		return false, err
+	}
+	baseOffsets := []int64{}
+	for _, entry := range entries {
+		name, found := strings.CutSuffix(entry.Name(), ".log")
+		if !found {
+			continue
+		}
+		baseOffset, err := strconv.ParseInt(name, 10, 64)
+		if err != nil || baseOffset <= partitionLog.activeSegment().baseOffset {
+			continue
+		}
+		baseOffsets = append(baseOffsets, baseOffset)
+	}
+	slices.Sort(baseOffsets)
+
+	for _, baseOffset := range baseOffsets {
+		segment, err := openWatchedLogSegment(partitionLog.dir, baseOffset)
+		if err != nil {
+			return false, err
+		}
+		partitionLog.segments = append(partitionLog.segments, segment)
+	}
+
+	partitionLog.nextOffset = partitionLog.activeSegment().nextOffset
+	return partitionLog.nextOffset > previousNextOffset, nil
+}
+
+func (partitionLog *PartitionLog) offsets() (int64, int64) {
+	partitionLog.mu.RLock()
+	defer partitionLog.mu.RUnlock()
+	return partitionLog.logStartOffset, partitionLog.nextOffset
+}
+
+// read returns the batch holding fetchOffset and the ones after it, stopping
+// before maxBytes is exceeded. With minOneBatch the first batch is returned
+// even if it is larger than maxBytes, so consumers can always make progress.
+func (partitionLog *PartitionLog) read(fetchOffset int64, maxBytes int, minOneBatch bool) ([]byte, error) {
+	partitionLog.mu.RLock()
+	defer partitionLog.mu.RUnlock()
+
+	records := []byte{}
+	for i := partitionLog.segmentIndexFor(fetchOffset); i < len(partitionLog.segments); i++ {
+		segmentRecords, limitReached, err := partitionLog.segments[i].read(fetchOffset, maxBytes-len(records), minOneBatch && len(records) == 0, partitionLog.nextOffset)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, segmentRecords...)
+		if limitReached {
+			break
+		}
+	}
+	return records, nil
+}
+
+// enforceRetention deletes the oldest segments once they are older than
+// retentionMs, or while the log without them is still at least retentionBytes
+// large. -1 disables either limit. It returns the number of deleted segments.
+func (partitionLog *PartitionLog) enforceRetention(retentionMs int64, retentionBytes int64) (int, error) {
+	partitionLog.mu.Lock()
+	defer partitionLog.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	totalSize := int64(0)
+	for _, segment := range partitionLog.segments {
+		totalSize += segment.size
+	}
+
+	deleteCount := 0
+	for i, segment := range partitionLog.segments {
+		// deleting an empty active segment would only roll another empty one
+		if segment.size == 0 && i == len(partitionLog.segments)-1 {
+			break
+		}
+		expired := retentionMs >= 0 && segment.maxTimestamp >= 0 && now-segment.maxTimestamp > retentionMs
+		oversized := retentionBytes >= 0 && totalSize-segment.size >= retentionBytes
+		if !expired && !oversized {
+			break
+		}
+		totalSize -= segment.size
+		deleteCount++
+	}
+	if deleteCount == 0 {
+		return 0, nil
+	}
+
+	// a log always keeps a segment to append to, roll a new one if the
+	// active segment is going away too
+	if deleteCount == len(partitionLog.segments) {
+		newSegment, err := partitionLog.openSegment(partitionLog.nextOffset)
+		if err != nil {
+			return 0, err
+		}
+		partitionLog.segments = append(partitionLog.segments, newSegment)
+	}
+
+	for _, segment := range partitionLog.segments[:deleteCount] {
+		if err := segment.delete(); err != nil {
+			return 0, err
+		}
+	}
+	partitionLog.segments = partitionLog.segments[deleteCount:]
+	partitionLog.logStartOffset = partitionLog.segments[0].baseOffset
+	return deleteCount, nil
+}
+
+// lastStableOffset is the offset read_committed consumers can read up to.
+// There are no transactions, so nothing below the high watermark is pending.
+func (partitionLog *PartitionLog) lastStableOffset() int64 {
+	_, highWatermark := partitionLog.offsets()
+	return highWatermark
+}
+
+// offsetForTimestamp returns the offset and timestamp of the first record
+// below maxOffset with a timestamp at or after timestamp, or -1 for both if
+// there is none.
+func (partitionLog *PartitionLog) offsetForTimestamp(timestamp int64, maxOffset int64) (int64, int64, error) {
+	partitionLog.mu.RLock()
+	defer partitionLog.mu.RUnlock()
+
+	for _, segment := range partitionLog.segments {
+		if segment.maxTimestamp < timestamp {
+			continue
+		}
+		offset, recordTimestamp, err := segment.offsetForTimestamp(timestamp, maxOffset)
+		if err != nil || offset >= 0 {
+			return offset, recordTimestamp, err
+		}
+	}
+	return -1, -1, nil
+}
+
+// maxTimestampOffset returns the offset and timestamp of the record with the
+// largest timestamp below maxOffset, the first one if several share it.
+func (partitionLog *PartitionLog) maxTimestampOffset(maxOffset int64) (int64, int64, error) {
+	partitionLog.mu.RLock()
+	var maxSegment *LogSegment
+	for _, segment := range partitionLog.segments {
+		if segment.baseOffset < maxOffset && segment.maxTimestamp >= 0 && (maxSegment == nil || segment.maxTimestamp > maxSegment.maxTimestamp) {
+			maxSegment = segment
+		}
+	}
+	partitionLog.mu.RUnlock()
+
+	if maxSegment == nil {
+		return -1, -1, nil
+	}
+	return partitionLog.offsetForTimestamp(maxSegment.maxTimestamp, maxOffset)
+}
+
+// legacyOffsetsBefore answers ListOffsets v0, which returns up to
+// maxNumOffsets segment base offsets, newest first, of the segments written
+// before timestamp. The log end offset counts as one more segment.
+func (partitionLog *PartitionLog) legacyOffsetsBefore(timestamp int64, maxNumOffsets int) []int64 {
+	partitionLog.mu.RLock()
+	defer partitionLog.mu.RUnlock()
+
+	offsets := []int64{}
+	timestamps := []int64{}
+	for _, segment := range partitionLog.segments {
+		offsets = append(offsets, segment.baseOffset)
+		timestamps = append(timestamps, segment.maxTimestamp)
+	}
+	if partitionLog.activeSegment().size > 0 {
+		offsets = append(offsets, partitionLog.nextOffset)
+		timestamps = append(timestamps, time.Now().UnixMilli())
+	}
+
+	startIndex := -1
+	switch timestamp {
+	case listOffsetsLatestTimestamp:
+		startIndex = len(offsets) - 1
+	case listOffsetsEarliestTimestamp:
+		startIndex = 0
+	default:
+		for i := range timestamps {
+			if timestamps[i] <= timestamp {
+				startIndex = i
+			}
+		}
+	}
+
+	result := []int64{}
+	for i := startIndex; i >= 0 && len(result) < maxNumOffsets; i-- {
+		result = append(result, offsets[i])
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestPartitionLog returns a log with one segment per timestamp, each
+// holding a single batch with that timestamp, and the size of a segment.
+func newTestPartitionLog(t *testing.T, timestamps []int64) (*PartitionLog, int64) {
+	t.Helper()
+
+	config := serverConfig
+	t.Cleanup(func() { serverConfig = config })
+	serverConfig = defaultConfig()
+	// every append rolls a new segment
+	serverConfig.logSegmentBytes = 1
+
+	partitionLog := &PartitionLog{topicName: "retention", dir: t.TempDir()}
+	if err := partitionLog.load(); err != nil {
+		t.Fatal(err)
+	}
+	for _, timestamp := range timestamps {
+		if _, err := partitionLog.append(newRecordBatch(0, timestamp, nil, [][]byte{[]byte("value")})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return partitionLog, partitionLog.segments[0].size
+}
+
+func TestPartitionLogEnforceRetention(t *testing.T) {
+	now := time.Now().UnixMilli()
+	old := now - int64(2*time.Hour/time.Millisecond)
+	hour := int64(time.Hour / time.Millisecond)
+
+	tests := []struct {
+		name           string
+		timestamps     []int64
+		retentionMs    int64
+		retentionBytes int64
+		// in segments, converted to bytes
+		retentionSegments int64
+		deleted           int
+		logStartOffset    int64
+		segments          int
+	}{
+		{"no limits", []int64{old, old, now, now}, -1, -1, 0, 0, 0, 4},
+		{"time", []int64{old, old, now, now}, hour, -1, 0, 2, 2, 2},
+		{"time keeps everything recent", []int64{now, now, now}, hour, -1, 0, 0, 0, 3},
+		{"time stops at the first recent segment", []int64{old, now, old, now}, hour, -1, 0, 1, 1, 3},
+		{"time expires the active segment", []int64{old, old}, hour, -1, 0, 2, 2, 1},
+		{"bytes", []int64{now, now, now, now}, -1, 1, 2, 1, 1, 3},
+		{"bytes at a segment boundary", []int64{now, now, now, now}, -1, 0, 2, 2, 2, 2},
+		{"bytes below one segment", []int64{now, now}, -1, 0, 0, 2, 2, 1},
+		{"time or bytes", []int64{old, now, now, now}, hour, 0, 3, 1, 1, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			partitionLog, segmentSize := newTestPartitionLog(t, test.timestamps)
+			retentionBytes := test.retentionBytes
+			if retentionBytes >= 0 {
+				retentionBytes += test.retentionSegments * segmentSize
+			}
+
+			deleted, err := partitionLog.enforceRetention(test.retentionMs, retentionBytes)
+			if err != nil {
+				t.Fatalf("enforceRetention: %s", err)
+			}
+			logStartOffset, nextOffset := partitionLog.offsets()
+			if deleted != test.deleted || logStartOffset != test.logStartOffset || len(partitionLog.segments) != test.segments {
+				t.Fatalf("deleted %d, log start offset %d, %d segments, want %d, %d, %d",
+					deleted, logStartOffset, len(partitionLog.segments), test.deleted, test.logStartOffset, test.segments)
+			}
+			if nextOffset != int64(len(test.timestamps)) {
+				t.Fatalf("next offset %d, want %d", nextOffset, len(test.timestamps))
+			}
+
+			logFiles, err := filepath.Glob(filepath.Join(partitionLog.dir, "*.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// a segment rolled to replace the deleted ones has no file yet
+			wantFiles := 0
+			for _, segment := range partitionLog.segments {
+				if segment.size > 0 {
+					wantFiles++
+				}
+			}
+			if len(logFiles) != wantFiles {
+				t.Fatalf("%d log files left, want %d", len(logFiles), wantFiles)
+			}
+		})
+	}
+}
+
+func TestWatchedPartitionLogIsReadOnly(t *testing.T) {
+	config := serverConfig
+	t.Cleanup(func() { serverConfig = config })
+	serverConfig = defaultConfig()
+	// index every batch
+	serverConfig.logIndexIntervalBytes = 0
+
+	// the other process is halfway through writing the last batch
+	log, _ := testBatches(3)
+	dir := t.TempDir()
+	logFileName := filepath.Join(dir, fmt.Sprintf("%020d.log", 0))
+	if err := os.WriteFile(logFileName, log[:len(log)-5], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	partitionLog := &PartitionLog{topicName: "__cluster_metadata", dir: dir, watched: true}
+	if err := partitionLog.load(); err != nil {
+		t.Fatal(err)
+	}
+	// as after an unclean shutdown
+	if err := partitionLog.recover(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, nextOffset := partitionLog.offsets(); nextOffset != 2 {
+		t.Fatalf("next offset %d, want 2", nextOffset)
+	}
+	if logFile, err := os.Stat(logFileName); err != nil || logFile.Size() != int64(len(log)-5) {
+		t.Fatalf("log file changed, %v", err)
+	}
+	if indexes, _ := filepath.Glob(filepath.Join(dir, "*index")); len(indexes) > 0 {
+		t.Fatalf("index files %v written", indexes)
+	}
+	if entries := len(partitionLog.activeSegment().offsetIndex.entries); entries != 1 {
+		t.Fatalf("%d offset index entries in memory, want 1", entries)
+	}
+
+	// the rest of the batch shows up once written
+	if err := os.WriteFile(logFileName, log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	grew, err := partitionLog.refresh()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, nextOffset := partitionLog.offsets(); !grew || nextOffset != 3 {
+		t.Fatalf("grew %t to next offset %d, want 3", grew, nextOffset)
+	}
+	if indexes, _ := filepath.Glob(filepath.Join(dir, "*index")); len(indexes) > 0 {
+		t.Fatalf("index files %v written", indexes)
+	}
+}
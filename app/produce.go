@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
+)
+
+// Produce
+
+type ProduceRequest struct {
+	RequestHeader
+	protocol.ProduceRequest
+}
+
+func (request *ProduceRequest) parse(decoder *protocol.Decoder) error {
+	return request.ProduceRequest.Decode(decoder, request.apiVersion)
+}
+
+// generateResponse returns false when the producer asked for acks=0, in which
+// case the broker must not send a response at all.
+func (request *ProduceRequest) generateResponse(commonResponse *Response) bool {
+	commonResponse.correlationId = request.correlationId
+
+	produceResponse := protocol.ProduceResponse{}
+	produceResponse.Default()
+
+	clusterTopics := getClusterTopics()
+
+	for _, topicData := range request.TopicData {
+		topicResponse := &protocol.ProduceResponseTopicProduceResponse{Name: topicData.Name}
+
+		topic := findTopicByName(clusterTopics, topicData.Name)
+		topicConfigs := getTopicConfigs(topicData.Name)
+
+		for _, partitionData := range topicData.PartitionData {
+			partitionResponse := &protocol.ProduceResponsePartitionProduceResponse{}
+			partitionResponse.Default()
+			partitionResponse.Index = partitionData.Index
+			partitionResponse.BaseOffset = -1
+			topicResponse.PartitionResponses = append(topicResponse.PartitionResponses, partitionResponse)
+
+			if request.Acks != 0 && request.Acks != 1 && request.Acks != -1 {
+				partitionResponse.ErrorCode = errorCodeInvalidRequiredAcks
+				continue
+			}
+
+			if topic == nil || !topicHasPartition(topic, partitionData.Index) {
+				partitionResponse.ErrorCode = errorCodeUnknownTopicOrPartition
+				continue
+			}
+
+			batchIndex, errorCode, errorMessage := validateRecordBatches(partitionData.Records, request.apiVersion)
+			if errorCode != errorCodeNone {
+				partitionResponse.ErrorCode = errorCode
+				partitionResponse.ErrorMessage = errorMessage
+				partitionResponse.RecordErrors = append(partitionResponse.RecordErrors, &protocol.ProduceResponseBatchIndexAndErrorMessage{
+					BatchIndex:             int32(batchIndex),
+					BatchIndexErrorMessage: errorMessage,
+				})
+				continue
+			}
+
+			records, err := convertRecordBatches(partitionData.Records, topicConfigs["compression.type"])
+			if err != nil {
+				fmt.Printf("Error while converting record batches. %s\n", err)
+				partitionResponse.ErrorCode = errorCodeCorruptMessage
+				continue
+			}
+			logAppendTime := int64(-1)
+			if topicConfigs["message.timestamp.type"] == "LogAppendTime" {
+				logAppendTime = time.Now().UnixMilli()
+				setLogAppendTime(records, logAppendTime)
+			}
+
+			partitionLog, err := getPartitionLog(topicData.Name, partitionData.Index)
+			if err != nil {
+				fmt.Printf("Error while opening partition log. %s\n", err)
+				partitionResponse.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
+
+			baseOffset, err := partitionLog.append(records)
+			if err != nil {
+				fmt.Printf("Error while appending to partition log. %s\n", err)
+				partitionResponse.ErrorCode = errorCodeKafkaStorageError
+				continue
+			}
+			partitionResponse.BaseOffset = baseOffset
+			partitionResponse.LogAppendTimeMs = logAppendTime
+			partitionResponse.LogStartOffset, _ = partitionLog.offsets()
+		}
+
+		produceResponse.Responses = append(produceResponse.Responses, topicResponse)
+	}
+
+	if request.Acks == 0 {
+		return false
+	}
+
+	commonResponse.body = protocol.NewEncoder(produceResponse.IsFlexible(request.apiVersion))
+	produceResponse.Encode(commonResponse.body, request.apiVersion)
+	return true
+}
+
+func topicHasPartition(topic *Topic, partitionIndex int32) bool {
+	for _, partition := range topic.partitions {
+		if partition.partitionIndex == partitionIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRecordBatches checks that records is a sequence of well formed
+// RecordBatch v2 entries. On failure it returns the index of the bad batch
+// along with the error code and message to send back.
+func validateRecordBatches(records []byte, apiVersion int16) (int, int16, string) {
+	if len(records) == 0 {
+		return 0, errorCodeCorruptMessage, "no record batches"
+	}
+
+	batchIndex := 0
+	for position := 0; position < len(records); batchIndex++ {
+		batch := records[position:]
+		if len(batch) < recordBatchHeaderSize {
+			return batchIndex, errorCodeCorruptMessage, "record batch is truncated"
+		}
+
+		batchHeader := &ClusterMetadata{}
+		if err := readRecordBatchHeader(protocol.NewDecoder(batch[:recordBatchHeaderSize], false), batchHeader); err != nil {
+			return batchIndex, errorCodeCorruptMessage, fmt.Sprintf("record batch header can't be read, %s", err)
+		}
+
+		if batchHeader.magicByte != 2 {
+			return batchIndex, errorCodeUnsupportedForMessageFormat, fmt.Sprintf("unsupported record batch magic %d", batchHeader.magicByte)
+		}
+		batchSize := recordBatchLogOverhead + int(batchHeader.batchLength)
+		if batchSize < recordBatchHeaderSize || batchSize > len(batch) {
+			return batchIndex, errorCodeCorruptMessage, fmt.Sprintf("invalid record batch length %d", batchHeader.batchLength)
+		}
+		if !recordBatchCrcMatches(batch[:batchSize]) {
+			return batchIndex, errorCodeCorruptMessage, "record batch crc does not match its contents"
+		}
+		if batchHeader.recordsLength == 0 || batchHeader.lastOffsetDelta != batchHeader.recordsLength-1 {
+			return batchIndex, errorCodeInvalidRecord, "record count does not match lastOffsetDelta"
+		}
+
+		codec := int16(batchHeader.attributes & recordBatchCompressionMask)
+		if codec > compressionZstd {
+			return batchIndex, errorCodeUnsupportedCompressionType, fmt.Sprintf("unknown compression codec %d", codec)
+		}
+		// zstd came with produce v7, older clients can't read it back
+		if codec == compressionZstd && apiVersion < 7 {
+			return batchIndex, errorCodeUnsupportedCompressionType, "zstd compressed batches need produce v7 or later"
+		}
+		batchRecords, err := recordBatchRecords(batch[:batchSize])
+		if err != nil {
+			return batchIndex, errorCodeCorruptMessage, fmt.Sprintf("record batch can't be decompressed, %s", err)
+		}
+
+		recordsBuffer := bytes.NewBuffer(batchRecords)
+		for i := uint32(0); i < batchHeader.recordsLength; i++ {
+			recordLength, err := binary.ReadVarint(recordsBuffer)
+			if err != nil || recordLength <= 0 || recordLength > int64(recordsBuffer.Len()) {
+				return batchIndex, errorCodeCorruptMessage, fmt.Sprintf("invalid length for record %d", i)
+			}
+			recordsBuffer.Next(int(recordLength))
+		}
+		if recordsBuffer.Len() != 0 {
+			return batchIndex, errorCodeCorruptMessage, "unexpected bytes after the last record"
+		}
+
+		position += batchSize
+	}
+
+	return 0, errorCodeNone, ""
+}
+
+// convertRecordBatches compresses the batches of validated records with the
+// codec of the topic's compression.type, unless that is "producer". Records
+// of compressed batches have their offset deltas renumbered from 0 when the
+// producer left gaps, offsets are assigned to the batch as a whole.
+func convertRecordBatches(records []byte, compressionType string) ([]byte, error) {
+	converted := make([]byte, 0, len(records))
+	for position := 0; position < len(records); {
+		batchSize := recordBatchLogOverhead + int(binary.BigEndian.Uint32(records[position+recordBatchLengthPosition:]))
+		batch := records[position : position+batchSize]
+		position += batchSize
+
+		codec := recordBatchCodec(batch)
+		targetCodec, ok := compressionCodecForType(compressionType)
+		if !ok {
+			targetCodec = codec
+		}
+		// control batches are never compressed
+		isControlBatch := binary.BigEndian.Uint16(batch[recordBatchAttributesPosition:])&recordBatchControlFlag != 0
+		if isControlBatch || codec == compressionNone && targetCodec == compressionNone {
+			converted = append(converted, batch...)
+			continue
+		}
+
+		batchRecords, err := recordBatchRecords(batch)
+		if err != nil {
+			return nil, err
+		}
+		count := binary.BigEndian.Uint32(batch[recordBatchRecordsCountPosition:])
+		renumbered := &bytes.Buffer{}
+		inOrder := true
+		recordsDecoder := protocol.NewDecoder(batchRecords, false)
+		for i := int64(0); i < int64(count); i++ {
+			record, err := readRecord(recordsDecoder)
+			if err != nil {
+				return nil, err
+			}
+			inOrder = inOrder && record.offsetDelta == i
+			renumbered.Write(setRecordOffsetDelta(record.raw, i))
+		}
+		if inOrder && codec == targetCodec {
+			converted = append(converted, batch...)
+			continue
+		}
+
+		rebuilt, err := rebuildRecordBatch(batch, targetCodec, renumbered.Bytes(), count)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, rebuilt...)
+	}
+	return converted, nil
+}
+
+// setLogAppendTime stamps every batch with the time the broker appends it,
+// for topics with message.timestamp.type=LogAppendTime. Like Kafka, only the
+// header changes: maxTimestamp becomes the append time and the records are
+// read as having it.
+func setLogAppendTime(records []byte, logAppendTime int64) {
+	for position := 0; position < len(records); {
+		batch := records[position:]
+		batchSize := recordBatchLogOverhead + int(binary.BigEndian.Uint32(batch[recordBatchLengthPosition:]))
+		attributes := binary.BigEndian.Uint16(batch[recordBatchAttributesPosition:])
+		binary.BigEndian.PutUint16(batch[recordBatchAttributesPosition:], attributes|recordBatchLogAppendTimeFlag)
+		binary.BigEndian.PutUint64(batch[recordBatchMaxTimestampPosition:], uint64(logAppendTime))
+		setRecordBatchCrc(batch[:batchSize])
+		position += batchSize
+	}
+}
@@ -0,0 +1,87 @@
+// Code generated by gen from schema/ApiVersionsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// ApiVersionsRequest is the request of API key 18, versions 0 to 4.
+type ApiVersionsRequest struct {
+	// The name of the client.
+	ClientSoftwareName string
+	// The version of the client.
+	ClientSoftwareVersion string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ApiVersionsRequest) ApiKey() int16 {
+	return 18
+}
+
+func (v *ApiVersionsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *ApiVersionsRequest) MaxVersion() int16 {
+	return 4
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ApiVersionsRequest) IsFlexible(version int16) bool {
+	return version >= 3
+}
+
+// Decode reads version of ApiVersionsRequest from decoder.
+func (v *ApiVersionsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 4 {
+		return fmt.Errorf("ApiVersionsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ApiVersionsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *ApiVersionsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 4 {
+		encoder.fail(fmt.Errorf("ApiVersionsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ApiVersionsRequest) Default() {
+	*v = ApiVersionsRequest{}
+}
+
+func (v *ApiVersionsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.ClientSoftwareName = decoder.ReadString()
+	}
+	if version >= 3 {
+		v.ClientSoftwareVersion = decoder.ReadString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ApiVersionsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ApiVersionsRequest{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteString(v.ClientSoftwareName)
+	}
+	if version >= 3 {
+		encoder.WriteString(v.ClientSoftwareVersion)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,319 @@
+// Code generated by gen from schema/ApiVersionsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+	"maps"
+)
+
+// ApiVersionsResponse is the response of API key 18, versions 0 to 4.
+type ApiVersionsResponse struct {
+	// The top-level error code.
+	ErrorCode int16
+	// The APIs supported by the broker.
+	ApiKeys []*ApiVersionsResponseApiVersion
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// Features supported by the broker. Note: in v0-v3, features with MinSupportedVersion = 0 are omitted.
+	SupportedFeatures []*ApiVersionsResponseSupportedFeatureKey
+	// The monotonically increasing epoch for the finalized features information. Valid values are >= 0. A value of -1 is special and represents unknown epoch.
+	FinalizedFeaturesEpoch int64
+	// List of cluster-wide finalized features. The information is valid only if FinalizedFeaturesEpoch >= 0.
+	FinalizedFeatures []*ApiVersionsResponseFinalizedFeatureKey
+	// Set by a KRaft controller if the required configurations for ZK migration are present
+	ZkMigrationReady bool
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ApiVersionsResponse) ApiKey() int16 {
+	return 18
+}
+
+func (v *ApiVersionsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *ApiVersionsResponse) MaxVersion() int16 {
+	return 4
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ApiVersionsResponse) IsFlexible(version int16) bool {
+	return version >= 3
+}
+
+// Decode reads version of ApiVersionsResponse from decoder.
+func (v *ApiVersionsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 4 {
+		return fmt.Errorf("ApiVersionsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ApiVersionsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *ApiVersionsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 4 {
+		encoder.fail(fmt.Errorf("ApiVersionsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ApiVersionsResponse) Default() {
+	*v = ApiVersionsResponse{}
+	v.FinalizedFeaturesEpoch = -1
+}
+
+func (v *ApiVersionsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ErrorCode = decoder.ReadInt16()
+	apiKeysLength := decoder.ReadArrayLength()
+	v.ApiKeys = make([]*ApiVersionsResponseApiVersion, apiKeysLength)
+	for i := range v.ApiKeys {
+		v.ApiKeys[i] = &ApiVersionsResponseApiVersion{}
+		v.ApiKeys[i].decode(decoder, version)
+	}
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 3 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		supportedFeaturesLength := tagged.ReadArrayLength()
+		v.SupportedFeatures = make([]*ApiVersionsResponseSupportedFeatureKey, supportedFeaturesLength)
+		for i := range v.SupportedFeatures {
+			v.SupportedFeatures[i] = &ApiVersionsResponseSupportedFeatureKey{}
+			v.SupportedFeatures[i].decode(tagged, version)
+		}
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[1]; ok && version >= 3 {
+		delete(v.UnknownTaggedFields, 1)
+		tagged := NewDecoder(data, true)
+		v.FinalizedFeaturesEpoch = tagged.ReadInt64()
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 1: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[2]; ok && version >= 3 {
+		delete(v.UnknownTaggedFields, 2)
+		tagged := NewDecoder(data, true)
+		finalizedFeaturesLength := tagged.ReadArrayLength()
+		v.FinalizedFeatures = make([]*ApiVersionsResponseFinalizedFeatureKey, finalizedFeaturesLength)
+		for i := range v.FinalizedFeatures {
+			v.FinalizedFeatures[i] = &ApiVersionsResponseFinalizedFeatureKey{}
+			v.FinalizedFeatures[i].decode(tagged, version)
+		}
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 2: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[3]; ok && version >= 3 {
+		delete(v.UnknownTaggedFields, 3)
+		tagged := NewDecoder(data, true)
+		v.ZkMigrationReady = tagged.ReadBool()
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 3: %w", err))
+		}
+	}
+}
+
+func (v *ApiVersionsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ApiVersionsResponse{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteArrayLength(len(v.ApiKeys))
+	for _, element := range v.ApiKeys {
+		element.encode(encoder, version)
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 3 && len(v.SupportedFeatures) > 0 {
+		tagged := NewEncoder(true)
+		tagged.WriteArrayLength(len(v.SupportedFeatures))
+		for _, element := range v.SupportedFeatures {
+			element.encode(tagged, version)
+		}
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	if version >= 3 && v.FinalizedFeaturesEpoch != -1 {
+		tagged := NewEncoder(true)
+		tagged.WriteInt64(v.FinalizedFeaturesEpoch)
+		encoder.fail(tagged.Err())
+		taggedFields[1] = tagged.Bytes()
+	}
+	if version >= 3 && len(v.FinalizedFeatures) > 0 {
+		tagged := NewEncoder(true)
+		tagged.WriteArrayLength(len(v.FinalizedFeatures))
+		for _, element := range v.FinalizedFeatures {
+			element.encode(tagged, version)
+		}
+		encoder.fail(tagged.Err())
+		taggedFields[2] = tagged.Bytes()
+	}
+	if version >= 3 && v.ZkMigrationReady {
+		tagged := NewEncoder(true)
+		tagged.WriteBool(v.ZkMigrationReady)
+		encoder.fail(tagged.Err())
+		taggedFields[3] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// ApiVersionsResponseApiVersion is a struct of ApiVersionsResponse.
+type ApiVersionsResponseApiVersion struct {
+	// The API index.
+	ApiKey int16
+	// The minimum supported version, inclusive.
+	MinVersion int16
+	// The maximum supported version, inclusive.
+	MaxVersion int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ApiVersionsResponseApiVersion) Default() {
+	*v = ApiVersionsResponseApiVersion{}
+}
+
+func (v *ApiVersionsResponseApiVersion) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ApiKey = decoder.ReadInt16()
+	v.MinVersion = decoder.ReadInt16()
+	v.MaxVersion = decoder.ReadInt16()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ApiVersionsResponseApiVersion) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ApiVersionsResponseApiVersion{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ApiKey)
+	encoder.WriteInt16(v.MinVersion)
+	encoder.WriteInt16(v.MaxVersion)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ApiVersionsResponseSupportedFeatureKey is a struct of ApiVersionsResponse.
+type ApiVersionsResponseSupportedFeatureKey struct {
+	// The name of the feature.
+	Name string
+	// The minimum supported version for the feature.
+	MinVersion int16
+	// The maximum supported version for the feature.
+	MaxVersion int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ApiVersionsResponseSupportedFeatureKey) Default() {
+	*v = ApiVersionsResponseSupportedFeatureKey{}
+}
+
+func (v *ApiVersionsResponseSupportedFeatureKey) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 3 {
+		v.MinVersion = decoder.ReadInt16()
+	}
+	if version >= 3 {
+		v.MaxVersion = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ApiVersionsResponseSupportedFeatureKey) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ApiVersionsResponseSupportedFeatureKey{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 3 {
+		encoder.WriteInt16(v.MinVersion)
+	}
+	if version >= 3 {
+		encoder.WriteInt16(v.MaxVersion)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ApiVersionsResponseFinalizedFeatureKey is a struct of ApiVersionsResponse.
+type ApiVersionsResponseFinalizedFeatureKey struct {
+	// The name of the feature.
+	Name string
+	// The cluster-wide finalized max version level for the feature.
+	MaxVersionLevel int16
+	// The cluster-wide finalized min version level for the feature.
+	MinVersionLevel int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ApiVersionsResponseFinalizedFeatureKey) Default() {
+	*v = ApiVersionsResponseFinalizedFeatureKey{}
+}
+
+func (v *ApiVersionsResponseFinalizedFeatureKey) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 3 {
+		v.MaxVersionLevel = decoder.ReadInt16()
+	}
+	if version >= 3 {
+		v.MinVersionLevel = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ApiVersionsResponseFinalizedFeatureKey) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ApiVersionsResponseFinalizedFeatureKey{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 3 {
+		encoder.WriteInt16(v.MaxVersionLevel)
+	}
+	if version >= 3 {
+		encoder.WriteInt16(v.MinVersionLevel)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,180 @@
+// Code generated by gen from schema/CreatePartitionsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// CreatePartitionsRequest is the request of API key 37, versions 0 to 3.
+type CreatePartitionsRequest struct {
+	// Each topic that we want to create new partitions inside.
+	Topics []*CreatePartitionsRequestCreatePartitionsTopic
+	// The time in ms to wait for the partitions to be created.
+	TimeoutMs int32
+	// If true, then validate the request, but don't actually increase the number of partitions.
+	ValidateOnly bool
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *CreatePartitionsRequest) ApiKey() int16 {
+	return 37
+}
+
+func (v *CreatePartitionsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *CreatePartitionsRequest) MaxVersion() int16 {
+	return 3
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *CreatePartitionsRequest) IsFlexible(version int16) bool {
+	return version >= 2
+}
+
+// Decode reads version of CreatePartitionsRequest from decoder.
+func (v *CreatePartitionsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 3 {
+		return fmt.Errorf("CreatePartitionsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of CreatePartitionsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *CreatePartitionsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 3 {
+		encoder.fail(fmt.Errorf("CreatePartitionsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreatePartitionsRequest) Default() {
+	*v = CreatePartitionsRequest{}
+}
+
+func (v *CreatePartitionsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*CreatePartitionsRequestCreatePartitionsTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &CreatePartitionsRequestCreatePartitionsTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.TimeoutMs = decoder.ReadInt32()
+	v.ValidateOnly = decoder.ReadBool()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreatePartitionsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreatePartitionsRequest{}
+		v.Default()
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteInt32(v.TimeoutMs)
+	encoder.WriteBool(v.ValidateOnly)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreatePartitionsRequestCreatePartitionsTopic is a struct of CreatePartitionsRequest.
+type CreatePartitionsRequestCreatePartitionsTopic struct {
+	// The topic name.
+	Name string
+	// The new partition count.
+	Count int32
+	// The new partition assignments.
+	Assignments []*CreatePartitionsRequestCreatePartitionsAssignment
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreatePartitionsRequestCreatePartitionsTopic) Default() {
+	*v = CreatePartitionsRequestCreatePartitionsTopic{}
+}
+
+func (v *CreatePartitionsRequestCreatePartitionsTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.Count = decoder.ReadInt32()
+	assignmentsLength := decoder.ReadNullableArrayLength()
+	if assignmentsLength >= 0 {
+		v.Assignments = make([]*CreatePartitionsRequestCreatePartitionsAssignment, assignmentsLength)
+		for i := range v.Assignments {
+			v.Assignments[i] = &CreatePartitionsRequestCreatePartitionsAssignment{}
+			v.Assignments[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreatePartitionsRequestCreatePartitionsTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreatePartitionsRequestCreatePartitionsTopic{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteInt32(v.Count)
+	if v.Assignments == nil {
+		encoder.WriteArrayLength(-1)
+	} else {
+		encoder.WriteArrayLength(len(v.Assignments))
+		for _, element := range v.Assignments {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreatePartitionsRequestCreatePartitionsAssignment is a struct of CreatePartitionsRequestCreatePartitionsTopic.
+type CreatePartitionsRequestCreatePartitionsAssignment struct {
+	// The assigned broker IDs.
+	BrokerIds []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreatePartitionsRequestCreatePartitionsAssignment) Default() {
+	*v = CreatePartitionsRequestCreatePartitionsAssignment{}
+}
+
+func (v *CreatePartitionsRequestCreatePartitionsAssignment) decode(decoder *Decoder, version int16) {
+	v.Default()
+	brokerIdsLength := decoder.ReadArrayLength()
+	v.BrokerIds = make([]int32, brokerIdsLength)
+	for i := range v.BrokerIds {
+		v.BrokerIds[i] = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreatePartitionsRequestCreatePartitionsAssignment) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreatePartitionsRequestCreatePartitionsAssignment{}
+		v.Default()
+	}
+	encoder.WriteArrayLength(len(v.BrokerIds))
+	for _, element := range v.BrokerIds {
+		encoder.WriteInt32(element)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
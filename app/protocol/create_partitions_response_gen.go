@@ -0,0 +1,125 @@
+// Code generated by gen from schema/CreatePartitionsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// CreatePartitionsResponse is the response of API key 37, versions 0 to 3.
+type CreatePartitionsResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The partition creation results for each topic.
+	Results []*CreatePartitionsResponseCreatePartitionsTopicResult
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *CreatePartitionsResponse) ApiKey() int16 {
+	return 37
+}
+
+func (v *CreatePartitionsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *CreatePartitionsResponse) MaxVersion() int16 {
+	return 3
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *CreatePartitionsResponse) IsFlexible(version int16) bool {
+	return version >= 2
+}
+
+// Decode reads version of CreatePartitionsResponse from decoder.
+func (v *CreatePartitionsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 3 {
+		return fmt.Errorf("CreatePartitionsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of CreatePartitionsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *CreatePartitionsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 3 {
+		encoder.fail(fmt.Errorf("CreatePartitionsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreatePartitionsResponse) Default() {
+	*v = CreatePartitionsResponse{}
+}
+
+func (v *CreatePartitionsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ThrottleTimeMs = decoder.ReadInt32()
+	resultsLength := decoder.ReadArrayLength()
+	v.Results = make([]*CreatePartitionsResponseCreatePartitionsTopicResult, resultsLength)
+	for i := range v.Results {
+		v.Results[i] = &CreatePartitionsResponseCreatePartitionsTopicResult{}
+		v.Results[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreatePartitionsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreatePartitionsResponse{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.ThrottleTimeMs)
+	encoder.WriteArrayLength(len(v.Results))
+	for _, element := range v.Results {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreatePartitionsResponseCreatePartitionsTopicResult is a struct of CreatePartitionsResponse.
+type CreatePartitionsResponseCreatePartitionsTopicResult struct {
+	// The topic name.
+	Name string
+	// The result error, or zero if there was no error.
+	ErrorCode int16
+	// The result message, or null if there was no error.
+	ErrorMessage string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreatePartitionsResponseCreatePartitionsTopicResult) Default() {
+	*v = CreatePartitionsResponseCreatePartitionsTopicResult{}
+}
+
+func (v *CreatePartitionsResponseCreatePartitionsTopicResult) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.ErrorCode = decoder.ReadInt16()
+	v.ErrorMessage, _ = decoder.ReadNullableString()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreatePartitionsResponseCreatePartitionsTopicResult) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreatePartitionsResponseCreatePartitionsTopicResult{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteNullableString(v.ErrorMessage)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
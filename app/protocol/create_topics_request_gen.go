@@ -0,0 +1,233 @@
+// Code generated by gen from schema/CreateTopicsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// CreateTopicsRequest is the request of API key 19, versions 0 to 7.
+type CreateTopicsRequest struct {
+	// The topics to create.
+	Topics []*CreateTopicsRequestCreatableTopic
+	// How long to wait in milliseconds before timing out the request.
+	TimeoutMs int32
+	// If true, check that the topics can be created as specified, but don't create anything.
+	ValidateOnly bool
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *CreateTopicsRequest) ApiKey() int16 {
+	return 19
+}
+
+func (v *CreateTopicsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *CreateTopicsRequest) MaxVersion() int16 {
+	return 7
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *CreateTopicsRequest) IsFlexible(version int16) bool {
+	return version >= 5
+}
+
+// Decode reads version of CreateTopicsRequest from decoder.
+func (v *CreateTopicsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 7 {
+		return fmt.Errorf("CreateTopicsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of CreateTopicsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *CreateTopicsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 7 {
+		encoder.fail(fmt.Errorf("CreateTopicsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsRequest) Default() {
+	*v = CreateTopicsRequest{}
+	v.TimeoutMs = 60000
+}
+
+func (v *CreateTopicsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*CreateTopicsRequestCreatableTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &CreateTopicsRequestCreatableTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.TimeoutMs = decoder.ReadInt32()
+	if version >= 1 {
+		v.ValidateOnly = decoder.ReadBool()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsRequest{}
+		v.Default()
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteInt32(v.TimeoutMs)
+	if version >= 1 {
+		encoder.WriteBool(v.ValidateOnly)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreateTopicsRequestCreatableTopic is a struct of CreateTopicsRequest.
+type CreateTopicsRequestCreatableTopic struct {
+	// The topic name.
+	Name string
+	// The number of partitions to create in the topic, or -1 if we are either specifying a manual partition assignment or using the default partitions.
+	NumPartitions int32
+	// The number of replicas to create for each partition in the topic, or -1 if we are either specifying a manual partition assignment or using the default replication factor.
+	ReplicationFactor int16
+	// The manual partition assignment, or the empty array if we are using automatic assignment.
+	Assignments []*CreateTopicsRequestCreatableReplicaAssignment
+	// The custom topic configurations to set.
+	Configs []*CreateTopicsRequestCreatableTopicConfig
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsRequestCreatableTopic) Default() {
+	*v = CreateTopicsRequestCreatableTopic{}
+}
+
+func (v *CreateTopicsRequestCreatableTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.NumPartitions = decoder.ReadInt32()
+	v.ReplicationFactor = decoder.ReadInt16()
+	assignmentsLength := decoder.ReadArrayLength()
+	v.Assignments = make([]*CreateTopicsRequestCreatableReplicaAssignment, assignmentsLength)
+	for i := range v.Assignments {
+		v.Assignments[i] = &CreateTopicsRequestCreatableReplicaAssignment{}
+		v.Assignments[i].decode(decoder, version)
+	}
+	configsLength := decoder.ReadArrayLength()
+	v.Configs = make([]*CreateTopicsRequestCreatableTopicConfig, configsLength)
+	for i := range v.Configs {
+		v.Configs[i] = &CreateTopicsRequestCreatableTopicConfig{}
+		v.Configs[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsRequestCreatableTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsRequestCreatableTopic{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteInt32(v.NumPartitions)
+	encoder.WriteInt16(v.ReplicationFactor)
+	encoder.WriteArrayLength(len(v.Assignments))
+	for _, element := range v.Assignments {
+		element.encode(encoder, version)
+	}
+	encoder.WriteArrayLength(len(v.Configs))
+	for _, element := range v.Configs {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreateTopicsRequestCreatableReplicaAssignment is a struct of CreateTopicsRequestCreatableTopic.
+type CreateTopicsRequestCreatableReplicaAssignment struct {
+	// The partition index.
+	PartitionIndex int32
+	// The brokers to place the partition on.
+	BrokerIds []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsRequestCreatableReplicaAssignment) Default() {
+	*v = CreateTopicsRequestCreatableReplicaAssignment{}
+}
+
+func (v *CreateTopicsRequestCreatableReplicaAssignment) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	brokerIdsLength := decoder.ReadArrayLength()
+	v.BrokerIds = make([]int32, brokerIdsLength)
+	for i := range v.BrokerIds {
+		v.BrokerIds[i] = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsRequestCreatableReplicaAssignment) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsRequestCreatableReplicaAssignment{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteArrayLength(len(v.BrokerIds))
+	for _, element := range v.BrokerIds {
+		encoder.WriteInt32(element)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreateTopicsRequestCreatableTopicConfig is a struct of CreateTopicsRequestCreatableTopic.
+type CreateTopicsRequestCreatableTopicConfig struct {
+	// The configuration name.
+	Name string
+	// The configuration value.
+	Value string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsRequestCreatableTopicConfig) Default() {
+	*v = CreateTopicsRequestCreatableTopicConfig{}
+}
+
+func (v *CreateTopicsRequestCreatableTopicConfig) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.Value, _ = decoder.ReadNullableString()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsRequestCreatableTopicConfig) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsRequestCreatableTopicConfig{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteNullableString(v.Value)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,271 @@
+// Code generated by gen from schema/CreateTopicsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// CreateTopicsResponse is the response of API key 19, versions 0 to 7.
+type CreateTopicsResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// Results for each topic we tried to create.
+	Topics []*CreateTopicsResponseCreatableTopicResult
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *CreateTopicsResponse) ApiKey() int16 {
+	return 19
+}
+
+func (v *CreateTopicsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *CreateTopicsResponse) MaxVersion() int16 {
+	return 7
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *CreateTopicsResponse) IsFlexible(version int16) bool {
+	return version >= 5
+}
+
+// Decode reads version of CreateTopicsResponse from decoder.
+func (v *CreateTopicsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 7 {
+		return fmt.Errorf("CreateTopicsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of CreateTopicsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *CreateTopicsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 7 {
+		encoder.fail(fmt.Errorf("CreateTopicsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsResponse) Default() {
+	*v = CreateTopicsResponse{}
+}
+
+func (v *CreateTopicsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 2 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*CreateTopicsResponseCreatableTopicResult, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &CreateTopicsResponseCreatableTopicResult{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsResponse{}
+		v.Default()
+	}
+	if version >= 2 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// CreateTopicsResponseCreatableTopicResult is a struct of CreateTopicsResponse.
+type CreateTopicsResponseCreatableTopicResult struct {
+	// The topic name.
+	Name string
+	// The unique topic ID
+	TopicId uuid.UUID
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The error message, or null if there was no error.
+	ErrorMessage string
+	// Optional topic config error returned if configs are not returned in the response.
+	TopicConfigErrorCode int16
+	// Number of partitions of the topic.
+	NumPartitions int32
+	// Replication factor of the topic.
+	ReplicationFactor int16
+	// Configuration of the topic.
+	Configs []*CreateTopicsResponseCreatableTopicConfigs
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsResponseCreatableTopicResult) Default() {
+	*v = CreateTopicsResponseCreatableTopicResult{}
+	v.NumPartitions = -1
+	v.ReplicationFactor = -1
+}
+
+func (v *CreateTopicsResponseCreatableTopicResult) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	if version >= 7 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	if version >= 1 {
+		v.ErrorMessage, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.NumPartitions = decoder.ReadInt32()
+	}
+	if version >= 5 {
+		v.ReplicationFactor = decoder.ReadInt16()
+	}
+	if version >= 5 {
+		configsLength := decoder.ReadNullableArrayLength()
+		if configsLength >= 0 {
+			v.Configs = make([]*CreateTopicsResponseCreatableTopicConfigs, configsLength)
+			for i := range v.Configs {
+				v.Configs[i] = &CreateTopicsResponseCreatableTopicConfigs{}
+				v.Configs[i].decode(decoder, version)
+			}
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 5 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		v.TopicConfigErrorCode = tagged.ReadInt16()
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+}
+
+func (v *CreateTopicsResponseCreatableTopicResult) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsResponseCreatableTopicResult{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	if version >= 7 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	if version >= 1 {
+		encoder.WriteNullableString(v.ErrorMessage)
+	}
+	if version >= 5 {
+		encoder.WriteInt32(v.NumPartitions)
+	}
+	if version >= 5 {
+		encoder.WriteInt16(v.ReplicationFactor)
+	}
+	if version >= 5 {
+		if v.Configs == nil {
+			encoder.WriteArrayLength(-1)
+		} else {
+			encoder.WriteArrayLength(len(v.Configs))
+			for _, element := range v.Configs {
+				element.encode(encoder, version)
+			}
+		}
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 5 && v.TopicConfigErrorCode != 0 {
+		tagged := NewEncoder(true)
+		tagged.WriteInt16(v.TopicConfigErrorCode)
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// CreateTopicsResponseCreatableTopicConfigs is a struct of CreateTopicsResponseCreatableTopicResult.
+type CreateTopicsResponseCreatableTopicConfigs struct {
+	// The configuration name.
+	Name string
+	// The configuration value.
+	Value string
+	// True if the configuration is read-only.
+	ReadOnly bool
+	// The configuration source.
+	ConfigSource int8
+	// True if this configuration is sensitive.
+	IsSensitive bool
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *CreateTopicsResponseCreatableTopicConfigs) Default() {
+	*v = CreateTopicsResponseCreatableTopicConfigs{}
+	v.ConfigSource = -1
+}
+
+func (v *CreateTopicsResponseCreatableTopicConfigs) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 5 {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 5 {
+		v.Value, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.ReadOnly = decoder.ReadBool()
+	}
+	if version >= 5 {
+		v.ConfigSource = decoder.ReadInt8()
+	}
+	if version >= 5 {
+		v.IsSensitive = decoder.ReadBool()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *CreateTopicsResponseCreatableTopicConfigs) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &CreateTopicsResponseCreatableTopicConfigs{}
+		v.Default()
+	}
+	if version >= 5 {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 5 {
+		encoder.WriteNullableString(v.Value)
+	}
+	if version >= 5 {
+		encoder.WriteBool(v.ReadOnly)
+	}
+	if version >= 5 {
+		encoder.WriteInt8(v.ConfigSource)
+	}
+	if version >= 5 {
+		encoder.WriteBool(v.IsSensitive)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,286 @@
+// Package protocol encodes and decodes the primitive types of the Kafka wire
+// protocol, see https://kafka.apache.org/protocol.html#protocol_types
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnexpectedEnd = errors.New("unexpected end of message")
+	ErrInvalidLength = errors.New("invalid length")
+	ErrInvalidVarint = errors.New("invalid varint")
+)
+
+// Decoder reads the fields of a message in order. The first error, a read
+// past the end of the message or an invalid length, sticks: later reads
+// return zero values and Err reports it, so a message can be read whole and
+// checked once.
+type Decoder struct {
+	// Flexible selects the compact strings, bytes and arrays and the tagged
+	// fields of flexible API versions.
+	Flexible bool
+
+	data     []byte
+	position int
+	err      error
+}
+
+func NewDecoder(data []byte, flexible bool) *Decoder {
+	return &Decoder{Flexible: flexible, data: data}
+}
+
+// Err returns the first error hit while decoding.
+func (decoder *Decoder) Err() error {
+	return decoder.err
+}
+
+// Remaining returns the number of bytes left to read.
+func (decoder *Decoder) Remaining() int {
+	return len(decoder.data) - decoder.position
+}
+
+func (decoder *Decoder) fail(err error) {
+	if decoder.err == nil {
+		decoder.err = fmt.Errorf("%w at offset %d", err, decoder.position)
+	}
+}
+
+// next returns the next n bytes of the message, nil once an error was hit.
+func (decoder *Decoder) next(n int) []byte {
+	if decoder.err != nil {
+		return nil
+	}
+	if n < 0 || n > decoder.Remaining() {
+		decoder.fail(ErrUnexpectedEnd)
+		return nil
+	}
+	data := decoder.data[decoder.position : decoder.position+n]
+	decoder.position += n
+	return data
+}
+
+func (decoder *Decoder) ReadInt8() int8 {
+	data := decoder.next(1)
+	if data == nil {
+		return 0
+	}
+	return int8(data[0])
+}
+
+func (decoder *Decoder) ReadBool() bool {
+	return decoder.ReadInt8() != 0
+}
+
+func (decoder *Decoder) ReadInt16() int16 {
+	data := decoder.next(2)
+	if data == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(data))
+}
+
+func (decoder *Decoder) ReadUint16() uint16 {
+	return uint16(decoder.ReadInt16())
+}
+
+func (decoder *Decoder) ReadInt32() int32 {
+	data := decoder.next(4)
+	if data == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(data))
+}
+
+func (decoder *Decoder) ReadUint32() uint32 {
+	return uint32(decoder.ReadInt32())
+}
+
+func (decoder *Decoder) ReadInt64() int64 {
+	data := decoder.next(8)
+	if data == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(data))
+}
+
+func (decoder *Decoder) ReadFloat64() float64 {
+	return math.Float64frombits(uint64(decoder.ReadInt64()))
+}
+
+func (decoder *Decoder) ReadUvarint() uint64 {
+	if decoder.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(decoder.data[decoder.position:])
+	if n == 0 {
+		decoder.fail(ErrUnexpectedEnd)
+		return 0
+	}
+	if n < 0 {
+		decoder.fail(ErrInvalidVarint)
+		return 0
+	}
+	decoder.position += n
+	return value
+}
+
+// ReadVarint reads a zigzag encoded VARINT or VARLONG.
+func (decoder *Decoder) ReadVarint() int64 {
+	if decoder.err != nil {
+		return 0
+	}
+	value, n := binary.Varint(decoder.data[decoder.position:])
+	if n == 0 {
+		decoder.fail(ErrUnexpectedEnd)
+		return 0
+	}
+	if n < 0 {
+		decoder.fail(ErrInvalidVarint)
+		return 0
+	}
+	decoder.position += n
+	return value
+}
+
+func (decoder *Decoder) ReadUuid() uuid.UUID {
+	var id uuid.UUID
+	copy(id[:], decoder.next(16))
+	return id
+}
+
+// readLength reads the length of a string, bytes or array field, -1 standing
+// for null. Compact lengths are stored as N+1 so that 0 is null.
+func (decoder *Decoder) readLength(classicBits int) int {
+	var length int64
+	switch {
+	case decoder.Flexible:
+		length = int64(decoder.ReadUvarint()) - 1
+	case classicBits == 16:
+		length = int64(decoder.ReadInt16())
+	default:
+		length = int64(decoder.ReadInt32())
+	}
+	if decoder.err != nil {
+		return 0
+	}
+	if length < -1 {
+		decoder.fail(ErrInvalidLength)
+		return 0
+	}
+	// every element takes at least a byte, longer can't be right
+	if length > int64(decoder.Remaining()) {
+		decoder.fail(ErrUnexpectedEnd)
+		return 0
+	}
+	return int(length)
+}
+
+// ReadString reads a STRING, or a COMPACT_STRING when flexible.
+func (decoder *Decoder) ReadString() string {
+	length := decoder.readLength(16)
+	if length < 0 {
+		decoder.fail(ErrInvalidLength)
+		return ""
+	}
+	return string(decoder.next(length))
+}
+
+// ReadNullableString reads a NULLABLE_STRING, or a COMPACT_NULLABLE_STRING
+// when flexible. ok is false for null.
+func (decoder *Decoder) ReadNullableString() (value string, ok bool) {
+	length := decoder.readLength(16)
+	if length < 0 {
+		return "", false
+	}
+	return string(decoder.next(length)), decoder.err == nil
+}
+
+// ReadBytes reads BYTES, or COMPACT_BYTES when flexible. The bytes are a copy.
+func (decoder *Decoder) ReadBytes() []byte {
+	length := decoder.readLength(32)
+	if length < 0 {
+		decoder.fail(ErrInvalidLength)
+		return nil
+	}
+	return slices.Clone(decoder.next(length))
+}
+
+// ReadNullableBytes reads NULLABLE_BYTES, or COMPACT_NULLABLE_BYTES when
+// flexible. null is returned as nil, and empty bytes as an empty slice.
+func (decoder *Decoder) ReadNullableBytes() []byte {
+	length := decoder.readLength(32)
+	if length < 0 {
+		return nil
+	}
+	data := decoder.next(length)
+	if data == nil {
+		return nil
+	}
+	return slices.Clone(data)
+}
+
+// ReadArrayLength reads the length of an ARRAY, or a COMPACT_ARRAY when
+// flexible. A null array has no elements.
+func (decoder *Decoder) ReadArrayLength() int {
+	return max(decoder.readLength(32), 0)
+}
+
+// ReadNullableArrayLength is ReadArrayLength for arrays where null means
+// something else than empty, it is returned as -1.
+func (decoder *Decoder) ReadNullableArrayLength() int {
+	return decoder.readLength(32)
+}
+
+func (decoder *Decoder) ReadInt32Array() []int32 {
+	length := decoder.ReadArrayLength()
+	array := make([]int32, 0, length)
+	for i := 0; i < length && decoder.err == nil; i++ {
+		array = append(array, decoder.ReadInt32())
+	}
+	return array
+}
+
+// ReadTaggedFields reads a tagged field section and returns the raw value of
+// every field by tag. Messages of versions that aren't flexible have none.
+func (decoder *Decoder) ReadTaggedFields() map[uint64][]byte {
+	if !decoder.Flexible {
+		return nil
+	}
+
+	count := decoder.ReadUvarint()
+	if count > uint64(decoder.Remaining()) {
+		decoder.fail(ErrInvalidLength)
+		return nil
+	}
+	taggedFields := make(map[uint64][]byte, count)
+	previousTag := int64(-1)
+	for i := uint64(0); i < count && decoder.err == nil; i++ {
+		tag := decoder.ReadUvarint()
+		// tags are sorted and unique
+		if int64(tag) <= previousTag {
+			decoder.fail(fmt.Errorf("tagged field %d out of order", tag))
+			return nil
+		}
+		previousTag = int64(tag)
+
+		size := decoder.ReadUvarint()
+		if size > uint64(decoder.Remaining()) {
+			decoder.fail(ErrUnexpectedEnd)
+			return nil
+		}
+		taggedFields[tag] = slices.Clone(decoder.next(int(size)))
+	}
+	return taggedFields
+}
+
+// ReadRaw reads the next n bytes as they are.
+func (decoder *Decoder) ReadRaw(n int) []byte {
+	return slices.Clone(decoder.next(n))
+}
@@ -0,0 +1,150 @@
+// Code generated by gen from schema/DeleteTopicsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DeleteTopicsRequest is the request of API key 20, versions 0 to 6.
+type DeleteTopicsRequest struct {
+	// The name or topic ID of the topic
+	Topics []*DeleteTopicsRequestDeleteTopicState
+	// The names of the topics to delete
+	TopicNames []string
+	// The length of time in milliseconds to wait for the deletions to complete.
+	TimeoutMs int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *DeleteTopicsRequest) ApiKey() int16 {
+	return 20
+}
+
+func (v *DeleteTopicsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *DeleteTopicsRequest) MaxVersion() int16 {
+	return 6
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *DeleteTopicsRequest) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of DeleteTopicsRequest from decoder.
+func (v *DeleteTopicsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 6 {
+		return fmt.Errorf("DeleteTopicsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of DeleteTopicsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *DeleteTopicsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 6 {
+		encoder.fail(fmt.Errorf("DeleteTopicsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DeleteTopicsRequest) Default() {
+	*v = DeleteTopicsRequest{}
+}
+
+func (v *DeleteTopicsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 6 {
+		topicsLength := decoder.ReadArrayLength()
+		v.Topics = make([]*DeleteTopicsRequestDeleteTopicState, topicsLength)
+		for i := range v.Topics {
+			v.Topics[i] = &DeleteTopicsRequestDeleteTopicState{}
+			v.Topics[i].decode(decoder, version)
+		}
+	}
+	if version <= 5 {
+		topicNamesLength := decoder.ReadArrayLength()
+		v.TopicNames = make([]string, topicNamesLength)
+		for i := range v.TopicNames {
+			v.TopicNames[i] = decoder.ReadString()
+		}
+	}
+	v.TimeoutMs = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DeleteTopicsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DeleteTopicsRequest{}
+		v.Default()
+	}
+	if version >= 6 {
+		encoder.WriteArrayLength(len(v.Topics))
+		for _, element := range v.Topics {
+			element.encode(encoder, version)
+		}
+	}
+	if version <= 5 {
+		encoder.WriteArrayLength(len(v.TopicNames))
+		for _, element := range v.TopicNames {
+			encoder.WriteString(element)
+		}
+	}
+	encoder.WriteInt32(v.TimeoutMs)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DeleteTopicsRequestDeleteTopicState is a struct of DeleteTopicsRequest.
+type DeleteTopicsRequestDeleteTopicState struct {
+	// The topic name
+	Name string
+	// The unique topic ID
+	TopicId uuid.UUID
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DeleteTopicsRequestDeleteTopicState) Default() {
+	*v = DeleteTopicsRequestDeleteTopicState{}
+}
+
+func (v *DeleteTopicsRequestDeleteTopicState) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 6 {
+		v.Name, _ = decoder.ReadNullableString()
+	}
+	if version >= 6 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DeleteTopicsRequestDeleteTopicState) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DeleteTopicsRequestDeleteTopicState{}
+		v.Default()
+	}
+	if version >= 6 {
+		encoder.WriteNullableString(v.Name)
+	}
+	if version >= 6 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,151 @@
+// Code generated by gen from schema/DeleteTopicsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DeleteTopicsResponse is the response of API key 20, versions 0 to 6.
+type DeleteTopicsResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The results for each topic we tried to delete.
+	Responses []*DeleteTopicsResponseDeletableTopicResult
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *DeleteTopicsResponse) ApiKey() int16 {
+	return 20
+}
+
+func (v *DeleteTopicsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *DeleteTopicsResponse) MaxVersion() int16 {
+	return 6
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *DeleteTopicsResponse) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of DeleteTopicsResponse from decoder.
+func (v *DeleteTopicsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 6 {
+		return fmt.Errorf("DeleteTopicsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of DeleteTopicsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *DeleteTopicsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 6 {
+		encoder.fail(fmt.Errorf("DeleteTopicsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DeleteTopicsResponse) Default() {
+	*v = DeleteTopicsResponse{}
+}
+
+func (v *DeleteTopicsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	responsesLength := decoder.ReadArrayLength()
+	v.Responses = make([]*DeleteTopicsResponseDeletableTopicResult, responsesLength)
+	for i := range v.Responses {
+		v.Responses[i] = &DeleteTopicsResponseDeletableTopicResult{}
+		v.Responses[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DeleteTopicsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DeleteTopicsResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Responses))
+	for _, element := range v.Responses {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DeleteTopicsResponseDeletableTopicResult is a struct of DeleteTopicsResponse.
+type DeleteTopicsResponseDeletableTopicResult struct {
+	// The topic name
+	Name string
+	// the unique topic ID
+	TopicId uuid.UUID
+	// The deletion error, or 0 if the deletion succeeded.
+	ErrorCode int16
+	// The error message, or null if there is no error.
+	ErrorMessage string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DeleteTopicsResponseDeletableTopicResult) Default() {
+	*v = DeleteTopicsResponseDeletableTopicResult{}
+}
+
+func (v *DeleteTopicsResponseDeletableTopicResult) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 6 {
+		v.Name, _ = decoder.ReadNullableString()
+	} else {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 6 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	if version >= 5 {
+		v.ErrorMessage, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DeleteTopicsResponseDeletableTopicResult) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DeleteTopicsResponseDeletableTopicResult{}
+		v.Default()
+	}
+	if version >= 6 {
+		encoder.WriteNullableString(v.Name)
+	} else {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 6 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	if version >= 5 {
+		encoder.WriteNullableString(v.ErrorMessage)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,164 @@
+// Code generated by gen from schema/DescribeTopicPartitionsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// DescribeTopicPartitionsRequest is the request of API key 75, versions 0 to 0.
+type DescribeTopicPartitionsRequest struct {
+	// The topics to fetch details for.
+	Topics []*DescribeTopicPartitionsRequestTopicRequest
+	// The maximum number of partitions included in the response.
+	ResponsePartitionLimit int32
+	// The first topic and partition index to fetch details for.
+	Cursor *DescribeTopicPartitionsRequestCursor
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *DescribeTopicPartitionsRequest) ApiKey() int16 {
+	return 75
+}
+
+func (v *DescribeTopicPartitionsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *DescribeTopicPartitionsRequest) MaxVersion() int16 {
+	return 0
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *DescribeTopicPartitionsRequest) IsFlexible(version int16) bool {
+	return true
+}
+
+// Decode reads version of DescribeTopicPartitionsRequest from decoder.
+func (v *DescribeTopicPartitionsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 0 {
+		return fmt.Errorf("DescribeTopicPartitionsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of DescribeTopicPartitionsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *DescribeTopicPartitionsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 0 {
+		encoder.fail(fmt.Errorf("DescribeTopicPartitionsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsRequest) Default() {
+	*v = DescribeTopicPartitionsRequest{}
+	v.ResponsePartitionLimit = 2000
+}
+
+func (v *DescribeTopicPartitionsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*DescribeTopicPartitionsRequestTopicRequest, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &DescribeTopicPartitionsRequestTopicRequest{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.ResponsePartitionLimit = decoder.ReadInt32()
+	if decoder.ReadInt8() >= 0 {
+		v.Cursor = &DescribeTopicPartitionsRequestCursor{}
+		v.Cursor.decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsRequest{}
+		v.Default()
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteInt32(v.ResponsePartitionLimit)
+	if v.Cursor == nil {
+		encoder.WriteInt8(-1)
+	} else {
+		encoder.WriteInt8(1)
+		v.Cursor.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DescribeTopicPartitionsRequestTopicRequest is a struct of DescribeTopicPartitionsRequest.
+type DescribeTopicPartitionsRequestTopicRequest struct {
+	// The topic name
+	Name string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsRequestTopicRequest) Default() {
+	*v = DescribeTopicPartitionsRequestTopicRequest{}
+}
+
+func (v *DescribeTopicPartitionsRequestTopicRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsRequestTopicRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsRequestTopicRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DescribeTopicPartitionsRequestCursor is a struct of DescribeTopicPartitionsRequest.
+type DescribeTopicPartitionsRequestCursor struct {
+	// The name for the first topic to process
+	TopicName string
+	// The partition index to start with
+	PartitionIndex int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsRequestCursor) Default() {
+	*v = DescribeTopicPartitionsRequestCursor{}
+}
+
+func (v *DescribeTopicPartitionsRequestCursor) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.TopicName = decoder.ReadString()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsRequestCursor) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsRequestCursor{}
+		v.Default()
+	}
+	encoder.WriteString(v.TopicName)
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
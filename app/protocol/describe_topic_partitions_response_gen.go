@@ -0,0 +1,304 @@
+// Code generated by gen from schema/DescribeTopicPartitionsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// DescribeTopicPartitionsResponse is the response of API key 75, versions 0 to 0.
+type DescribeTopicPartitionsResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// Each topic in the response.
+	Topics []*DescribeTopicPartitionsResponseTopic
+	// The next topic and partition index to fetch details for.
+	NextCursor *DescribeTopicPartitionsResponseCursor
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *DescribeTopicPartitionsResponse) ApiKey() int16 {
+	return 75
+}
+
+func (v *DescribeTopicPartitionsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *DescribeTopicPartitionsResponse) MaxVersion() int16 {
+	return 0
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *DescribeTopicPartitionsResponse) IsFlexible(version int16) bool {
+	return true
+}
+
+// Decode reads version of DescribeTopicPartitionsResponse from decoder.
+func (v *DescribeTopicPartitionsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 0 {
+		return fmt.Errorf("DescribeTopicPartitionsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of DescribeTopicPartitionsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *DescribeTopicPartitionsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 0 {
+		encoder.fail(fmt.Errorf("DescribeTopicPartitionsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsResponse) Default() {
+	*v = DescribeTopicPartitionsResponse{}
+}
+
+func (v *DescribeTopicPartitionsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ThrottleTimeMs = decoder.ReadInt32()
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*DescribeTopicPartitionsResponseTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &DescribeTopicPartitionsResponseTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	if decoder.ReadInt8() >= 0 {
+		v.NextCursor = &DescribeTopicPartitionsResponseCursor{}
+		v.NextCursor.decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsResponse{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.ThrottleTimeMs)
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	if v.NextCursor == nil {
+		encoder.WriteInt8(-1)
+	} else {
+		encoder.WriteInt8(1)
+		v.NextCursor.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DescribeTopicPartitionsResponseTopic is a struct of DescribeTopicPartitionsResponse.
+type DescribeTopicPartitionsResponseTopic struct {
+	// The topic error, or 0 if there was no error.
+	ErrorCode int16
+	// The topic name.
+	Name string
+	// The topic id.
+	TopicId uuid.UUID
+	// True if the topic is internal.
+	IsInternal bool
+	// Each partition in the topic.
+	Partitions []*DescribeTopicPartitionsResponsePartition
+	// 32-bit bitfield to represent authorized operations for this topic.
+	TopicAuthorizedOperations int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsResponseTopic) Default() {
+	*v = DescribeTopicPartitionsResponseTopic{}
+	v.TopicAuthorizedOperations = -2147483648
+}
+
+func (v *DescribeTopicPartitionsResponseTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ErrorCode = decoder.ReadInt16()
+	v.Name, _ = decoder.ReadNullableString()
+	v.TopicId = decoder.ReadUuid()
+	v.IsInternal = decoder.ReadBool()
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*DescribeTopicPartitionsResponsePartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &DescribeTopicPartitionsResponsePartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.TopicAuthorizedOperations = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsResponseTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsResponseTopic{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteNullableString(v.Name)
+	encoder.WriteUuid(v.TopicId)
+	encoder.WriteBool(v.IsInternal)
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteInt32(v.TopicAuthorizedOperations)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DescribeTopicPartitionsResponsePartition is a struct of DescribeTopicPartitionsResponseTopic.
+type DescribeTopicPartitionsResponsePartition struct {
+	// The partition error, or 0 if there was no error.
+	ErrorCode int16
+	// The partition index.
+	PartitionIndex int32
+	// The ID of the leader broker.
+	LeaderId int32
+	// The leader epoch of this partition.
+	LeaderEpoch int32
+	// The set of all nodes that host this partition.
+	ReplicaNodes []int32
+	// The set of nodes that are in sync with the leader for this partition.
+	IsrNodes []int32
+	// The new eligible leader replicas otherwise.
+	EligibleLeaderReplicas []int32
+	// The last known ELR.
+	LastKnownElr []int32
+	// The set of offline replicas of this partition.
+	OfflineReplicas []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsResponsePartition) Default() {
+	*v = DescribeTopicPartitionsResponsePartition{}
+	v.LeaderEpoch = -1
+}
+
+func (v *DescribeTopicPartitionsResponsePartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ErrorCode = decoder.ReadInt16()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.LeaderId = decoder.ReadInt32()
+	v.LeaderEpoch = decoder.ReadInt32()
+	replicaNodesLength := decoder.ReadArrayLength()
+	v.ReplicaNodes = make([]int32, replicaNodesLength)
+	for i := range v.ReplicaNodes {
+		v.ReplicaNodes[i] = decoder.ReadInt32()
+	}
+	isrNodesLength := decoder.ReadArrayLength()
+	v.IsrNodes = make([]int32, isrNodesLength)
+	for i := range v.IsrNodes {
+		v.IsrNodes[i] = decoder.ReadInt32()
+	}
+	eligibleLeaderReplicasLength := decoder.ReadNullableArrayLength()
+	if eligibleLeaderReplicasLength >= 0 {
+		v.EligibleLeaderReplicas = make([]int32, eligibleLeaderReplicasLength)
+		for i := range v.EligibleLeaderReplicas {
+			v.EligibleLeaderReplicas[i] = decoder.ReadInt32()
+		}
+	}
+	lastKnownElrLength := decoder.ReadNullableArrayLength()
+	if lastKnownElrLength >= 0 {
+		v.LastKnownElr = make([]int32, lastKnownElrLength)
+		for i := range v.LastKnownElr {
+			v.LastKnownElr[i] = decoder.ReadInt32()
+		}
+	}
+	offlineReplicasLength := decoder.ReadArrayLength()
+	v.OfflineReplicas = make([]int32, offlineReplicasLength)
+	for i := range v.OfflineReplicas {
+		v.OfflineReplicas[i] = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsResponsePartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsResponsePartition{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt32(v.LeaderId)
+	encoder.WriteInt32(v.LeaderEpoch)
+	encoder.WriteArrayLength(len(v.ReplicaNodes))
+	for _, element := range v.ReplicaNodes {
+		encoder.WriteInt32(element)
+	}
+	encoder.WriteArrayLength(len(v.IsrNodes))
+	for _, element := range v.IsrNodes {
+		encoder.WriteInt32(element)
+	}
+	if v.EligibleLeaderReplicas == nil {
+		encoder.WriteArrayLength(-1)
+	} else {
+		encoder.WriteArrayLength(len(v.EligibleLeaderReplicas))
+		for _, element := range v.EligibleLeaderReplicas {
+			encoder.WriteInt32(element)
+		}
+	}
+	if v.LastKnownElr == nil {
+		encoder.WriteArrayLength(-1)
+	} else {
+		encoder.WriteArrayLength(len(v.LastKnownElr))
+		for _, element := range v.LastKnownElr {
+			encoder.WriteInt32(element)
+		}
+	}
+	encoder.WriteArrayLength(len(v.OfflineReplicas))
+	for _, element := range v.OfflineReplicas {
+		encoder.WriteInt32(element)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// DescribeTopicPartitionsResponseCursor is a struct of DescribeTopicPartitionsResponse.
+type DescribeTopicPartitionsResponseCursor struct {
+	// The name for the first topic to process
+	TopicName string
+	// The partition index to start with
+	PartitionIndex int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *DescribeTopicPartitionsResponseCursor) Default() {
+	*v = DescribeTopicPartitionsResponseCursor{}
+}
+
+func (v *DescribeTopicPartitionsResponseCursor) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.TopicName = decoder.ReadString()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *DescribeTopicPartitionsResponseCursor) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &DescribeTopicPartitionsResponseCursor{}
+		v.Default()
+	}
+	encoder.WriteString(v.TopicName)
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,185 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+var ErrTooLong = errors.New("value too long for its length field")
+
+// Encoder writes the fields of a message in order. Like Decoder, the first
+// error sticks and is reported by Err.
+type Encoder struct {
+	// Flexible selects the compact strings, bytes and arrays and the tagged
+	// fields of flexible API versions.
+	Flexible bool
+
+	data []byte
+	err  error
+}
+
+func NewEncoder(flexible bool) *Encoder {
+	return &Encoder{Flexible: flexible}
+}
+
+// Err returns the first error hit while encoding.
+func (encoder *Encoder) Err() error {
+	return encoder.err
+}
+
+// Bytes returns the encoded message.
+func (encoder *Encoder) Bytes() []byte {
+	return encoder.data
+}
+
+func (encoder *Encoder) Len() int {
+	return len(encoder.data)
+}
+
+func (encoder *Encoder) WriteInt8(value int8) {
+	encoder.data = append(encoder.data, byte(value))
+}
+
+func (encoder *Encoder) WriteBool(value bool) {
+	if value {
+		encoder.WriteInt8(1)
+	} else {
+		encoder.WriteInt8(0)
+	}
+}
+
+func (encoder *Encoder) WriteInt16(value int16) {
+	encoder.data = binary.BigEndian.AppendUint16(encoder.data, uint16(value))
+}
+
+func (encoder *Encoder) WriteUint16(value uint16) {
+	encoder.data = binary.BigEndian.AppendUint16(encoder.data, value)
+}
+
+func (encoder *Encoder) WriteInt32(value int32) {
+	encoder.data = binary.BigEndian.AppendUint32(encoder.data, uint32(value))
+}
+
+func (encoder *Encoder) WriteUint32(value uint32) {
+	encoder.data = binary.BigEndian.AppendUint32(encoder.data, value)
+}
+
+func (encoder *Encoder) WriteInt64(value int64) {
+	encoder.data = binary.BigEndian.AppendUint64(encoder.data, uint64(value))
+}
+
+func (encoder *Encoder) WriteFloat64(value float64) {
+	encoder.WriteInt64(int64(math.Float64bits(value)))
+}
+
+func (encoder *Encoder) WriteUvarint(value uint64) {
+	encoder.data = binary.AppendUvarint(encoder.data, value)
+}
+
+// WriteVarint writes a zigzag encoded VARINT or VARLONG.
+func (encoder *Encoder) WriteVarint(value int64) {
+	encoder.data = binary.AppendVarint(encoder.data, value)
+}
+
+func (encoder *Encoder) WriteUuid(id uuid.UUID) {
+	encoder.data = append(encoder.data, id[:]...)
+}
+
+// writeLength writes the length of a string, bytes or array field, -1 for
+// null, as N+1 when flexible.
+func (encoder *Encoder) writeLength(length int, classicBits int) {
+	switch {
+	case encoder.Flexible:
+		encoder.WriteUvarint(uint64(length + 1))
+	case classicBits == 16:
+		if length > math.MaxInt16 {
+			encoder.fail(ErrTooLong)
+		}
+		encoder.WriteInt16(int16(length))
+	default:
+		if length > math.MaxInt32 {
+			encoder.fail(ErrTooLong)
+		}
+		encoder.WriteInt32(int32(length))
+	}
+}
+
+func (encoder *Encoder) fail(err error) {
+	if encoder.err == nil {
+		encoder.err = err
+	}
+}
+
+// WriteString writes a STRING, or a COMPACT_STRING when flexible.
+func (encoder *Encoder) WriteString(value string) {
+	encoder.writeLength(len(value), 16)
+	encoder.data = append(encoder.data, value...)
+}
+
+// WriteNullableString writes a NULLABLE_STRING, or a COMPACT_NULLABLE_STRING
+// when flexible. The empty string is written as null.
+func (encoder *Encoder) WriteNullableString(value string) {
+	if value == "" {
+		encoder.writeLength(-1, 16)
+		return
+	}
+	encoder.WriteString(value)
+}
+
+// WriteBytes writes BYTES, or COMPACT_BYTES when flexible.
+func (encoder *Encoder) WriteBytes(value []byte) {
+	encoder.writeLength(len(value), 32)
+	encoder.data = append(encoder.data, value...)
+}
+
+// WriteNullableBytes writes NULLABLE_BYTES, or COMPACT_NULLABLE_BYTES when
+// flexible, nil as null.
+func (encoder *Encoder) WriteNullableBytes(value []byte) {
+	if value == nil {
+		encoder.writeLength(-1, 32)
+		return
+	}
+	encoder.WriteBytes(value)
+}
+
+// WriteArrayLength writes the length of an ARRAY, or a COMPACT_ARRAY when
+// flexible. -1 is a null array.
+func (encoder *Encoder) WriteArrayLength(length int) {
+	encoder.writeLength(length, 32)
+}
+
+func (encoder *Encoder) WriteInt32Array(array []int32) {
+	encoder.WriteArrayLength(len(array))
+	for _, value := range array {
+		encoder.WriteInt32(value)
+	}
+}
+
+// WriteTaggedFields writes a tagged field section with the raw values of the
+// given fields, sorted by tag. Versions that aren't flexible have none.
+func (encoder *Encoder) WriteTaggedFields(taggedFields map[uint64][]byte) {
+	if !encoder.Flexible {
+		return
+	}
+
+	encoder.WriteUvarint(uint64(len(taggedFields)))
+	tags := make([]uint64, 0, len(taggedFields))
+	for tag := range taggedFields {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	for _, tag := range tags {
+		encoder.WriteUvarint(tag)
+		encoder.WriteUvarint(uint64(len(taggedFields[tag])))
+		encoder.data = append(encoder.data, taggedFields[tag]...)
+	}
+}
+
+// WriteRaw writes bytes as they are.
+func (encoder *Encoder) WriteRaw(data []byte) {
+	encoder.data = append(encoder.data, data...)
+}
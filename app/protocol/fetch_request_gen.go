@@ -0,0 +1,417 @@
+// Code generated by gen from schema/FetchRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// FetchRequest is the request of API key 1, versions 0 to 16.
+type FetchRequest struct {
+	// The clusterId if known. This is used to validate metadata fetches prior to broker registration.
+	ClusterId string
+	// The broker ID of the follower, of -1 if this request is from a consumer.
+	ReplicaId    int32
+	ReplicaState *FetchRequestReplicaState
+	// The maximum time in milliseconds to wait for the response.
+	MaxWaitMs int32
+	// The minimum bytes to accumulate in the response.
+	MinBytes int32
+	// The maximum bytes to fetch.  See KIP-74 for cases where this limit may not be honored.
+	MaxBytes int32
+	// This setting controls the visibility of transactional records. Using READ_UNCOMMITTED (isolation_level = 0) makes all records visible. With READ_COMMITTED (isolation_level = 1), non-transactional and COMMITTED transactional records are visible. To be more concrete, READ_COMMITTED returns all data from offsets smaller than the current LSO (last stable offset), and enables the inclusion of the list of aborted transactions in the result, which allows consumers to discard ABORTED transactional records
+	IsolationLevel int8
+	// The fetch session ID.
+	SessionId int32
+	// The fetch session epoch, which is used for ordering requests in a session.
+	SessionEpoch int32
+	// The topics to fetch.
+	Topics []*FetchRequestFetchTopic
+	// In an incremental fetch request, the partitions to remove.
+	ForgottenTopicsData []*FetchRequestForgottenTopic
+	// Rack ID of the consumer making this request
+	RackId string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *FetchRequest) ApiKey() int16 {
+	return 1
+}
+
+func (v *FetchRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *FetchRequest) MaxVersion() int16 {
+	return 16
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *FetchRequest) IsFlexible(version int16) bool {
+	return version >= 12
+}
+
+// Decode reads version of FetchRequest from decoder.
+func (v *FetchRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 16 {
+		return fmt.Errorf("FetchRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of FetchRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *FetchRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 16 {
+		encoder.fail(fmt.Errorf("FetchRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchRequest) Default() {
+	*v = FetchRequest{}
+	v.ReplicaId = -1
+	v.MaxBytes = 0x7fffffff
+	v.SessionEpoch = -1
+}
+
+func (v *FetchRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 14 {
+		v.ReplicaId = decoder.ReadInt32()
+	}
+	v.MaxWaitMs = decoder.ReadInt32()
+	v.MinBytes = decoder.ReadInt32()
+	if version >= 3 {
+		v.MaxBytes = decoder.ReadInt32()
+	}
+	if version >= 4 {
+		v.IsolationLevel = decoder.ReadInt8()
+	}
+	if version >= 7 {
+		v.SessionId = decoder.ReadInt32()
+	}
+	if version >= 7 {
+		v.SessionEpoch = decoder.ReadInt32()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*FetchRequestFetchTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &FetchRequestFetchTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	if version >= 7 {
+		forgottenTopicsDataLength := decoder.ReadArrayLength()
+		v.ForgottenTopicsData = make([]*FetchRequestForgottenTopic, forgottenTopicsDataLength)
+		for i := range v.ForgottenTopicsData {
+			v.ForgottenTopicsData[i] = &FetchRequestForgottenTopic{}
+			v.ForgottenTopicsData[i].decode(decoder, version)
+		}
+	}
+	if version >= 11 {
+		v.RackId = decoder.ReadString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 12 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		v.ClusterId, _ = tagged.ReadNullableString()
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[1]; ok && version >= 15 {
+		delete(v.UnknownTaggedFields, 1)
+		tagged := NewDecoder(data, true)
+		v.ReplicaState = &FetchRequestReplicaState{}
+		v.ReplicaState.decode(tagged, version)
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 1: %w", err))
+		}
+	}
+}
+
+func (v *FetchRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchRequest{}
+		v.Default()
+	}
+	if version <= 14 {
+		encoder.WriteInt32(v.ReplicaId)
+	}
+	encoder.WriteInt32(v.MaxWaitMs)
+	encoder.WriteInt32(v.MinBytes)
+	if version >= 3 {
+		encoder.WriteInt32(v.MaxBytes)
+	}
+	if version >= 4 {
+		encoder.WriteInt8(v.IsolationLevel)
+	}
+	if version >= 7 {
+		encoder.WriteInt32(v.SessionId)
+	}
+	if version >= 7 {
+		encoder.WriteInt32(v.SessionEpoch)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	if version >= 7 {
+		encoder.WriteArrayLength(len(v.ForgottenTopicsData))
+		for _, element := range v.ForgottenTopicsData {
+			element.encode(encoder, version)
+		}
+	}
+	if version >= 11 {
+		encoder.WriteString(v.RackId)
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 12 && v.ClusterId != "" {
+		tagged := NewEncoder(true)
+		tagged.WriteNullableString(v.ClusterId)
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	if version >= 15 && v.ReplicaState != nil {
+		tagged := NewEncoder(true)
+		v.ReplicaState.encode(tagged, version)
+		encoder.fail(tagged.Err())
+		taggedFields[1] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// FetchRequestReplicaState is a struct of FetchRequest.
+type FetchRequestReplicaState struct {
+	// The replica ID of the follower, or -1 if this request is from a consumer.
+	ReplicaId int32
+	// The epoch of this follower, or -1 if not available.
+	ReplicaEpoch int64
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchRequestReplicaState) Default() {
+	*v = FetchRequestReplicaState{}
+	v.ReplicaId = -1
+	v.ReplicaEpoch = -1
+}
+
+func (v *FetchRequestReplicaState) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 15 {
+		v.ReplicaId = decoder.ReadInt32()
+	}
+	if version >= 15 {
+		v.ReplicaEpoch = decoder.ReadInt64()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchRequestReplicaState) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchRequestReplicaState{}
+		v.Default()
+	}
+	if version >= 15 {
+		encoder.WriteInt32(v.ReplicaId)
+	}
+	if version >= 15 {
+		encoder.WriteInt64(v.ReplicaEpoch)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchRequestFetchTopic is a struct of FetchRequest.
+type FetchRequestFetchTopic struct {
+	// The name of the topic to fetch.
+	Topic string
+	// The unique topic ID
+	TopicId uuid.UUID
+	// The partitions to fetch.
+	Partitions []*FetchRequestFetchPartition
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchRequestFetchTopic) Default() {
+	*v = FetchRequestFetchTopic{}
+}
+
+func (v *FetchRequestFetchTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 12 {
+		v.Topic = decoder.ReadString()
+	}
+	if version >= 13 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*FetchRequestFetchPartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &FetchRequestFetchPartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchRequestFetchTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchRequestFetchTopic{}
+		v.Default()
+	}
+	if version <= 12 {
+		encoder.WriteString(v.Topic)
+	}
+	if version >= 13 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchRequestFetchPartition is a struct of FetchRequestFetchTopic.
+type FetchRequestFetchPartition struct {
+	// The partition index.
+	Partition int32
+	// The current leader epoch of the partition.
+	CurrentLeaderEpoch int32
+	// The message offset.
+	FetchOffset int64
+	// The epoch of the last fetched record or -1 if there is none
+	LastFetchedEpoch int32
+	// The earliest available offset of the follower replica.  The field is only used when the request is sent by the follower.
+	LogStartOffset int64
+	// The maximum bytes to fetch from this partition.  See KIP-74 for cases where this limit may not be honored.
+	PartitionMaxBytes int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchRequestFetchPartition) Default() {
+	*v = FetchRequestFetchPartition{}
+	v.CurrentLeaderEpoch = -1
+	v.LastFetchedEpoch = -1
+	v.LogStartOffset = -1
+}
+
+func (v *FetchRequestFetchPartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Partition = decoder.ReadInt32()
+	if version >= 9 {
+		v.CurrentLeaderEpoch = decoder.ReadInt32()
+	}
+	v.FetchOffset = decoder.ReadInt64()
+	if version >= 12 {
+		v.LastFetchedEpoch = decoder.ReadInt32()
+	}
+	if version >= 5 {
+		v.LogStartOffset = decoder.ReadInt64()
+	}
+	v.PartitionMaxBytes = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchRequestFetchPartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchRequestFetchPartition{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.Partition)
+	if version >= 9 {
+		encoder.WriteInt32(v.CurrentLeaderEpoch)
+	}
+	encoder.WriteInt64(v.FetchOffset)
+	if version >= 12 {
+		encoder.WriteInt32(v.LastFetchedEpoch)
+	}
+	if version >= 5 {
+		encoder.WriteInt64(v.LogStartOffset)
+	}
+	encoder.WriteInt32(v.PartitionMaxBytes)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchRequestForgottenTopic is a struct of FetchRequest.
+type FetchRequestForgottenTopic struct {
+	// The topic name.
+	Topic string
+	// The unique topic ID
+	TopicId uuid.UUID
+	// The partitions indexes to forget.
+	Partitions []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchRequestForgottenTopic) Default() {
+	*v = FetchRequestForgottenTopic{}
+}
+
+func (v *FetchRequestForgottenTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 7 && version <= 12 {
+		v.Topic = decoder.ReadString()
+	}
+	if version >= 13 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	if version >= 7 {
+		partitionsLength := decoder.ReadArrayLength()
+		v.Partitions = make([]int32, partitionsLength)
+		for i := range v.Partitions {
+			v.Partitions[i] = decoder.ReadInt32()
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchRequestForgottenTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchRequestForgottenTopic{}
+		v.Default()
+	}
+	if version >= 7 && version <= 12 {
+		encoder.WriteString(v.Topic)
+	}
+	if version >= 13 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	if version >= 7 {
+		encoder.WriteArrayLength(len(v.Partitions))
+		for _, element := range v.Partitions {
+			encoder.WriteInt32(element)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,557 @@
+// Code generated by gen from schema/FetchResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/google/uuid"
+)
+
+// FetchResponse is the response of API key 1, versions 0 to 16.
+type FetchResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The top level response error code.
+	ErrorCode int16
+	// The fetch session ID, or 0 if this is not part of a fetch session.
+	SessionId int32
+	// The response topics.
+	Responses []*FetchResponseFetchableTopicResponse
+	// Endpoints for all current-leaders enumerated in PartitionData, with errors NOT_LEADER_OR_FOLLOWER & FENCED_LEADER_EPOCH.
+	NodeEndpoints []*FetchResponseNodeEndpoint
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *FetchResponse) ApiKey() int16 {
+	return 1
+}
+
+func (v *FetchResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *FetchResponse) MaxVersion() int16 {
+	return 16
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *FetchResponse) IsFlexible(version int16) bool {
+	return version >= 12
+}
+
+// Decode reads version of FetchResponse from decoder.
+func (v *FetchResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 16 {
+		return fmt.Errorf("FetchResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of FetchResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *FetchResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 16 {
+		encoder.fail(fmt.Errorf("FetchResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponse) Default() {
+	*v = FetchResponse{}
+}
+
+func (v *FetchResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	if version >= 7 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	if version >= 7 {
+		v.SessionId = decoder.ReadInt32()
+	}
+	responsesLength := decoder.ReadArrayLength()
+	v.Responses = make([]*FetchResponseFetchableTopicResponse, responsesLength)
+	for i := range v.Responses {
+		v.Responses[i] = &FetchResponseFetchableTopicResponse{}
+		v.Responses[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 16 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		nodeEndpointsLength := tagged.ReadArrayLength()
+		v.NodeEndpoints = make([]*FetchResponseNodeEndpoint, nodeEndpointsLength)
+		for i := range v.NodeEndpoints {
+			v.NodeEndpoints[i] = &FetchResponseNodeEndpoint{}
+			v.NodeEndpoints[i].decode(tagged, version)
+		}
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+}
+
+func (v *FetchResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	if version >= 7 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	if version >= 7 {
+		encoder.WriteInt32(v.SessionId)
+	}
+	encoder.WriteArrayLength(len(v.Responses))
+	for _, element := range v.Responses {
+		element.encode(encoder, version)
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 16 && len(v.NodeEndpoints) > 0 {
+		tagged := NewEncoder(true)
+		tagged.WriteArrayLength(len(v.NodeEndpoints))
+		for _, element := range v.NodeEndpoints {
+			element.encode(tagged, version)
+		}
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// FetchResponseFetchableTopicResponse is a struct of FetchResponse.
+type FetchResponseFetchableTopicResponse struct {
+	// The topic name.
+	Topic string
+	// The unique topic ID
+	TopicId uuid.UUID
+	// The topic partitions.
+	Partitions []*FetchResponsePartitionData
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseFetchableTopicResponse) Default() {
+	*v = FetchResponseFetchableTopicResponse{}
+}
+
+func (v *FetchResponseFetchableTopicResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 12 {
+		v.Topic = decoder.ReadString()
+	}
+	if version >= 13 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*FetchResponsePartitionData, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &FetchResponsePartitionData{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseFetchableTopicResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseFetchableTopicResponse{}
+		v.Default()
+	}
+	if version <= 12 {
+		encoder.WriteString(v.Topic)
+	}
+	if version >= 13 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchResponsePartitionData is a struct of FetchResponseFetchableTopicResponse.
+type FetchResponsePartitionData struct {
+	// The partition index.
+	PartitionIndex int32
+	// The error code, or 0 if there was no fetch error.
+	ErrorCode int16
+	// The current high water mark.
+	HighWatermark int64
+	// The last stable offset (or LSO) of the partition. This is the last offset such that the state of all transactional records prior to this offset have been decided (ABORTED or COMMITTED)
+	LastStableOffset int64
+	// The current log start offset.
+	LogStartOffset int64
+	// In case divergence is detected based on the `LastFetchedEpoch` and `FetchOffset` in the request, this field indicates the largest epoch and its end offset such that subsequent records are known to diverge
+	DivergingEpoch *FetchResponseEpochEndOffset
+	CurrentLeader  *FetchResponseLeaderIdAndEpoch
+	// In the case of fetching an offset less than the LogStartOffset, this is the end offset and epoch that should be used in the FetchSnapshot request.
+	SnapshotId *FetchResponseSnapshotId
+	// The aborted transactions.
+	AbortedTransactions []*FetchResponseAbortedTransaction
+	// The preferred read replica for the consumer to use on its next fetch request
+	PreferredReadReplica int32
+	// The record data.
+	Records []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponsePartitionData) Default() {
+	*v = FetchResponsePartitionData{}
+	v.LastStableOffset = -1
+	v.LogStartOffset = -1
+	v.PreferredReadReplica = -1
+}
+
+func (v *FetchResponsePartitionData) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.ErrorCode = decoder.ReadInt16()
+	v.HighWatermark = decoder.ReadInt64()
+	if version >= 4 {
+		v.LastStableOffset = decoder.ReadInt64()
+	}
+	if version >= 5 {
+		v.LogStartOffset = decoder.ReadInt64()
+	}
+	if version >= 4 {
+		abortedTransactionsLength := decoder.ReadNullableArrayLength()
+		if abortedTransactionsLength >= 0 {
+			v.AbortedTransactions = make([]*FetchResponseAbortedTransaction, abortedTransactionsLength)
+			for i := range v.AbortedTransactions {
+				v.AbortedTransactions[i] = &FetchResponseAbortedTransaction{}
+				v.AbortedTransactions[i].decode(decoder, version)
+			}
+		}
+	}
+	if version >= 11 {
+		v.PreferredReadReplica = decoder.ReadInt32()
+	}
+	v.Records = decoder.ReadNullableBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 12 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		v.DivergingEpoch = &FetchResponseEpochEndOffset{}
+		v.DivergingEpoch.decode(tagged, version)
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[1]; ok && version >= 12 {
+		delete(v.UnknownTaggedFields, 1)
+		tagged := NewDecoder(data, true)
+		v.CurrentLeader = &FetchResponseLeaderIdAndEpoch{}
+		v.CurrentLeader.decode(tagged, version)
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 1: %w", err))
+		}
+	}
+	if data, ok := v.UnknownTaggedFields[2]; ok && version >= 12 {
+		delete(v.UnknownTaggedFields, 2)
+		tagged := NewDecoder(data, true)
+		v.SnapshotId = &FetchResponseSnapshotId{}
+		v.SnapshotId.decode(tagged, version)
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 2: %w", err))
+		}
+	}
+}
+
+func (v *FetchResponsePartitionData) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponsePartitionData{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteInt64(v.HighWatermark)
+	if version >= 4 {
+		encoder.WriteInt64(v.LastStableOffset)
+	}
+	if version >= 5 {
+		encoder.WriteInt64(v.LogStartOffset)
+	}
+	if version >= 4 {
+		if v.AbortedTransactions == nil {
+			encoder.WriteArrayLength(-1)
+		} else {
+			encoder.WriteArrayLength(len(v.AbortedTransactions))
+			for _, element := range v.AbortedTransactions {
+				element.encode(encoder, version)
+			}
+		}
+	}
+	if version >= 11 {
+		encoder.WriteInt32(v.PreferredReadReplica)
+	}
+	encoder.WriteNullableBytes(v.Records)
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 12 && v.DivergingEpoch != nil {
+		tagged := NewEncoder(true)
+		v.DivergingEpoch.encode(tagged, version)
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	if version >= 12 && v.CurrentLeader != nil {
+		tagged := NewEncoder(true)
+		v.CurrentLeader.encode(tagged, version)
+		encoder.fail(tagged.Err())
+		taggedFields[1] = tagged.Bytes()
+	}
+	if version >= 12 && v.SnapshotId != nil {
+		tagged := NewEncoder(true)
+		v.SnapshotId.encode(tagged, version)
+		encoder.fail(tagged.Err())
+		taggedFields[2] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// FetchResponseEpochEndOffset is a struct of FetchResponsePartitionData.
+type FetchResponseEpochEndOffset struct {
+	Epoch     int32
+	EndOffset int64
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseEpochEndOffset) Default() {
+	*v = FetchResponseEpochEndOffset{}
+	v.Epoch = -1
+	v.EndOffset = -1
+}
+
+func (v *FetchResponseEpochEndOffset) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 12 {
+		v.Epoch = decoder.ReadInt32()
+	}
+	if version >= 12 {
+		v.EndOffset = decoder.ReadInt64()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseEpochEndOffset) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseEpochEndOffset{}
+		v.Default()
+	}
+	if version >= 12 {
+		encoder.WriteInt32(v.Epoch)
+	}
+	if version >= 12 {
+		encoder.WriteInt64(v.EndOffset)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchResponseLeaderIdAndEpoch is a struct of FetchResponsePartitionData.
+type FetchResponseLeaderIdAndEpoch struct {
+	// The ID of the current leader or -1 if the leader is unknown.
+	LeaderId int32
+	// The latest known leader epoch
+	LeaderEpoch int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseLeaderIdAndEpoch) Default() {
+	*v = FetchResponseLeaderIdAndEpoch{}
+	v.LeaderId = -1
+	v.LeaderEpoch = -1
+}
+
+func (v *FetchResponseLeaderIdAndEpoch) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 12 {
+		v.LeaderId = decoder.ReadInt32()
+	}
+	if version >= 12 {
+		v.LeaderEpoch = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseLeaderIdAndEpoch) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseLeaderIdAndEpoch{}
+		v.Default()
+	}
+	if version >= 12 {
+		encoder.WriteInt32(v.LeaderId)
+	}
+	if version >= 12 {
+		encoder.WriteInt32(v.LeaderEpoch)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchResponseSnapshotId is a struct of FetchResponsePartitionData.
+type FetchResponseSnapshotId struct {
+	EndOffset int64
+	Epoch     int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseSnapshotId) Default() {
+	*v = FetchResponseSnapshotId{}
+	v.EndOffset = -1
+	v.Epoch = -1
+}
+
+func (v *FetchResponseSnapshotId) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.EndOffset = decoder.ReadInt64()
+	v.Epoch = decoder.ReadInt32()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseSnapshotId) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseSnapshotId{}
+		v.Default()
+	}
+	encoder.WriteInt64(v.EndOffset)
+	encoder.WriteInt32(v.Epoch)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchResponseAbortedTransaction is a struct of FetchResponsePartitionData.
+type FetchResponseAbortedTransaction struct {
+	// The producer id associated with the aborted transaction.
+	ProducerId int64
+	// The first offset in the aborted transaction.
+	FirstOffset int64
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseAbortedTransaction) Default() {
+	*v = FetchResponseAbortedTransaction{}
+}
+
+func (v *FetchResponseAbortedTransaction) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 4 {
+		v.ProducerId = decoder.ReadInt64()
+	}
+	if version >= 4 {
+		v.FirstOffset = decoder.ReadInt64()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseAbortedTransaction) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseAbortedTransaction{}
+		v.Default()
+	}
+	if version >= 4 {
+		encoder.WriteInt64(v.ProducerId)
+	}
+	if version >= 4 {
+		encoder.WriteInt64(v.FirstOffset)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FetchResponseNodeEndpoint is a struct of FetchResponse.
+type FetchResponseNodeEndpoint struct {
+	// The ID of the associated node.
+	NodeId int32
+	// The node's hostname.
+	Host string
+	// The node's port.
+	Port int32
+	// The rack of the node, or null if it has not been assigned to a rack.
+	Rack string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FetchResponseNodeEndpoint) Default() {
+	*v = FetchResponseNodeEndpoint{}
+}
+
+func (v *FetchResponseNodeEndpoint) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 16 {
+		v.NodeId = decoder.ReadInt32()
+	}
+	if version >= 16 {
+		v.Host = decoder.ReadString()
+	}
+	if version >= 16 {
+		v.Port = decoder.ReadInt32()
+	}
+	if version >= 16 {
+		v.Rack, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FetchResponseNodeEndpoint) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FetchResponseNodeEndpoint{}
+		v.Default()
+	}
+	if version >= 16 {
+		encoder.WriteInt32(v.NodeId)
+	}
+	if version >= 16 {
+		encoder.WriteString(v.Host)
+	}
+	if version >= 16 {
+		encoder.WriteInt32(v.Port)
+	}
+	if version >= 16 {
+		encoder.WriteNullableString(v.Rack)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
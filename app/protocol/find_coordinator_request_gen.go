@@ -0,0 +1,102 @@
+// Code generated by gen from schema/FindCoordinatorRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// FindCoordinatorRequest is the request of API key 10, versions 0 to 5.
+type FindCoordinatorRequest struct {
+	// The coordinator key.
+	Key string
+	// The coordinator key type. (Group, transaction, etc.)
+	KeyType int8
+	// The coordinator keys.
+	CoordinatorKeys []string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *FindCoordinatorRequest) ApiKey() int16 {
+	return 10
+}
+
+func (v *FindCoordinatorRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *FindCoordinatorRequest) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *FindCoordinatorRequest) IsFlexible(version int16) bool {
+	return version >= 3
+}
+
+// Decode reads version of FindCoordinatorRequest from decoder.
+func (v *FindCoordinatorRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("FindCoordinatorRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of FindCoordinatorRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *FindCoordinatorRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("FindCoordinatorRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FindCoordinatorRequest) Default() {
+	*v = FindCoordinatorRequest{}
+}
+
+func (v *FindCoordinatorRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 3 {
+		v.Key = decoder.ReadString()
+	}
+	if version >= 1 {
+		v.KeyType = decoder.ReadInt8()
+	}
+	if version >= 4 {
+		coordinatorKeysLength := decoder.ReadArrayLength()
+		v.CoordinatorKeys = make([]string, coordinatorKeysLength)
+		for i := range v.CoordinatorKeys {
+			v.CoordinatorKeys[i] = decoder.ReadString()
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FindCoordinatorRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FindCoordinatorRequest{}
+		v.Default()
+	}
+	if version <= 3 {
+		encoder.WriteString(v.Key)
+	}
+	if version >= 1 {
+		encoder.WriteInt8(v.KeyType)
+	}
+	if version >= 4 {
+		encoder.WriteArrayLength(len(v.CoordinatorKeys))
+		for _, element := range v.CoordinatorKeys {
+			encoder.WriteString(element)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,209 @@
+// Code generated by gen from schema/FindCoordinatorResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// FindCoordinatorResponse is the response of API key 10, versions 0 to 5.
+type FindCoordinatorResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The error message, or null if there was no error.
+	ErrorMessage string
+	// The node id.
+	NodeId int32
+	// The host name.
+	Host string
+	// The port.
+	Port int32
+	// Each coordinator result in the response
+	Coordinators []*FindCoordinatorResponseCoordinator
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *FindCoordinatorResponse) ApiKey() int16 {
+	return 10
+}
+
+func (v *FindCoordinatorResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *FindCoordinatorResponse) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *FindCoordinatorResponse) IsFlexible(version int16) bool {
+	return version >= 3
+}
+
+// Decode reads version of FindCoordinatorResponse from decoder.
+func (v *FindCoordinatorResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("FindCoordinatorResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of FindCoordinatorResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *FindCoordinatorResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("FindCoordinatorResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FindCoordinatorResponse) Default() {
+	*v = FindCoordinatorResponse{}
+}
+
+func (v *FindCoordinatorResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	if version <= 3 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	if version >= 1 && version <= 3 {
+		v.ErrorMessage, _ = decoder.ReadNullableString()
+	}
+	if version <= 3 {
+		v.NodeId = decoder.ReadInt32()
+	}
+	if version <= 3 {
+		v.Host = decoder.ReadString()
+	}
+	if version <= 3 {
+		v.Port = decoder.ReadInt32()
+	}
+	if version >= 4 {
+		coordinatorsLength := decoder.ReadArrayLength()
+		v.Coordinators = make([]*FindCoordinatorResponseCoordinator, coordinatorsLength)
+		for i := range v.Coordinators {
+			v.Coordinators[i] = &FindCoordinatorResponseCoordinator{}
+			v.Coordinators[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FindCoordinatorResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FindCoordinatorResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	if version <= 3 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	if version >= 1 && version <= 3 {
+		encoder.WriteNullableString(v.ErrorMessage)
+	}
+	if version <= 3 {
+		encoder.WriteInt32(v.NodeId)
+	}
+	if version <= 3 {
+		encoder.WriteString(v.Host)
+	}
+	if version <= 3 {
+		encoder.WriteInt32(v.Port)
+	}
+	if version >= 4 {
+		encoder.WriteArrayLength(len(v.Coordinators))
+		for _, element := range v.Coordinators {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// FindCoordinatorResponseCoordinator is a struct of FindCoordinatorResponse.
+type FindCoordinatorResponseCoordinator struct {
+	// The coordinator key.
+	Key string
+	// The node id.
+	NodeId int32
+	// The host name.
+	Host string
+	// The port.
+	Port int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The error message, or null if there was no error.
+	ErrorMessage string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *FindCoordinatorResponseCoordinator) Default() {
+	*v = FindCoordinatorResponseCoordinator{}
+}
+
+func (v *FindCoordinatorResponseCoordinator) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 4 {
+		v.Key = decoder.ReadString()
+	}
+	if version >= 4 {
+		v.NodeId = decoder.ReadInt32()
+	}
+	if version >= 4 {
+		v.Host = decoder.ReadString()
+	}
+	if version >= 4 {
+		v.Port = decoder.ReadInt32()
+	}
+	if version >= 4 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	if version >= 4 {
+		v.ErrorMessage, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *FindCoordinatorResponseCoordinator) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &FindCoordinatorResponseCoordinator{}
+		v.Default()
+	}
+	if version >= 4 {
+		encoder.WriteString(v.Key)
+	}
+	if version >= 4 {
+		encoder.WriteInt32(v.NodeId)
+	}
+	if version >= 4 {
+		encoder.WriteString(v.Host)
+	}
+	if version >= 4 {
+		encoder.WriteInt32(v.Port)
+	}
+	if version >= 4 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	if version >= 4 {
+		encoder.WriteNullableString(v.ErrorMessage)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,807 @@
+// Gen generates the message types of package protocol from Apache Kafka's JSON
+// message schemas, the files of
+// clients/src/main/resources/common/message in the Kafka repository.
+//
+// Usage:
+//
+//	go run ./gen <schema dir> <output dir>
+//
+// Every <Name>.json schema becomes a <name>_gen.go file with a struct per
+// message and per nested struct. The structs decode and encode every valid
+// version of the message, flexible versions with compact types and tagged
+// fields included, on top of the protocol Decoder and Encoder.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// versions is a range of API versions, empty when low > high.
+type versions struct {
+	low  int16
+	high int16
+}
+
+var noVersions = versions{0, -1}
+
+// parseVersions parses a schema version range: "none", "3", "3-5" or "3+".
+func parseVersions(value string) (versions, error) {
+	switch {
+	case value == "" || value == "none":
+		return noVersions, nil
+	case strings.HasSuffix(value, "+"):
+		low, err := strconv.ParseInt(strings.TrimSuffix(value, "+"), 10, 16)
+		return versions{int16(low), math.MaxInt16}, err
+	case strings.Contains(value, "-"):
+		lowValue, highValue, _ := strings.Cut(value, "-")
+		low, err := strconv.ParseInt(lowValue, 10, 16)
+		if err != nil {
+			return noVersions, err
+		}
+		high, err := strconv.ParseInt(highValue, 10, 16)
+		return versions{int16(low), int16(high)}, err
+	default:
+		version, err := strconv.ParseInt(value, 10, 16)
+		return versions{int16(version), int16(version)}, err
+	}
+}
+
+func (v versions) empty() bool {
+	return v.low > v.high
+}
+
+func (v versions) intersect(other versions) versions {
+	return versions{max(v.low, other.low), min(v.high, other.high)}
+}
+
+// condition returns the Go condition on version for v, "" when every
+// version of within is in v.
+func (v versions) condition(within versions) string {
+	v = v.intersect(within)
+	if v.low == v.high && v.low > within.low && v.high < within.high {
+		return fmt.Sprintf("version == %d", v.low)
+	}
+	var conditions []string
+	if v.low > within.low {
+		conditions = append(conditions, fmt.Sprintf("version >= %d", v.low))
+	}
+	if v.high < within.high {
+		conditions = append(conditions, fmt.Sprintf("version <= %d", v.high))
+	}
+	return strings.Join(conditions, " && ")
+}
+
+type field struct {
+	Name             string          `json:"name"`
+	Type             string          `json:"type"`
+	Versions         string          `json:"versions"`
+	NullableVersions string          `json:"nullableVersions"`
+	TaggedVersions   string          `json:"taggedVersions"`
+	Tag              *int            `json:"tag"`
+	Default          json.RawMessage `json:"default"`
+	About            string          `json:"about"`
+	Fields           []*field        `json:"fields"`
+
+	versions versions
+	nullable versions
+	tagged   versions
+}
+
+type structSchema struct {
+	Name     string   `json:"name"`
+	Versions string   `json:"versions"`
+	Fields   []*field `json:"fields"`
+}
+
+type messageSchema struct {
+	ApiKey           int16           `json:"apiKey"`
+	Type             string          `json:"type"`
+	Name             string          `json:"name"`
+	ValidVersions    string          `json:"validVersions"`
+	FlexibleVersions string          `json:"flexibleVersions"`
+	Fields           []*field        `json:"fields"`
+	CommonStructs    []*structSchema `json:"commonStructs"`
+}
+
+var primitiveTypes = map[string]string{
+	"bool":    "bool",
+	"int8":    "int8",
+	"int16":   "int16",
+	"uint16":  "uint16",
+	"int32":   "int32",
+	"uint32":  "uint32",
+	"int64":   "int64",
+	"float64": "float64",
+	"string":  "string",
+	"bytes":   "[]byte",
+	"records": "[]byte",
+	"uuid":    "uuid.UUID",
+}
+
+// the Decoder and Encoder method suffix of the types, the non-nullable one
+// for strings and bytes
+var primitiveMethods = map[string]string{
+	"bool":    "Bool",
+	"int8":    "Int8",
+	"int16":   "Int16",
+	"uint16":  "Uint16",
+	"int32":   "Int32",
+	"uint32":  "Uint32",
+	"int64":   "Int64",
+	"float64": "Float64",
+	"string":  "String",
+	"bytes":   "Bytes",
+	"records": "Bytes",
+	"uuid":    "Uuid",
+}
+
+// commentLine matches the // comments Kafka's schemas have, which JSON
+// doesn't.
+var commentLine = regexp.MustCompile(`(?m)^\s*//.*$`)
+
+func readSchema(path string) (*messageSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema := &messageSchema{}
+	if err := json.Unmarshal(commentLine.ReplaceAll(data, nil), schema); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return schema, nil
+}
+
+type generator struct {
+	schema        *messageSchema
+	valid         versions
+	flexible      versions
+	commonStructs map[string]*structSchema
+	buffer        bytes.Buffer
+	usesUuid      bool
+	generated     map[string]bool
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buffer, format, args...)
+}
+
+// structName names the Go type of a schema struct, prefixed with the message
+// name since every message has its own.
+func (g *generator) structName(name string) string {
+	if strings.HasPrefix(name, g.schema.Name) {
+		return name
+	}
+	return g.schema.Name + name
+}
+
+// elementType returns the type of a field without the [] of arrays.
+func elementType(f *field) (name string, array bool) {
+	if strings.HasPrefix(f.Type, "[]") {
+		return strings.TrimPrefix(f.Type, "[]"), true
+	}
+	return f.Type, false
+}
+
+func isPrimitive(name string) bool {
+	_, ok := primitiveTypes[name]
+	return ok
+}
+
+func (g *generator) goType(f *field) string {
+	element, array := elementType(f)
+	if isPrimitive(element) {
+		if element == "uuid" {
+			g.usesUuid = true
+		}
+		if array {
+			return "[]" + primitiveTypes[element]
+		}
+		return primitiveTypes[element]
+	}
+	if array {
+		return "[]*" + g.structName(element)
+	}
+	return "*" + g.structName(element)
+}
+
+// resolve parses the version ranges of fields and their nested fields.
+func (g *generator) resolve(fields []*field) error {
+	for _, f := range fields {
+		// a few schemas start field names in lower case, like groupId
+		f.Name = upperFirst(f.Name)
+		var err error
+		if f.versions, err = parseVersions(f.Versions); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if f.nullable, err = parseVersions(f.NullableVersions); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if f.tagged, err = parseVersions(f.TaggedVersions); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if f.Tag != nil && f.tagged.empty() {
+			return fmt.Errorf("field %s has a tag but no tagged versions", f.Name)
+		}
+		if f.Tag == nil && !f.tagged.empty() {
+			return fmt.Errorf("field %s has tagged versions but no tag", f.Name)
+		}
+		f.versions = f.versions.intersect(g.valid)
+		f.nullable = f.nullable.intersect(f.versions)
+
+		element, _ := elementType(f)
+		if !isPrimitive(element) && f.Fields == nil {
+			common, ok := g.commonStructs[element]
+			if !ok {
+				return fmt.Errorf("field %s has unknown type %s", f.Name, f.Type)
+			}
+			f.Fields = common.Fields
+		}
+		if err := g.resolve(f.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultValue returns the Go literal of the default of a field, "" for the
+// zero value.
+func defaultValue(f *field) (string, error) {
+	if f.Default == nil {
+		return "", nil
+	}
+	var value string
+	if err := json.Unmarshal(f.Default, &value); err != nil {
+		// some schemas write booleans and numbers unquoted
+		value = string(f.Default)
+	}
+
+	element, array := elementType(f)
+	switch {
+	case value == "null" || array || !isPrimitive(element):
+		return "", nil
+	case element == "bool":
+		if value != "true" && value != "false" {
+			return "", fmt.Errorf("field %s has invalid default %q", f.Name, value)
+		}
+		if value == "false" {
+			return "", nil
+		}
+		return value, nil
+	case element == "string":
+		if value == "" {
+			return "", nil
+		}
+		return strconv.Quote(value), nil
+	case element == "float64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("field %s has invalid default %q", f.Name, value)
+		}
+		return value, nil
+	case strings.HasPrefix(element, "int") || strings.HasPrefix(element, "uint"):
+		if _, err := strconv.ParseInt(value, 0, 64); err != nil {
+			return "", fmt.Errorf("field %s has invalid default %q", f.Name, value)
+		}
+		if n, _ := strconv.ParseInt(value, 0, 64); n == 0 {
+			return "", nil
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("field %s can't have default %q", f.Name, value)
+}
+
+// isTagged reports whether a field is only ever sent as a tagged field.
+func isTagged(f *field) bool {
+	return f.Tag != nil
+}
+
+func (g *generator) generateStruct(name string, about string, fields []*field) error {
+	// common structs can be used by more than one field
+	if g.generated[name] {
+		return nil
+	}
+	g.generated[name] = true
+
+	var nested []*field
+	g.printf("\n// %s %s\n", name, about)
+	g.printf("type %s struct {\n", name)
+	for _, f := range fields {
+		if f.versions.empty() {
+			continue
+		}
+		if f.About != "" {
+			g.printf("// %s\n", f.About)
+		}
+		g.printf("%s %s\n", f.Name, g.goType(f))
+		if element, _ := elementType(f); !isPrimitive(element) {
+			nested = append(nested, f)
+		}
+	}
+	if !g.flexible.empty() {
+		g.printf("\n// UnknownTaggedFields has the raw value of the tagged fields this\n")
+		g.printf("// schema doesn't know, by tag.\n")
+		g.printf("UnknownTaggedFields map[uint64][]byte\n")
+	}
+	g.printf("}\n")
+
+	if name == g.schema.Name {
+		g.generateMessageMethods()
+	}
+	if err := g.generateDefault(name, fields); err != nil {
+		return err
+	}
+	g.generateDecode(name, fields)
+	g.generateEncode(name, fields)
+
+	for _, f := range nested {
+		element, _ := elementType(f)
+		about := "is a struct of " + name + "."
+		if err := g.generateStruct(g.structName(element), about, f.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateMessageMethods prints the methods of the message struct.
+func (g *generator) generateMessageMethods() {
+	schema := g.schema
+	flexible := g.flexible.condition(g.valid)
+	switch {
+	case g.flexible.empty():
+		flexible = "false"
+	case flexible == "":
+		flexible = "true"
+	}
+
+	g.printf("\nfunc (v *%s) ApiKey() int16 {\nreturn %d\n}\n\n", schema.Name, schema.ApiKey)
+	g.printf("func (v *%s) MinVersion() int16 {\nreturn %d\n}\n\n", schema.Name, g.valid.low)
+	g.printf("func (v *%s) MaxVersion() int16 {\nreturn %d\n}\n\n", schema.Name, g.valid.high)
+	g.printf("// IsFlexible reports whether version uses the compact types and tagged fields.\n")
+	g.printf("func (v *%s) IsFlexible(version int16) bool {\nreturn %s\n}\n\n", schema.Name, flexible)
+	g.printf("// Decode reads version of %s from decoder.\n", schema.Name)
+	g.printf("func (v *%s) Decode(decoder *Decoder, version int16) error {\n", schema.Name)
+	g.printf("if version < %d || version > %d {\n", g.valid.low, g.valid.high)
+	g.printf("return fmt.Errorf(\"%s v%%d is not supported\", version)\n", schema.Name)
+	g.printf("}\n")
+	g.printf("decoder.Flexible = v.IsFlexible(version)\n")
+	g.printf("v.decode(decoder, version)\n")
+	g.printf("return decoder.Err()\n")
+	g.printf("}\n\n")
+	g.printf("// Encode writes version of %s to encoder, errors are reported by\n// encoder.Err.\n", schema.Name)
+	g.printf("func (v *%s) Encode(encoder *Encoder, version int16) {\n", schema.Name)
+	g.printf("if version < %d || version > %d {\n", g.valid.low, g.valid.high)
+	g.printf("encoder.fail(fmt.Errorf(\"%s v%%d is not supported\", version))\n", schema.Name)
+	g.printf("return\n")
+	g.printf("}\n")
+	g.printf("encoder.Flexible = v.IsFlexible(version)\n")
+	g.printf("v.encode(encoder, version)\n")
+	g.printf("}\n")
+}
+
+func (g *generator) generateDefault(name string, fields []*field) error {
+	g.printf("\n// Default sets the fields to their schema defaults.\n")
+	g.printf("func (v *%s) Default() {\n", name)
+	g.printf("*v = %s{}\n", name)
+	for _, f := range fields {
+		if f.versions.empty() {
+			continue
+		}
+		value, err := defaultValue(f)
+		if err != nil {
+			return err
+		}
+		if value != "" {
+			g.printf("v.%s = %s\n", f.Name, value)
+		}
+	}
+	g.printf("}\n")
+	return nil
+}
+
+// block prints body, inside an if statement unless condition is "".
+func (g *generator) block(condition string, body func()) {
+	if condition == "" {
+		body()
+		return
+	}
+	g.printf("if %s {\n", condition)
+	body()
+	g.printf("}\n")
+}
+
+// nullableBlock prints whenNullable for the versions where f is nullable and
+// otherwise for the others.
+func (g *generator) nullableBlock(f *field, whenNullable func(), otherwise func()) {
+	switch {
+	case f.nullable.empty():
+		otherwise()
+	case f.nullable == f.versions:
+		whenNullable()
+	default:
+		g.printf("if %s {\n", f.nullable.condition(f.versions))
+		whenNullable()
+		g.printf("} else {\n")
+		otherwise()
+		g.printf("}\n")
+	}
+}
+
+// decodeField prints the statements reading f into target with decoder.
+func (g *generator) decodeField(f *field, target string, decoder string) {
+	element, array := elementType(f)
+	switch {
+	case array:
+		length := lowerFirst(f.Name) + "Length"
+		g.nullableBlock(f, func() {
+			g.printf("%s := %s.ReadNullableArrayLength()\n", length, decoder)
+			g.printf("if %s >= 0 {\n", length)
+			g.decodeElements(element, target, decoder, length)
+			g.printf("}\n")
+		}, func() {
+			g.printf("%s := %s.ReadArrayLength()\n", length, decoder)
+			g.decodeElements(element, target, decoder, length)
+		})
+	case !isPrimitive(element):
+		g.nullableBlock(f, func() {
+			g.printf("if %s.ReadInt8() >= 0 {\n", decoder)
+			g.printf("%s = &%s{}\n", target, g.structName(element))
+			g.printf("%s.decode(%s, version)\n", target, decoder)
+			g.printf("}\n")
+		}, func() {
+			g.printf("%s = &%s{}\n", target, g.structName(element))
+			g.printf("%s.decode(%s, version)\n", target, decoder)
+		})
+	case element == "string":
+		g.nullableBlock(f, func() {
+			g.printf("%s, _ = %s.ReadNullableString()\n", target, decoder)
+		}, func() {
+			g.printf("%s = %s.ReadString()\n", target, decoder)
+		})
+	case element == "bytes" || element == "records":
+		g.nullableBlock(f, func() {
+			g.printf("%s = %s.ReadNullableBytes()\n", target, decoder)
+		}, func() {
+			g.printf("%s = %s.ReadBytes()\n", target, decoder)
+		})
+	default:
+		g.printf("%s = %s.Read%s()\n", target, decoder, primitiveMethods[element])
+	}
+}
+
+func (g *generator) decodeElements(element string, target string, decoder string, length string) {
+	if isPrimitive(element) {
+		g.printf("%s = make([]%s, %s)\n", target, primitiveTypes[element], length)
+		g.printf("for i := range %s {\n", target)
+		g.printf("%s[i] = %s.Read%s()\n", target, decoder, primitiveMethods[element])
+		g.printf("}\n")
+		return
+	}
+	g.printf("%s = make([]*%s, %s)\n", target, g.structName(element), length)
+	g.printf("for i := range %s {\n", target)
+	g.printf("%s[i] = &%s{}\n", target, g.structName(element))
+	g.printf("%s[i].decode(%s, version)\n", target, decoder)
+	g.printf("}\n")
+}
+
+func (g *generator) generateDecode(name string, fields []*field) {
+	g.printf("\nfunc (v *%s) decode(decoder *Decoder, version int16) {\n", name)
+	g.printf("v.Default()\n")
+	for _, f := range fields {
+		if f.versions.empty() || isTagged(f) {
+			continue
+		}
+		g.block(f.versions.condition(g.valid), func() {
+			g.decodeField(f, "v."+f.Name, "decoder")
+		})
+	}
+	if g.flexible.empty() {
+		g.printf("}\n")
+		return
+	}
+
+	g.printf("v.UnknownTaggedFields = decoder.ReadTaggedFields()\n")
+	for _, f := range fields {
+		if f.versions.empty() || !isTagged(f) {
+			continue
+		}
+		condition := "ok"
+		if versionCondition := f.tagged.condition(g.valid); versionCondition != "" {
+			condition += " && " + versionCondition
+		}
+		g.printf("if data, ok := v.UnknownTaggedFields[%d]; %s {\n", *f.Tag, condition)
+		g.printf("delete(v.UnknownTaggedFields, %d)\n", *f.Tag)
+		g.printf("tagged := NewDecoder(data, true)\n")
+		g.decodeField(f, "v."+f.Name, "tagged")
+		g.printf("if err := tagged.Err(); err != nil {\n")
+		g.printf("decoder.fail(fmt.Errorf(\"tagged field %d: %%w\", err))\n", *f.Tag)
+		g.printf("}\n")
+		g.printf("}\n")
+	}
+	g.printf("}\n")
+}
+
+// encodeField prints the statements writing value of f with encoder.
+func (g *generator) encodeField(f *field, value string, encoder string) {
+	element, array := elementType(f)
+	switch {
+	case array:
+		writeElements := func() {
+			g.printf("%s.WriteArrayLength(len(%s))\n", encoder, value)
+			g.printf("for _, element := range %s {\n", value)
+			if isPrimitive(element) {
+				g.printf("%s.Write%s(element)\n", encoder, primitiveMethods[element])
+			} else {
+				g.printf("element.encode(%s, version)\n", encoder)
+			}
+			g.printf("}\n")
+		}
+		g.nullableBlock(f, func() {
+			g.printf("if %s == nil {\n", value)
+			g.printf("%s.WriteArrayLength(-1)\n", encoder)
+			g.printf("} else {\n")
+			writeElements()
+			g.printf("}\n")
+		}, writeElements)
+	case !isPrimitive(element):
+		g.nullableBlock(f, func() {
+			g.printf("if %s == nil {\n", value)
+			g.printf("%s.WriteInt8(-1)\n", encoder)
+			g.printf("} else {\n")
+			g.printf("%s.WriteInt8(1)\n", encoder)
+			g.printf("%s.encode(%s, version)\n", value, encoder)
+			g.printf("}\n")
+		}, func() {
+			g.printf("%s.encode(%s, version)\n", value, encoder)
+		})
+	case element == "string" && hasEmptyDefault(f):
+		// the empty string can't be told apart from null, the default wins
+		g.printf("%s.WriteString(%s)\n", encoder, value)
+	case element == "string":
+		g.nullableBlock(f, func() {
+			g.printf("%s.WriteNullableString(%s)\n", encoder, value)
+		}, func() {
+			g.printf("%s.WriteString(%s)\n", encoder, value)
+		})
+	case element == "bytes" || element == "records":
+		g.nullableBlock(f, func() {
+			g.printf("%s.WriteNullableBytes(%s)\n", encoder, value)
+		}, func() {
+			g.printf("%s.WriteBytes(%s)\n", encoder, value)
+		})
+	default:
+		g.printf("%s.Write%s(%s)\n", encoder, primitiveMethods[element], value)
+	}
+}
+
+// hasEmptyDefault reports whether f declares the empty string as its default.
+// Nullable strings are written as null when empty unless they do.
+func hasEmptyDefault(f *field) bool {
+	var value string
+	return f.Default != nil && json.Unmarshal(f.Default, &value) == nil && value == ""
+}
+
+// isSetCondition returns the condition for a tagged field to differ from its
+// default, tagged fields are only sent then.
+func isSetCondition(f *field, value string) (string, error) {
+	element, array := elementType(f)
+	switch {
+	case array && !f.nullable.empty():
+		return value + " != nil", nil
+	case array:
+		return "len(" + value + ") > 0", nil
+	case !isPrimitive(element):
+		return value + " != nil", nil
+	case element == "bytes" || element == "records":
+		if !f.nullable.empty() {
+			return value + " != nil", nil
+		}
+		return "len(" + value + ") > 0", nil
+	case element == "uuid":
+		return value + " != uuid.Nil", nil
+	case element == "bool":
+		if defaultBool, _ := defaultValue(f); defaultBool == "true" {
+			return "!" + value, nil
+		}
+		return value, nil
+	}
+	defaultLiteral, err := defaultValue(f)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case defaultLiteral != "":
+		return value + " != " + defaultLiteral, nil
+	case element == "string":
+		return value + ` != ""`, nil
+	default:
+		return value + " != 0", nil
+	}
+}
+
+func (g *generator) generateEncode(name string, fields []*field) {
+	g.printf("\nfunc (v *%s) encode(encoder *Encoder, version int16) {\n", name)
+	g.printf("if v == nil {\n")
+	g.printf("v = &%s{}\n", name)
+	g.printf("v.Default()\n")
+	g.printf("}\n")
+	for _, f := range fields {
+		if f.versions.empty() || isTagged(f) {
+			continue
+		}
+		g.block(f.versions.condition(g.valid), func() {
+			g.encodeField(f, "v."+f.Name, "encoder")
+		})
+	}
+	if g.flexible.empty() {
+		g.printf("}\n")
+		return
+	}
+
+	hasTagged := slices.ContainsFunc(fields, func(f *field) bool {
+		return !f.versions.empty() && isTagged(f)
+	})
+	if !hasTagged {
+		g.printf("encoder.WriteTaggedFields(v.UnknownTaggedFields)\n")
+		g.printf("}\n")
+		return
+	}
+	g.printf("taggedFields := maps.Clone(v.UnknownTaggedFields)\n")
+	g.printf("if taggedFields == nil {\n")
+	g.printf("taggedFields = map[uint64][]byte{}\n")
+	g.printf("}\n")
+	for _, f := range fields {
+		if f.versions.empty() || !isTagged(f) {
+			continue
+		}
+		// checkTagged already checked the schema, the error can't happen
+		isSet, _ := isSetCondition(f, "v."+f.Name)
+		if versionCondition := f.tagged.condition(g.valid); versionCondition != "" {
+			isSet = versionCondition + " && " + isSet
+		}
+		g.printf("if %s {\n", isSet)
+		g.printf("tagged := NewEncoder(true)\n")
+		g.encodeField(f, "v."+f.Name, "tagged")
+		g.printf("encoder.fail(tagged.Err())\n")
+		g.printf("taggedFields[%d] = tagged.Bytes()\n", *f.Tag)
+		g.printf("}\n")
+	}
+	g.printf("encoder.WriteTaggedFields(taggedFields)\n")
+	g.printf("}\n")
+}
+
+// checkTagged checks the tagged fields of a struct, their tags must be
+// unique and they must be tagged in every version they have.
+func checkTagged(fields []*field) error {
+	tags := map[int]string{}
+	for _, f := range fields {
+		if !isTagged(f) {
+			continue
+		}
+		if other, ok := tags[*f.Tag]; ok {
+			return fmt.Errorf("fields %s and %s have tag %d", other, f.Name, *f.Tag)
+		}
+		tags[*f.Tag] = f.Name
+		if f.tagged.intersect(f.versions) != f.versions {
+			return fmt.Errorf("field %s is tagged in only some of its versions", f.Name)
+		}
+		if _, err := isSetCondition(f, ""); err != nil {
+			return err
+		}
+	}
+	for _, f := range fields {
+		if err := checkTagged(f.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) generateMessage(source string) ([]byte, error) {
+	schema := g.schema
+	var err error
+	if g.valid, err = parseVersions(schema.ValidVersions); err != nil {
+		return nil, fmt.Errorf("validVersions: %w", err)
+	}
+	if g.flexible, err = parseVersions(schema.FlexibleVersions); err != nil {
+		return nil, fmt.Errorf("flexibleVersions: %w", err)
+	}
+	g.flexible = g.flexible.intersect(g.valid)
+	g.commonStructs = map[string]*structSchema{}
+	for _, common := range schema.CommonStructs {
+		g.commonStructs[common.Name] = common
+	}
+	if err := g.resolve(schema.Fields); err != nil {
+		return nil, err
+	}
+	if err := checkTagged(schema.Fields); err != nil {
+		return nil, err
+	}
+
+	about := fmt.Sprintf("is the %s of API key %d, versions %d to %d.", schema.Type, schema.ApiKey, g.valid.low, g.valid.high)
+	if err := g.generateStruct(schema.Name, about, schema.Fields); err != nil {
+		return nil, err
+	}
+	body := g.buffer.String()
+	g.buffer.Reset()
+
+	g.printf("// Code generated by gen from %s. DO NOT EDIT.\n\n", source)
+	g.printf("package protocol\n\n")
+	g.printf("import (\n\"fmt\"\n")
+	if strings.Contains(body, "maps.") {
+		g.printf("\"maps\"\n")
+	}
+	if g.usesUuid {
+		g.printf("\n\"github.com/google/uuid\"\n")
+	}
+	g.printf(")\n")
+	g.buffer.WriteString(body)
+
+	source = g.buffer.String()
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, source)
+	}
+	return formatted, nil
+}
+
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func upperFirst(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// fileName turns FetchRequest into fetch_request_gen.go.
+func fileName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(r)
+	}
+	return strings.ToLower(builder.String()) + "_gen.go"
+}
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: gen <schema dir> <output dir>")
+		os.Exit(2)
+	}
+	schemaDir, outputDir := os.Args[1], os.Args[2]
+
+	paths, err := filepath.Glob(filepath.Join(schemaDir, "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error while listing schemas. %s\n", err)
+		os.Exit(1)
+	}
+	for _, path := range paths {
+		schema, err := readSchema(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while reading schema. %s\n", err)
+			os.Exit(1)
+		}
+		g := &generator{schema: schema, generated: map[string]bool{}}
+		source := filepath.ToSlash(filepath.Join(filepath.Base(schemaDir), filepath.Base(path)))
+		code, err := g.generateMessage(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error while generating %s. %s\n", path, err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, fileName(schema.Name)), code, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error while writing %s. %s\n", schema.Name, err)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package protocol
+
+// The message types are generated from the Kafka message schemas in schema/.
+//go:generate go run ./gen schema .
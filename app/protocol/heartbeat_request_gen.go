@@ -0,0 +1,91 @@
+// Code generated by gen from schema/HeartbeatRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// HeartbeatRequest is the request of API key 12, versions 0 to 4.
+type HeartbeatRequest struct {
+	// The group id.
+	GroupId string
+	// The generation of the group.
+	GenerationId int32
+	// The member ID.
+	MemberId string
+	// The unique identifier of the consumer instance provided by end user.
+	GroupInstanceId string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *HeartbeatRequest) ApiKey() int16 {
+	return 12
+}
+
+func (v *HeartbeatRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *HeartbeatRequest) MaxVersion() int16 {
+	return 4
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *HeartbeatRequest) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of HeartbeatRequest from decoder.
+func (v *HeartbeatRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 4 {
+		return fmt.Errorf("HeartbeatRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of HeartbeatRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *HeartbeatRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 4 {
+		encoder.fail(fmt.Errorf("HeartbeatRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *HeartbeatRequest) Default() {
+	*v = HeartbeatRequest{}
+}
+
+func (v *HeartbeatRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.GroupId = decoder.ReadString()
+	v.GenerationId = decoder.ReadInt32()
+	v.MemberId = decoder.ReadString()
+	if version >= 3 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *HeartbeatRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &HeartbeatRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.GroupId)
+	encoder.WriteInt32(v.GenerationId)
+	encoder.WriteString(v.MemberId)
+	if version >= 3 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,83 @@
+// Code generated by gen from schema/HeartbeatResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// HeartbeatResponse is the response of API key 12, versions 0 to 4.
+type HeartbeatResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *HeartbeatResponse) ApiKey() int16 {
+	return 12
+}
+
+func (v *HeartbeatResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *HeartbeatResponse) MaxVersion() int16 {
+	return 4
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *HeartbeatResponse) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of HeartbeatResponse from decoder.
+func (v *HeartbeatResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 4 {
+		return fmt.Errorf("HeartbeatResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of HeartbeatResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *HeartbeatResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 4 {
+		encoder.fail(fmt.Errorf("HeartbeatResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *HeartbeatResponse) Default() {
+	*v = HeartbeatResponse{}
+}
+
+func (v *HeartbeatResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *HeartbeatResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &HeartbeatResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,158 @@
+// Code generated by gen from schema/JoinGroupRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// JoinGroupRequest is the request of API key 11, versions 0 to 9.
+type JoinGroupRequest struct {
+	// The group identifier.
+	GroupId string
+	// The coordinator considers the consumer dead if it receives no heartbeat after this timeout in milliseconds.
+	SessionTimeoutMs int32
+	// The maximum time in milliseconds that the coordinator will wait for each member to rejoin when rebalancing the group.
+	RebalanceTimeoutMs int32
+	// The member id assigned by the group coordinator.
+	MemberId string
+	// The unique identifier of the consumer instance provided by end user.
+	GroupInstanceId string
+	// The unique name the for class of protocols implemented by the group we want to join.
+	ProtocolType string
+	// The list of protocols that the member supports.
+	Protocols []*JoinGroupRequestProtocol
+	// The reason why the member (re-)joins the group.
+	Reason string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *JoinGroupRequest) ApiKey() int16 {
+	return 11
+}
+
+func (v *JoinGroupRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *JoinGroupRequest) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *JoinGroupRequest) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of JoinGroupRequest from decoder.
+func (v *JoinGroupRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("JoinGroupRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of JoinGroupRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *JoinGroupRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("JoinGroupRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *JoinGroupRequest) Default() {
+	*v = JoinGroupRequest{}
+	v.RebalanceTimeoutMs = -1
+}
+
+func (v *JoinGroupRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.GroupId = decoder.ReadString()
+	v.SessionTimeoutMs = decoder.ReadInt32()
+	if version >= 1 {
+		v.RebalanceTimeoutMs = decoder.ReadInt32()
+	}
+	v.MemberId = decoder.ReadString()
+	if version >= 5 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	v.ProtocolType = decoder.ReadString()
+	protocolsLength := decoder.ReadArrayLength()
+	v.Protocols = make([]*JoinGroupRequestProtocol, protocolsLength)
+	for i := range v.Protocols {
+		v.Protocols[i] = &JoinGroupRequestProtocol{}
+		v.Protocols[i].decode(decoder, version)
+	}
+	if version >= 8 {
+		v.Reason, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *JoinGroupRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &JoinGroupRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.GroupId)
+	encoder.WriteInt32(v.SessionTimeoutMs)
+	if version >= 1 {
+		encoder.WriteInt32(v.RebalanceTimeoutMs)
+	}
+	encoder.WriteString(v.MemberId)
+	if version >= 5 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	encoder.WriteString(v.ProtocolType)
+	encoder.WriteArrayLength(len(v.Protocols))
+	for _, element := range v.Protocols {
+		element.encode(encoder, version)
+	}
+	if version >= 8 {
+		encoder.WriteNullableString(v.Reason)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// JoinGroupRequestProtocol is a struct of JoinGroupRequest.
+type JoinGroupRequestProtocol struct {
+	// The protocol name.
+	Name string
+	// The protocol metadata.
+	Metadata []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *JoinGroupRequestProtocol) Default() {
+	*v = JoinGroupRequestProtocol{}
+}
+
+func (v *JoinGroupRequestProtocol) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	v.Metadata = decoder.ReadBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *JoinGroupRequestProtocol) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &JoinGroupRequestProtocol{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteBytes(v.Metadata)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
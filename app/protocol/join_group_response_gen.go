@@ -0,0 +1,177 @@
+// Code generated by gen from schema/JoinGroupResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// JoinGroupResponse is the response of API key 11, versions 0 to 9.
+type JoinGroupResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The generation ID of the group.
+	GenerationId int32
+	// The group protocol name.
+	ProtocolType string
+	// The group protocol selected by the coordinator.
+	ProtocolName string
+	// The leader of the group.
+	Leader string
+	// True if the leader must skip running the assignment.
+	SkipAssignment bool
+	// The member ID assigned by the group coordinator.
+	MemberId string
+	Members  []*JoinGroupResponseMember
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *JoinGroupResponse) ApiKey() int16 {
+	return 11
+}
+
+func (v *JoinGroupResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *JoinGroupResponse) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *JoinGroupResponse) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of JoinGroupResponse from decoder.
+func (v *JoinGroupResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("JoinGroupResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of JoinGroupResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *JoinGroupResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("JoinGroupResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *JoinGroupResponse) Default() {
+	*v = JoinGroupResponse{}
+	v.GenerationId = -1
+}
+
+func (v *JoinGroupResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 2 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	v.GenerationId = decoder.ReadInt32()
+	if version >= 7 {
+		v.ProtocolType, _ = decoder.ReadNullableString()
+	}
+	if version >= 7 {
+		v.ProtocolName, _ = decoder.ReadNullableString()
+	} else {
+		v.ProtocolName = decoder.ReadString()
+	}
+	v.Leader = decoder.ReadString()
+	if version >= 9 {
+		v.SkipAssignment = decoder.ReadBool()
+	}
+	v.MemberId = decoder.ReadString()
+	membersLength := decoder.ReadArrayLength()
+	v.Members = make([]*JoinGroupResponseMember, membersLength)
+	for i := range v.Members {
+		v.Members[i] = &JoinGroupResponseMember{}
+		v.Members[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *JoinGroupResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &JoinGroupResponse{}
+		v.Default()
+	}
+	if version >= 2 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteInt32(v.GenerationId)
+	if version >= 7 {
+		encoder.WriteNullableString(v.ProtocolType)
+	}
+	if version >= 7 {
+		encoder.WriteNullableString(v.ProtocolName)
+	} else {
+		encoder.WriteString(v.ProtocolName)
+	}
+	encoder.WriteString(v.Leader)
+	if version >= 9 {
+		encoder.WriteBool(v.SkipAssignment)
+	}
+	encoder.WriteString(v.MemberId)
+	encoder.WriteArrayLength(len(v.Members))
+	for _, element := range v.Members {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// JoinGroupResponseMember is a struct of JoinGroupResponse.
+type JoinGroupResponseMember struct {
+	// The group member ID.
+	MemberId string
+	// The unique identifier of the consumer instance provided by end user.
+	GroupInstanceId string
+	// The group member metadata.
+	Metadata []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *JoinGroupResponseMember) Default() {
+	*v = JoinGroupResponseMember{}
+}
+
+func (v *JoinGroupResponseMember) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.MemberId = decoder.ReadString()
+	if version >= 5 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	v.Metadata = decoder.ReadBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *JoinGroupResponseMember) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &JoinGroupResponseMember{}
+		v.Default()
+	}
+	encoder.WriteString(v.MemberId)
+	if version >= 5 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	encoder.WriteBytes(v.Metadata)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
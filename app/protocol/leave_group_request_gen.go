@@ -0,0 +1,149 @@
+// Code generated by gen from schema/LeaveGroupRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// LeaveGroupRequest is the request of API key 13, versions 0 to 5.
+type LeaveGroupRequest struct {
+	// The ID of the group to leave.
+	GroupId string
+	// The member ID to remove from the group.
+	MemberId string
+	// List of leaving member identities.
+	Members []*LeaveGroupRequestMemberIdentity
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *LeaveGroupRequest) ApiKey() int16 {
+	return 13
+}
+
+func (v *LeaveGroupRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *LeaveGroupRequest) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *LeaveGroupRequest) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of LeaveGroupRequest from decoder.
+func (v *LeaveGroupRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("LeaveGroupRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of LeaveGroupRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *LeaveGroupRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("LeaveGroupRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *LeaveGroupRequest) Default() {
+	*v = LeaveGroupRequest{}
+}
+
+func (v *LeaveGroupRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.GroupId = decoder.ReadString()
+	if version <= 2 {
+		v.MemberId = decoder.ReadString()
+	}
+	if version >= 3 {
+		membersLength := decoder.ReadArrayLength()
+		v.Members = make([]*LeaveGroupRequestMemberIdentity, membersLength)
+		for i := range v.Members {
+			v.Members[i] = &LeaveGroupRequestMemberIdentity{}
+			v.Members[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *LeaveGroupRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &LeaveGroupRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.GroupId)
+	if version <= 2 {
+		encoder.WriteString(v.MemberId)
+	}
+	if version >= 3 {
+		encoder.WriteArrayLength(len(v.Members))
+		for _, element := range v.Members {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// LeaveGroupRequestMemberIdentity is a struct of LeaveGroupRequest.
+type LeaveGroupRequestMemberIdentity struct {
+	// The member ID to remove from the group.
+	MemberId string
+	// The group instance ID to remove from the group.
+	GroupInstanceId string
+	// The reason why the member left the group.
+	Reason string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *LeaveGroupRequestMemberIdentity) Default() {
+	*v = LeaveGroupRequestMemberIdentity{}
+}
+
+func (v *LeaveGroupRequestMemberIdentity) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.MemberId = decoder.ReadString()
+	}
+	if version >= 3 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.Reason, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *LeaveGroupRequestMemberIdentity) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &LeaveGroupRequestMemberIdentity{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteString(v.MemberId)
+	}
+	if version >= 3 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	if version >= 5 {
+		encoder.WriteNullableString(v.Reason)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
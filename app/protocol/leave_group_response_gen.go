@@ -0,0 +1,149 @@
+// Code generated by gen from schema/LeaveGroupResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// LeaveGroupResponse is the response of API key 13, versions 0 to 5.
+type LeaveGroupResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// List of leaving member responses.
+	Members []*LeaveGroupResponseMemberResponse
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *LeaveGroupResponse) ApiKey() int16 {
+	return 13
+}
+
+func (v *LeaveGroupResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *LeaveGroupResponse) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *LeaveGroupResponse) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of LeaveGroupResponse from decoder.
+func (v *LeaveGroupResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("LeaveGroupResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of LeaveGroupResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *LeaveGroupResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("LeaveGroupResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *LeaveGroupResponse) Default() {
+	*v = LeaveGroupResponse{}
+}
+
+func (v *LeaveGroupResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	if version >= 3 {
+		membersLength := decoder.ReadArrayLength()
+		v.Members = make([]*LeaveGroupResponseMemberResponse, membersLength)
+		for i := range v.Members {
+			v.Members[i] = &LeaveGroupResponseMemberResponse{}
+			v.Members[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *LeaveGroupResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &LeaveGroupResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	if version >= 3 {
+		encoder.WriteArrayLength(len(v.Members))
+		for _, element := range v.Members {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// LeaveGroupResponseMemberResponse is a struct of LeaveGroupResponse.
+type LeaveGroupResponseMemberResponse struct {
+	// The member ID to remove from the group.
+	MemberId string
+	// The group instance ID to remove from the group.
+	GroupInstanceId string
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *LeaveGroupResponseMemberResponse) Default() {
+	*v = LeaveGroupResponseMemberResponse{}
+}
+
+func (v *LeaveGroupResponseMemberResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.MemberId = decoder.ReadString()
+	}
+	if version >= 3 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	if version >= 3 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *LeaveGroupResponseMemberResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &LeaveGroupResponseMemberResponse{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteString(v.MemberId)
+	}
+	if version >= 3 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	if version >= 3 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,189 @@
+// Code generated by gen from schema/ListOffsetsRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// ListOffsetsRequest is the request of API key 2, versions 0 to 9.
+type ListOffsetsRequest struct {
+	// The broker ID of the requester, or -1 if this request is being made by a normal consumer.
+	ReplicaId int32
+	// This setting controls the visibility of transactional records. Using READ_UNCOMMITTED (isolation_level = 0) makes all records visible. With READ_COMMITTED (isolation_level = 1), non-transactional and COMMITTED transactional records are visible. To be more concrete, READ_COMMITTED returns all data from offsets smaller than the current LSO (last stable offset), and enables the inclusion of the list of aborted transactions in the result, which allows consumers to discard ABORTED transactional records
+	IsolationLevel int8
+	// Each topic in the request.
+	Topics []*ListOffsetsRequestListOffsetsTopic
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ListOffsetsRequest) ApiKey() int16 {
+	return 2
+}
+
+func (v *ListOffsetsRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *ListOffsetsRequest) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ListOffsetsRequest) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of ListOffsetsRequest from decoder.
+func (v *ListOffsetsRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("ListOffsetsRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ListOffsetsRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *ListOffsetsRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("ListOffsetsRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsRequest) Default() {
+	*v = ListOffsetsRequest{}
+}
+
+func (v *ListOffsetsRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ReplicaId = decoder.ReadInt32()
+	if version >= 2 {
+		v.IsolationLevel = decoder.ReadInt8()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*ListOffsetsRequestListOffsetsTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &ListOffsetsRequestListOffsetsTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsRequest{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.ReplicaId)
+	if version >= 2 {
+		encoder.WriteInt8(v.IsolationLevel)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ListOffsetsRequestListOffsetsTopic is a struct of ListOffsetsRequest.
+type ListOffsetsRequestListOffsetsTopic struct {
+	// The topic name.
+	Name string
+	// Each partition in the request.
+	Partitions []*ListOffsetsRequestListOffsetsPartition
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsRequestListOffsetsTopic) Default() {
+	*v = ListOffsetsRequestListOffsetsTopic{}
+}
+
+func (v *ListOffsetsRequestListOffsetsTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*ListOffsetsRequestListOffsetsPartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &ListOffsetsRequestListOffsetsPartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsRequestListOffsetsTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsRequestListOffsetsTopic{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ListOffsetsRequestListOffsetsPartition is a struct of ListOffsetsRequestListOffsetsTopic.
+type ListOffsetsRequestListOffsetsPartition struct {
+	// The partition index.
+	PartitionIndex int32
+	// The current leader epoch.
+	CurrentLeaderEpoch int32
+	// The current timestamp.
+	Timestamp int64
+	// The maximum number of offsets to report.
+	MaxNumOffsets int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsRequestListOffsetsPartition) Default() {
+	*v = ListOffsetsRequestListOffsetsPartition{}
+	v.CurrentLeaderEpoch = -1
+	v.MaxNumOffsets = 1
+}
+
+func (v *ListOffsetsRequestListOffsetsPartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	if version >= 4 {
+		v.CurrentLeaderEpoch = decoder.ReadInt32()
+	}
+	v.Timestamp = decoder.ReadInt64()
+	if version <= 0 {
+		v.MaxNumOffsets = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsRequestListOffsetsPartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsRequestListOffsetsPartition{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	if version >= 4 {
+		encoder.WriteInt32(v.CurrentLeaderEpoch)
+	}
+	encoder.WriteInt64(v.Timestamp)
+	if version <= 0 {
+		encoder.WriteInt32(v.MaxNumOffsets)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,209 @@
+// Code generated by gen from schema/ListOffsetsResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// ListOffsetsResponse is the response of API key 2, versions 0 to 9.
+type ListOffsetsResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// Each topic in the response.
+	Topics []*ListOffsetsResponseListOffsetsTopicResponse
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ListOffsetsResponse) ApiKey() int16 {
+	return 2
+}
+
+func (v *ListOffsetsResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *ListOffsetsResponse) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ListOffsetsResponse) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of ListOffsetsResponse from decoder.
+func (v *ListOffsetsResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("ListOffsetsResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ListOffsetsResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *ListOffsetsResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("ListOffsetsResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsResponse) Default() {
+	*v = ListOffsetsResponse{}
+}
+
+func (v *ListOffsetsResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 2 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*ListOffsetsResponseListOffsetsTopicResponse, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &ListOffsetsResponseListOffsetsTopicResponse{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsResponse{}
+		v.Default()
+	}
+	if version >= 2 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ListOffsetsResponseListOffsetsTopicResponse is a struct of ListOffsetsResponse.
+type ListOffsetsResponseListOffsetsTopicResponse struct {
+	// The topic name
+	Name string
+	// Each partition in the response.
+	Partitions []*ListOffsetsResponseListOffsetsPartitionResponse
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsResponseListOffsetsTopicResponse) Default() {
+	*v = ListOffsetsResponseListOffsetsTopicResponse{}
+}
+
+func (v *ListOffsetsResponseListOffsetsTopicResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*ListOffsetsResponseListOffsetsPartitionResponse, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &ListOffsetsResponseListOffsetsPartitionResponse{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsResponseListOffsetsTopicResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsResponseListOffsetsTopicResponse{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ListOffsetsResponseListOffsetsPartitionResponse is a struct of ListOffsetsResponseListOffsetsTopicResponse.
+type ListOffsetsResponseListOffsetsPartitionResponse struct {
+	// The partition index.
+	PartitionIndex int32
+	// The partition error code, or 0 if there was no error.
+	ErrorCode int16
+	// The result offsets.
+	OldStyleOffsets []int64
+	// The timestamp associated with the returned offset.
+	Timestamp int64
+	// The returned offset.
+	Offset int64
+	// The leader epoch associated with the returned offset.
+	LeaderEpoch int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ListOffsetsResponseListOffsetsPartitionResponse) Default() {
+	*v = ListOffsetsResponseListOffsetsPartitionResponse{}
+	v.Timestamp = -1
+	v.Offset = -1
+	v.LeaderEpoch = -1
+}
+
+func (v *ListOffsetsResponseListOffsetsPartitionResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.ErrorCode = decoder.ReadInt16()
+	if version <= 0 {
+		oldStyleOffsetsLength := decoder.ReadArrayLength()
+		v.OldStyleOffsets = make([]int64, oldStyleOffsetsLength)
+		for i := range v.OldStyleOffsets {
+			v.OldStyleOffsets[i] = decoder.ReadInt64()
+		}
+	}
+	if version >= 1 {
+		v.Timestamp = decoder.ReadInt64()
+	}
+	if version >= 1 {
+		v.Offset = decoder.ReadInt64()
+	}
+	if version >= 4 {
+		v.LeaderEpoch = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ListOffsetsResponseListOffsetsPartitionResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ListOffsetsResponseListOffsetsPartitionResponse{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt16(v.ErrorCode)
+	if version <= 0 {
+		encoder.WriteArrayLength(len(v.OldStyleOffsets))
+		for _, element := range v.OldStyleOffsets {
+			encoder.WriteInt64(element)
+		}
+	}
+	if version >= 1 {
+		encoder.WriteInt64(v.Timestamp)
+	}
+	if version >= 1 {
+		encoder.WriteInt64(v.Offset)
+	}
+	if version >= 4 {
+		encoder.WriteInt32(v.LeaderEpoch)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
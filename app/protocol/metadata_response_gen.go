@@ -0,0 +1,340 @@
+// Code generated by gen from schema/MetadataResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MetadataResponse is the response of API key 3, versions 0 to 12.
+type MetadataResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// A list of brokers present in the cluster.
+	Brokers []*MetadataResponseBroker
+	// The cluster ID that responding broker belongs to.
+	ClusterId string
+	// The ID of the controller broker.
+	ControllerId int32
+	// Each topic in the response.
+	Topics []*MetadataResponseTopic
+	// 32-bit bitfield to represent authorized operations for this cluster.
+	ClusterAuthorizedOperations int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *MetadataResponse) ApiKey() int16 {
+	return 3
+}
+
+func (v *MetadataResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *MetadataResponse) MaxVersion() int16 {
+	return 12
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *MetadataResponse) IsFlexible(version int16) bool {
+	return version >= 9
+}
+
+// Decode reads version of MetadataResponse from decoder.
+func (v *MetadataResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 12 {
+		return fmt.Errorf("MetadataResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of MetadataResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *MetadataResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 12 {
+		encoder.fail(fmt.Errorf("MetadataResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *MetadataResponse) Default() {
+	*v = MetadataResponse{}
+	v.ControllerId = -1
+	v.ClusterAuthorizedOperations = -2147483648
+}
+
+func (v *MetadataResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	brokersLength := decoder.ReadArrayLength()
+	v.Brokers = make([]*MetadataResponseBroker, brokersLength)
+	for i := range v.Brokers {
+		v.Brokers[i] = &MetadataResponseBroker{}
+		v.Brokers[i].decode(decoder, version)
+	}
+	if version >= 2 {
+		v.ClusterId, _ = decoder.ReadNullableString()
+	}
+	if version >= 1 {
+		v.ControllerId = decoder.ReadInt32()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*MetadataResponseTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &MetadataResponseTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	if version >= 8 && version <= 10 {
+		v.ClusterAuthorizedOperations = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *MetadataResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &MetadataResponse{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Brokers))
+	for _, element := range v.Brokers {
+		element.encode(encoder, version)
+	}
+	if version >= 2 {
+		encoder.WriteNullableString(v.ClusterId)
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ControllerId)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	if version >= 8 && version <= 10 {
+		encoder.WriteInt32(v.ClusterAuthorizedOperations)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// MetadataResponseBroker is a struct of MetadataResponse.
+type MetadataResponseBroker struct {
+	// The broker ID.
+	NodeId int32
+	// The broker hostname.
+	Host string
+	// The broker port.
+	Port int32
+	// The rack of the broker, or null if it has not been assigned to a rack.
+	Rack string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *MetadataResponseBroker) Default() {
+	*v = MetadataResponseBroker{}
+}
+
+func (v *MetadataResponseBroker) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.NodeId = decoder.ReadInt32()
+	v.Host = decoder.ReadString()
+	v.Port = decoder.ReadInt32()
+	if version >= 1 {
+		v.Rack, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *MetadataResponseBroker) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &MetadataResponseBroker{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.NodeId)
+	encoder.WriteString(v.Host)
+	encoder.WriteInt32(v.Port)
+	if version >= 1 {
+		encoder.WriteNullableString(v.Rack)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// MetadataResponseTopic is a struct of MetadataResponse.
+type MetadataResponseTopic struct {
+	// The topic error, or 0 if there was no error.
+	ErrorCode int16
+	// The topic name. Null for non-existing topics queried by ID. This is never null when ErrorCode is zero. One of Name and TopicId is always populated.
+	Name string
+	// The topic id. Zero for non-existing topics queried by name. This is never zero when ErrorCode is zero. One of Name and TopicId is always populated.
+	TopicId uuid.UUID
+	// True if the topic is internal.
+	IsInternal bool
+	// Each partition in the topic.
+	Partitions []*MetadataResponsePartition
+	// 32-bit bitfield to represent authorized operations for this topic.
+	TopicAuthorizedOperations int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *MetadataResponseTopic) Default() {
+	*v = MetadataResponseTopic{}
+	v.TopicAuthorizedOperations = -2147483648
+}
+
+func (v *MetadataResponseTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ErrorCode = decoder.ReadInt16()
+	if version >= 12 {
+		v.Name, _ = decoder.ReadNullableString()
+	} else {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 10 {
+		v.TopicId = decoder.ReadUuid()
+	}
+	if version >= 1 {
+		v.IsInternal = decoder.ReadBool()
+	}
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*MetadataResponsePartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &MetadataResponsePartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	if version >= 8 {
+		v.TopicAuthorizedOperations = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *MetadataResponseTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &MetadataResponseTopic{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	if version >= 12 {
+		encoder.WriteNullableString(v.Name)
+	} else {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 10 {
+		encoder.WriteUuid(v.TopicId)
+	}
+	if version >= 1 {
+		encoder.WriteBool(v.IsInternal)
+	}
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	if version >= 8 {
+		encoder.WriteInt32(v.TopicAuthorizedOperations)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// MetadataResponsePartition is a struct of MetadataResponseTopic.
+type MetadataResponsePartition struct {
+	// The partition error, or 0 if there was no error.
+	ErrorCode int16
+	// The partition index.
+	PartitionIndex int32
+	// The ID of the leader broker.
+	LeaderId int32
+	// The leader epoch of this partition.
+	LeaderEpoch int32
+	// The set of all nodes that host this partition.
+	ReplicaNodes []int32
+	// The set of nodes that are in sync with the leader for this partition.
+	IsrNodes []int32
+	// The set of offline replicas of this partition.
+	OfflineReplicas []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *MetadataResponsePartition) Default() {
+	*v = MetadataResponsePartition{}
+	v.LeaderEpoch = -1
+}
+
+func (v *MetadataResponsePartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.ErrorCode = decoder.ReadInt16()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.LeaderId = decoder.ReadInt32()
+	if version >= 7 {
+		v.LeaderEpoch = decoder.ReadInt32()
+	}
+	replicaNodesLength := decoder.ReadArrayLength()
+	v.ReplicaNodes = make([]int32, replicaNodesLength)
+	for i := range v.ReplicaNodes {
+		v.ReplicaNodes[i] = decoder.ReadInt32()
+	}
+	isrNodesLength := decoder.ReadArrayLength()
+	v.IsrNodes = make([]int32, isrNodesLength)
+	for i := range v.IsrNodes {
+		v.IsrNodes[i] = decoder.ReadInt32()
+	}
+	if version >= 5 {
+		offlineReplicasLength := decoder.ReadArrayLength()
+		v.OfflineReplicas = make([]int32, offlineReplicasLength)
+		for i := range v.OfflineReplicas {
+			v.OfflineReplicas[i] = decoder.ReadInt32()
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *MetadataResponsePartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &MetadataResponsePartition{}
+		v.Default()
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt32(v.LeaderId)
+	if version >= 7 {
+		encoder.WriteInt32(v.LeaderEpoch)
+	}
+	encoder.WriteArrayLength(len(v.ReplicaNodes))
+	for _, element := range v.ReplicaNodes {
+		encoder.WriteInt32(element)
+	}
+	encoder.WriteArrayLength(len(v.IsrNodes))
+	for _, element := range v.IsrNodes {
+		encoder.WriteInt32(element)
+	}
+	if version >= 5 {
+		encoder.WriteArrayLength(len(v.OfflineReplicas))
+		for _, element := range v.OfflineReplicas {
+			encoder.WriteInt32(element)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
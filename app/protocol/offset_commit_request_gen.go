@@ -0,0 +1,219 @@
+// Code generated by gen from schema/OffsetCommitRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// OffsetCommitRequest is the request of API key 8, versions 0 to 9.
+type OffsetCommitRequest struct {
+	// The unique group identifier.
+	GroupId string
+	// The generation of the group if using the classic group protocol or the member epoch if using the consumer protocol.
+	GenerationIdOrMemberEpoch int32
+	// The member ID assigned by the group coordinator.
+	MemberId string
+	// The unique identifier of the consumer instance provided by end user.
+	GroupInstanceId string
+	// The time period in ms to retain the offset.
+	RetentionTimeMs int64
+	// The topics to commit offsets for.
+	Topics []*OffsetCommitRequestTopic
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *OffsetCommitRequest) ApiKey() int16 {
+	return 8
+}
+
+func (v *OffsetCommitRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *OffsetCommitRequest) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *OffsetCommitRequest) IsFlexible(version int16) bool {
+	return version >= 8
+}
+
+// Decode reads version of OffsetCommitRequest from decoder.
+func (v *OffsetCommitRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("OffsetCommitRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of OffsetCommitRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *OffsetCommitRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("OffsetCommitRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitRequest) Default() {
+	*v = OffsetCommitRequest{}
+	v.GenerationIdOrMemberEpoch = -1
+	v.RetentionTimeMs = -1
+}
+
+func (v *OffsetCommitRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.GroupId = decoder.ReadString()
+	if version >= 1 {
+		v.GenerationIdOrMemberEpoch = decoder.ReadInt32()
+	}
+	if version >= 1 {
+		v.MemberId = decoder.ReadString()
+	}
+	if version >= 7 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	if version >= 2 && version <= 4 {
+		v.RetentionTimeMs = decoder.ReadInt64()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*OffsetCommitRequestTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &OffsetCommitRequestTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.GroupId)
+	if version >= 1 {
+		encoder.WriteInt32(v.GenerationIdOrMemberEpoch)
+	}
+	if version >= 1 {
+		encoder.WriteString(v.MemberId)
+	}
+	if version >= 7 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	if version >= 2 && version <= 4 {
+		encoder.WriteInt64(v.RetentionTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetCommitRequestTopic is a struct of OffsetCommitRequest.
+type OffsetCommitRequestTopic struct {
+	// The topic name.
+	Name string
+	// Each partition to commit offsets for.
+	Partitions []*OffsetCommitRequestPartition
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitRequestTopic) Default() {
+	*v = OffsetCommitRequestTopic{}
+}
+
+func (v *OffsetCommitRequestTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*OffsetCommitRequestPartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &OffsetCommitRequestPartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitRequestTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitRequestTopic{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetCommitRequestPartition is a struct of OffsetCommitRequestTopic.
+type OffsetCommitRequestPartition struct {
+	// The partition index.
+	PartitionIndex int32
+	// The message offset to be committed.
+	CommittedOffset int64
+	// The leader epoch of this partition.
+	CommittedLeaderEpoch int32
+	// The timestamp of the commit.
+	CommitTimestamp int64
+	// Any associated metadata the client wants to keep.
+	CommittedMetadata string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitRequestPartition) Default() {
+	*v = OffsetCommitRequestPartition{}
+	v.CommittedLeaderEpoch = -1
+	v.CommitTimestamp = -1
+}
+
+func (v *OffsetCommitRequestPartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.CommittedOffset = decoder.ReadInt64()
+	if version >= 6 {
+		v.CommittedLeaderEpoch = decoder.ReadInt32()
+	}
+	if version == 1 {
+		v.CommitTimestamp = decoder.ReadInt64()
+	}
+	v.CommittedMetadata, _ = decoder.ReadNullableString()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitRequestPartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitRequestPartition{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt64(v.CommittedOffset)
+	if version >= 6 {
+		encoder.WriteInt32(v.CommittedLeaderEpoch)
+	}
+	if version == 1 {
+		encoder.WriteInt64(v.CommitTimestamp)
+	}
+	encoder.WriteNullableString(v.CommittedMetadata)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
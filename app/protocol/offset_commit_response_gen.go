@@ -0,0 +1,167 @@
+// Code generated by gen from schema/OffsetCommitResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// OffsetCommitResponse is the response of API key 8, versions 0 to 9.
+type OffsetCommitResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The responses for each topic.
+	Topics []*OffsetCommitResponseTopic
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *OffsetCommitResponse) ApiKey() int16 {
+	return 8
+}
+
+func (v *OffsetCommitResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *OffsetCommitResponse) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *OffsetCommitResponse) IsFlexible(version int16) bool {
+	return version >= 8
+}
+
+// Decode reads version of OffsetCommitResponse from decoder.
+func (v *OffsetCommitResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("OffsetCommitResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of OffsetCommitResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *OffsetCommitResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("OffsetCommitResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitResponse) Default() {
+	*v = OffsetCommitResponse{}
+}
+
+func (v *OffsetCommitResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	topicsLength := decoder.ReadArrayLength()
+	v.Topics = make([]*OffsetCommitResponseTopic, topicsLength)
+	for i := range v.Topics {
+		v.Topics[i] = &OffsetCommitResponseTopic{}
+		v.Topics[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitResponse{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteArrayLength(len(v.Topics))
+	for _, element := range v.Topics {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetCommitResponseTopic is a struct of OffsetCommitResponse.
+type OffsetCommitResponseTopic struct {
+	// The topic name.
+	Name string
+	// The responses for each partition in the topic.
+	Partitions []*OffsetCommitResponsePartition
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitResponseTopic) Default() {
+	*v = OffsetCommitResponseTopic{}
+}
+
+func (v *OffsetCommitResponseTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionsLength := decoder.ReadArrayLength()
+	v.Partitions = make([]*OffsetCommitResponsePartition, partitionsLength)
+	for i := range v.Partitions {
+		v.Partitions[i] = &OffsetCommitResponsePartition{}
+		v.Partitions[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitResponseTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitResponseTopic{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.Partitions))
+	for _, element := range v.Partitions {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetCommitResponsePartition is a struct of OffsetCommitResponseTopic.
+type OffsetCommitResponsePartition struct {
+	// The partition index.
+	PartitionIndex int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetCommitResponsePartition) Default() {
+	*v = OffsetCommitResponsePartition{}
+}
+
+func (v *OffsetCommitResponsePartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.PartitionIndex = decoder.ReadInt32()
+	v.ErrorCode = decoder.ReadInt16()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetCommitResponsePartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetCommitResponsePartition{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.PartitionIndex)
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
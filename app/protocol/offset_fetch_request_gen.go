@@ -0,0 +1,312 @@
+// Code generated by gen from schema/OffsetFetchRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// OffsetFetchRequest is the request of API key 9, versions 0 to 9.
+type OffsetFetchRequest struct {
+	// The group to fetch offsets for.
+	GroupId string
+	// Each topic we would like to fetch offsets for, or null to fetch offsets for all topics.
+	Topics []*OffsetFetchRequestTopic
+	// Each group we would like to fetch offsets for
+	Groups []*OffsetFetchRequestGroup
+	// Whether broker should hold on returning unstable offsets but set a retriable error code for the partitions.
+	RequireStable bool
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *OffsetFetchRequest) ApiKey() int16 {
+	return 9
+}
+
+func (v *OffsetFetchRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *OffsetFetchRequest) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *OffsetFetchRequest) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of OffsetFetchRequest from decoder.
+func (v *OffsetFetchRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("OffsetFetchRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of OffsetFetchRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *OffsetFetchRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("OffsetFetchRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchRequest) Default() {
+	*v = OffsetFetchRequest{}
+}
+
+func (v *OffsetFetchRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 7 {
+		v.GroupId = decoder.ReadString()
+	}
+	if version <= 7 {
+		if version >= 2 {
+			topicsLength := decoder.ReadNullableArrayLength()
+			if topicsLength >= 0 {
+				v.Topics = make([]*OffsetFetchRequestTopic, topicsLength)
+				for i := range v.Topics {
+					v.Topics[i] = &OffsetFetchRequestTopic{}
+					v.Topics[i].decode(decoder, version)
+				}
+			}
+		} else {
+			topicsLength := decoder.ReadArrayLength()
+			v.Topics = make([]*OffsetFetchRequestTopic, topicsLength)
+			for i := range v.Topics {
+				v.Topics[i] = &OffsetFetchRequestTopic{}
+				v.Topics[i].decode(decoder, version)
+			}
+		}
+	}
+	if version >= 8 {
+		groupsLength := decoder.ReadArrayLength()
+		v.Groups = make([]*OffsetFetchRequestGroup, groupsLength)
+		for i := range v.Groups {
+			v.Groups[i] = &OffsetFetchRequestGroup{}
+			v.Groups[i].decode(decoder, version)
+		}
+	}
+	if version >= 7 {
+		v.RequireStable = decoder.ReadBool()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchRequest{}
+		v.Default()
+	}
+	if version <= 7 {
+		encoder.WriteString(v.GroupId)
+	}
+	if version <= 7 {
+		if version >= 2 {
+			if v.Topics == nil {
+				encoder.WriteArrayLength(-1)
+			} else {
+				encoder.WriteArrayLength(len(v.Topics))
+				for _, element := range v.Topics {
+					element.encode(encoder, version)
+				}
+			}
+		} else {
+			encoder.WriteArrayLength(len(v.Topics))
+			for _, element := range v.Topics {
+				element.encode(encoder, version)
+			}
+		}
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.Groups))
+		for _, element := range v.Groups {
+			element.encode(encoder, version)
+		}
+	}
+	if version >= 7 {
+		encoder.WriteBool(v.RequireStable)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchRequestTopic is a struct of OffsetFetchRequest.
+type OffsetFetchRequestTopic struct {
+	// The topic name.
+	Name string
+	// The partition indexes we would like to fetch offsets for.
+	PartitionIndexes []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchRequestTopic) Default() {
+	*v = OffsetFetchRequestTopic{}
+}
+
+func (v *OffsetFetchRequestTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 7 {
+		v.Name = decoder.ReadString()
+	}
+	if version <= 7 {
+		partitionIndexesLength := decoder.ReadArrayLength()
+		v.PartitionIndexes = make([]int32, partitionIndexesLength)
+		for i := range v.PartitionIndexes {
+			v.PartitionIndexes[i] = decoder.ReadInt32()
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchRequestTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchRequestTopic{}
+		v.Default()
+	}
+	if version <= 7 {
+		encoder.WriteString(v.Name)
+	}
+	if version <= 7 {
+		encoder.WriteArrayLength(len(v.PartitionIndexes))
+		for _, element := range v.PartitionIndexes {
+			encoder.WriteInt32(element)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchRequestGroup is a struct of OffsetFetchRequest.
+type OffsetFetchRequestGroup struct {
+	// The group ID.
+	GroupId string
+	// The member ID assigned by the group coordinator if using the new consumer protocol (KIP-848).
+	MemberId string
+	// The member epoch if using the new consumer protocol (KIP-848).
+	MemberEpoch int32
+	// Each topic we would like to fetch offsets for, or null to fetch offsets for all topics.
+	Topics []*OffsetFetchRequestTopics
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchRequestGroup) Default() {
+	*v = OffsetFetchRequestGroup{}
+	v.MemberEpoch = -1
+}
+
+func (v *OffsetFetchRequestGroup) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.GroupId = decoder.ReadString()
+	}
+	if version >= 9 {
+		v.MemberId, _ = decoder.ReadNullableString()
+	}
+	if version >= 9 {
+		v.MemberEpoch = decoder.ReadInt32()
+	}
+	if version >= 8 {
+		topicsLength := decoder.ReadNullableArrayLength()
+		if topicsLength >= 0 {
+			v.Topics = make([]*OffsetFetchRequestTopics, topicsLength)
+			for i := range v.Topics {
+				v.Topics[i] = &OffsetFetchRequestTopics{}
+				v.Topics[i].decode(decoder, version)
+			}
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchRequestGroup) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchRequestGroup{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteString(v.GroupId)
+	}
+	if version >= 9 {
+		encoder.WriteNullableString(v.MemberId)
+	}
+	if version >= 9 {
+		encoder.WriteInt32(v.MemberEpoch)
+	}
+	if version >= 8 {
+		if v.Topics == nil {
+			encoder.WriteArrayLength(-1)
+		} else {
+			encoder.WriteArrayLength(len(v.Topics))
+			for _, element := range v.Topics {
+				element.encode(encoder, version)
+			}
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchRequestTopics is a struct of OffsetFetchRequestGroup.
+type OffsetFetchRequestTopics struct {
+	// The topic name.
+	Name string
+	// The partition indexes we would like to fetch offsets for.
+	PartitionIndexes []int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchRequestTopics) Default() {
+	*v = OffsetFetchRequestTopics{}
+}
+
+func (v *OffsetFetchRequestTopics) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 8 {
+		partitionIndexesLength := decoder.ReadArrayLength()
+		v.PartitionIndexes = make([]int32, partitionIndexesLength)
+		for i := range v.PartitionIndexes {
+			v.PartitionIndexes[i] = decoder.ReadInt32()
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchRequestTopics) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchRequestTopics{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.PartitionIndexes))
+		for _, element := range v.PartitionIndexes {
+			encoder.WriteInt32(element)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,411 @@
+// Code generated by gen from schema/OffsetFetchResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// OffsetFetchResponse is the response of API key 9, versions 0 to 9.
+type OffsetFetchResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The responses per topic.
+	Topics []*OffsetFetchResponseTopic
+	// The top-level error code, or 0 if there was no error.
+	ErrorCode int16
+	// The responses per group id.
+	Groups []*OffsetFetchResponseGroup
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *OffsetFetchResponse) ApiKey() int16 {
+	return 9
+}
+
+func (v *OffsetFetchResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *OffsetFetchResponse) MaxVersion() int16 {
+	return 9
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *OffsetFetchResponse) IsFlexible(version int16) bool {
+	return version >= 6
+}
+
+// Decode reads version of OffsetFetchResponse from decoder.
+func (v *OffsetFetchResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 9 {
+		return fmt.Errorf("OffsetFetchResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of OffsetFetchResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *OffsetFetchResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 9 {
+		encoder.fail(fmt.Errorf("OffsetFetchResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponse) Default() {
+	*v = OffsetFetchResponse{}
+}
+
+func (v *OffsetFetchResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	if version <= 7 {
+		topicsLength := decoder.ReadArrayLength()
+		v.Topics = make([]*OffsetFetchResponseTopic, topicsLength)
+		for i := range v.Topics {
+			v.Topics[i] = &OffsetFetchResponseTopic{}
+			v.Topics[i].decode(decoder, version)
+		}
+	}
+	if version >= 2 && version <= 7 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	if version >= 8 {
+		groupsLength := decoder.ReadArrayLength()
+		v.Groups = make([]*OffsetFetchResponseGroup, groupsLength)
+		for i := range v.Groups {
+			v.Groups[i] = &OffsetFetchResponseGroup{}
+			v.Groups[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponse{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	if version <= 7 {
+		encoder.WriteArrayLength(len(v.Topics))
+		for _, element := range v.Topics {
+			element.encode(encoder, version)
+		}
+	}
+	if version >= 2 && version <= 7 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.Groups))
+		for _, element := range v.Groups {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchResponseTopic is a struct of OffsetFetchResponse.
+type OffsetFetchResponseTopic struct {
+	// The topic name.
+	Name string
+	// The responses per partition
+	Partitions []*OffsetFetchResponsePartition
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponseTopic) Default() {
+	*v = OffsetFetchResponseTopic{}
+}
+
+func (v *OffsetFetchResponseTopic) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 7 {
+		v.Name = decoder.ReadString()
+	}
+	if version <= 7 {
+		partitionsLength := decoder.ReadArrayLength()
+		v.Partitions = make([]*OffsetFetchResponsePartition, partitionsLength)
+		for i := range v.Partitions {
+			v.Partitions[i] = &OffsetFetchResponsePartition{}
+			v.Partitions[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponseTopic) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponseTopic{}
+		v.Default()
+	}
+	if version <= 7 {
+		encoder.WriteString(v.Name)
+	}
+	if version <= 7 {
+		encoder.WriteArrayLength(len(v.Partitions))
+		for _, element := range v.Partitions {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchResponsePartition is a struct of OffsetFetchResponseTopic.
+type OffsetFetchResponsePartition struct {
+	// The partition index.
+	PartitionIndex int32
+	// The committed message offset.
+	CommittedOffset int64
+	// The leader epoch.
+	CommittedLeaderEpoch int32
+	// The partition metadata.
+	Metadata string
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponsePartition) Default() {
+	*v = OffsetFetchResponsePartition{}
+	v.CommittedLeaderEpoch = -1
+}
+
+func (v *OffsetFetchResponsePartition) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version <= 7 {
+		v.PartitionIndex = decoder.ReadInt32()
+	}
+	if version <= 7 {
+		v.CommittedOffset = decoder.ReadInt64()
+	}
+	if version >= 5 && version <= 7 {
+		v.CommittedLeaderEpoch = decoder.ReadInt32()
+	}
+	if version <= 7 {
+		v.Metadata, _ = decoder.ReadNullableString()
+	}
+	if version <= 7 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponsePartition) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponsePartition{}
+		v.Default()
+	}
+	if version <= 7 {
+		encoder.WriteInt32(v.PartitionIndex)
+	}
+	if version <= 7 {
+		encoder.WriteInt64(v.CommittedOffset)
+	}
+	if version >= 5 && version <= 7 {
+		encoder.WriteInt32(v.CommittedLeaderEpoch)
+	}
+	if version <= 7 {
+		encoder.WriteString(v.Metadata)
+	}
+	if version <= 7 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchResponseGroup is a struct of OffsetFetchResponse.
+type OffsetFetchResponseGroup struct {
+	// The group ID.
+	GroupId string
+	// The responses per topic.
+	Topics []*OffsetFetchResponseTopics
+	// The group-level error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponseGroup) Default() {
+	*v = OffsetFetchResponseGroup{}
+}
+
+func (v *OffsetFetchResponseGroup) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.GroupId = decoder.ReadString()
+	}
+	if version >= 8 {
+		topicsLength := decoder.ReadArrayLength()
+		v.Topics = make([]*OffsetFetchResponseTopics, topicsLength)
+		for i := range v.Topics {
+			v.Topics[i] = &OffsetFetchResponseTopics{}
+			v.Topics[i].decode(decoder, version)
+		}
+	}
+	if version >= 8 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponseGroup) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponseGroup{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteString(v.GroupId)
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.Topics))
+		for _, element := range v.Topics {
+			element.encode(encoder, version)
+		}
+	}
+	if version >= 8 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchResponseTopics is a struct of OffsetFetchResponseGroup.
+type OffsetFetchResponseTopics struct {
+	// The topic name.
+	Name string
+	// The responses per partition
+	Partitions []*OffsetFetchResponsePartitions
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponseTopics) Default() {
+	*v = OffsetFetchResponseTopics{}
+}
+
+func (v *OffsetFetchResponseTopics) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.Name = decoder.ReadString()
+	}
+	if version >= 8 {
+		partitionsLength := decoder.ReadArrayLength()
+		v.Partitions = make([]*OffsetFetchResponsePartitions, partitionsLength)
+		for i := range v.Partitions {
+			v.Partitions[i] = &OffsetFetchResponsePartitions{}
+			v.Partitions[i].decode(decoder, version)
+		}
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponseTopics) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponseTopics{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteString(v.Name)
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.Partitions))
+		for _, element := range v.Partitions {
+			element.encode(encoder, version)
+		}
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// OffsetFetchResponsePartitions is a struct of OffsetFetchResponseTopics.
+type OffsetFetchResponsePartitions struct {
+	// The partition index.
+	PartitionIndex int32
+	// The committed message offset.
+	CommittedOffset int64
+	// The leader epoch.
+	CommittedLeaderEpoch int32
+	// The partition metadata.
+	Metadata string
+	// The partition-level error code, or 0 if there was no error.
+	ErrorCode int16
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *OffsetFetchResponsePartitions) Default() {
+	*v = OffsetFetchResponsePartitions{}
+	v.CommittedLeaderEpoch = -1
+}
+
+func (v *OffsetFetchResponsePartitions) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.PartitionIndex = decoder.ReadInt32()
+	}
+	if version >= 8 {
+		v.CommittedOffset = decoder.ReadInt64()
+	}
+	if version >= 8 {
+		v.CommittedLeaderEpoch = decoder.ReadInt32()
+	}
+	if version >= 8 {
+		v.Metadata, _ = decoder.ReadNullableString()
+	}
+	if version >= 8 {
+		v.ErrorCode = decoder.ReadInt16()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *OffsetFetchResponsePartitions) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &OffsetFetchResponsePartitions{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteInt32(v.PartitionIndex)
+	}
+	if version >= 8 {
+		encoder.WriteInt64(v.CommittedOffset)
+	}
+	if version >= 8 {
+		encoder.WriteInt32(v.CommittedLeaderEpoch)
+	}
+	if version >= 8 {
+		encoder.WriteString(v.Metadata)
+	}
+	if version >= 8 {
+		encoder.WriteInt16(v.ErrorCode)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,175 @@
+// Code generated by gen from schema/ProduceRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// ProduceRequest is the request of API key 0, versions 0 to 11.
+type ProduceRequest struct {
+	// The transactional ID, or null if the producer is not transactional.
+	TransactionalId string
+	// The number of acknowledgments the producer requires the leader to have received before considering a request complete. Allowed values: 0 for no acknowledgments, 1 for only the leader and -1 for the full ISR.
+	Acks int16
+	// The timeout to await a response in milliseconds.
+	TimeoutMs int32
+	// Each topic to produce to.
+	TopicData []*ProduceRequestTopicProduceData
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ProduceRequest) ApiKey() int16 {
+	return 0
+}
+
+func (v *ProduceRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *ProduceRequest) MaxVersion() int16 {
+	return 11
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ProduceRequest) IsFlexible(version int16) bool {
+	return version >= 9
+}
+
+// Decode reads version of ProduceRequest from decoder.
+func (v *ProduceRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 11 {
+		return fmt.Errorf("ProduceRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ProduceRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *ProduceRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 11 {
+		encoder.fail(fmt.Errorf("ProduceRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceRequest) Default() {
+	*v = ProduceRequest{}
+}
+
+func (v *ProduceRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 3 {
+		v.TransactionalId, _ = decoder.ReadNullableString()
+	}
+	v.Acks = decoder.ReadInt16()
+	v.TimeoutMs = decoder.ReadInt32()
+	topicDataLength := decoder.ReadArrayLength()
+	v.TopicData = make([]*ProduceRequestTopicProduceData, topicDataLength)
+	for i := range v.TopicData {
+		v.TopicData[i] = &ProduceRequestTopicProduceData{}
+		v.TopicData[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceRequest{}
+		v.Default()
+	}
+	if version >= 3 {
+		encoder.WriteNullableString(v.TransactionalId)
+	}
+	encoder.WriteInt16(v.Acks)
+	encoder.WriteInt32(v.TimeoutMs)
+	encoder.WriteArrayLength(len(v.TopicData))
+	for _, element := range v.TopicData {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ProduceRequestTopicProduceData is a struct of ProduceRequest.
+type ProduceRequestTopicProduceData struct {
+	// The topic name.
+	Name string
+	// Each partition to produce to.
+	PartitionData []*ProduceRequestPartitionProduceData
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceRequestTopicProduceData) Default() {
+	*v = ProduceRequestTopicProduceData{}
+}
+
+func (v *ProduceRequestTopicProduceData) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionDataLength := decoder.ReadArrayLength()
+	v.PartitionData = make([]*ProduceRequestPartitionProduceData, partitionDataLength)
+	for i := range v.PartitionData {
+		v.PartitionData[i] = &ProduceRequestPartitionProduceData{}
+		v.PartitionData[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceRequestTopicProduceData) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceRequestTopicProduceData{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.PartitionData))
+	for _, element := range v.PartitionData {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ProduceRequestPartitionProduceData is a struct of ProduceRequestTopicProduceData.
+type ProduceRequestPartitionProduceData struct {
+	// The partition index.
+	Index int32
+	// The record data to be produced.
+	Records []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceRequestPartitionProduceData) Default() {
+	*v = ProduceRequestPartitionProduceData{}
+}
+
+func (v *ProduceRequestPartitionProduceData) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Index = decoder.ReadInt32()
+	v.Records = decoder.ReadNullableBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceRequestPartitionProduceData) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceRequestPartitionProduceData{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.Index)
+	encoder.WriteNullableBytes(v.Records)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
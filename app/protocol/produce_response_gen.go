@@ -0,0 +1,406 @@
+// Code generated by gen from schema/ProduceResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+	"maps"
+)
+
+// ProduceResponse is the response of API key 0, versions 0 to 11.
+type ProduceResponse struct {
+	// Each produce response
+	Responses []*ProduceResponseTopicProduceResponse
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// Endpoints for all current-leaders enumerated in PartitionProduceResponses, with errors NOT_LEADER_OR_FOLLOWER.
+	NodeEndpoints []*ProduceResponseNodeEndpoint
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *ProduceResponse) ApiKey() int16 {
+	return 0
+}
+
+func (v *ProduceResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *ProduceResponse) MaxVersion() int16 {
+	return 11
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *ProduceResponse) IsFlexible(version int16) bool {
+	return version >= 9
+}
+
+// Decode reads version of ProduceResponse from decoder.
+func (v *ProduceResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 11 {
+		return fmt.Errorf("ProduceResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of ProduceResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *ProduceResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 11 {
+		encoder.fail(fmt.Errorf("ProduceResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponse) Default() {
+	*v = ProduceResponse{}
+}
+
+func (v *ProduceResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	responsesLength := decoder.ReadArrayLength()
+	v.Responses = make([]*ProduceResponseTopicProduceResponse, responsesLength)
+	for i := range v.Responses {
+		v.Responses[i] = &ProduceResponseTopicProduceResponse{}
+		v.Responses[i].decode(decoder, version)
+	}
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 10 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		nodeEndpointsLength := tagged.ReadArrayLength()
+		v.NodeEndpoints = make([]*ProduceResponseNodeEndpoint, nodeEndpointsLength)
+		for i := range v.NodeEndpoints {
+			v.NodeEndpoints[i] = &ProduceResponseNodeEndpoint{}
+			v.NodeEndpoints[i].decode(tagged, version)
+		}
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+}
+
+func (v *ProduceResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponse{}
+		v.Default()
+	}
+	encoder.WriteArrayLength(len(v.Responses))
+	for _, element := range v.Responses {
+		element.encode(encoder, version)
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 10 && len(v.NodeEndpoints) > 0 {
+		tagged := NewEncoder(true)
+		tagged.WriteArrayLength(len(v.NodeEndpoints))
+		for _, element := range v.NodeEndpoints {
+			element.encode(tagged, version)
+		}
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// ProduceResponseTopicProduceResponse is a struct of ProduceResponse.
+type ProduceResponseTopicProduceResponse struct {
+	// The topic name
+	Name string
+	// Each partition that we produced to within the topic.
+	PartitionResponses []*ProduceResponsePartitionProduceResponse
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponseTopicProduceResponse) Default() {
+	*v = ProduceResponseTopicProduceResponse{}
+}
+
+func (v *ProduceResponseTopicProduceResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Name = decoder.ReadString()
+	partitionResponsesLength := decoder.ReadArrayLength()
+	v.PartitionResponses = make([]*ProduceResponsePartitionProduceResponse, partitionResponsesLength)
+	for i := range v.PartitionResponses {
+		v.PartitionResponses[i] = &ProduceResponsePartitionProduceResponse{}
+		v.PartitionResponses[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceResponseTopicProduceResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponseTopicProduceResponse{}
+		v.Default()
+	}
+	encoder.WriteString(v.Name)
+	encoder.WriteArrayLength(len(v.PartitionResponses))
+	for _, element := range v.PartitionResponses {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ProduceResponsePartitionProduceResponse is a struct of ProduceResponseTopicProduceResponse.
+type ProduceResponsePartitionProduceResponse struct {
+	// The partition index.
+	Index int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The base offset.
+	BaseOffset int64
+	// The timestamp returned by broker after appending the messages. If CreateTime is used for the topic, the timestamp will be -1.  If LogAppendTime is used for the topic, the timestamp will be the broker local time when the messages are appended.
+	LogAppendTimeMs int64
+	// The log start offset.
+	LogStartOffset int64
+	// The batch indices of records that caused the batch to be dropped
+	RecordErrors []*ProduceResponseBatchIndexAndErrorMessage
+	// The global error message summarizing the common root cause of the records that caused the batch to be dropped
+	ErrorMessage  string
+	CurrentLeader *ProduceResponseLeaderIdAndEpoch
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponsePartitionProduceResponse) Default() {
+	*v = ProduceResponsePartitionProduceResponse{}
+	v.LogAppendTimeMs = -1
+	v.LogStartOffset = -1
+}
+
+func (v *ProduceResponsePartitionProduceResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.Index = decoder.ReadInt32()
+	v.ErrorCode = decoder.ReadInt16()
+	v.BaseOffset = decoder.ReadInt64()
+	if version >= 2 {
+		v.LogAppendTimeMs = decoder.ReadInt64()
+	}
+	if version >= 5 {
+		v.LogStartOffset = decoder.ReadInt64()
+	}
+	if version >= 8 {
+		recordErrorsLength := decoder.ReadArrayLength()
+		v.RecordErrors = make([]*ProduceResponseBatchIndexAndErrorMessage, recordErrorsLength)
+		for i := range v.RecordErrors {
+			v.RecordErrors[i] = &ProduceResponseBatchIndexAndErrorMessage{}
+			v.RecordErrors[i].decode(decoder, version)
+		}
+	}
+	if version >= 8 {
+		v.ErrorMessage, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+	if data, ok := v.UnknownTaggedFields[0]; ok && version >= 10 {
+		delete(v.UnknownTaggedFields, 0)
+		tagged := NewDecoder(data, true)
+		v.CurrentLeader = &ProduceResponseLeaderIdAndEpoch{}
+		v.CurrentLeader.decode(tagged, version)
+		if err := tagged.Err(); err != nil {
+			decoder.fail(fmt.Errorf("tagged field 0: %w", err))
+		}
+	}
+}
+
+func (v *ProduceResponsePartitionProduceResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponsePartitionProduceResponse{}
+		v.Default()
+	}
+	encoder.WriteInt32(v.Index)
+	encoder.WriteInt16(v.ErrorCode)
+	encoder.WriteInt64(v.BaseOffset)
+	if version >= 2 {
+		encoder.WriteInt64(v.LogAppendTimeMs)
+	}
+	if version >= 5 {
+		encoder.WriteInt64(v.LogStartOffset)
+	}
+	if version >= 8 {
+		encoder.WriteArrayLength(len(v.RecordErrors))
+		for _, element := range v.RecordErrors {
+			element.encode(encoder, version)
+		}
+	}
+	if version >= 8 {
+		encoder.WriteNullableString(v.ErrorMessage)
+	}
+	taggedFields := maps.Clone(v.UnknownTaggedFields)
+	if taggedFields == nil {
+		taggedFields = map[uint64][]byte{}
+	}
+	if version >= 10 && v.CurrentLeader != nil {
+		tagged := NewEncoder(true)
+		v.CurrentLeader.encode(tagged, version)
+		encoder.fail(tagged.Err())
+		taggedFields[0] = tagged.Bytes()
+	}
+	encoder.WriteTaggedFields(taggedFields)
+}
+
+// ProduceResponseBatchIndexAndErrorMessage is a struct of ProduceResponsePartitionProduceResponse.
+type ProduceResponseBatchIndexAndErrorMessage struct {
+	// The batch index of the record that cause the batch to be dropped
+	BatchIndex int32
+	// The error message of the record that caused the batch to be dropped
+	BatchIndexErrorMessage string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponseBatchIndexAndErrorMessage) Default() {
+	*v = ProduceResponseBatchIndexAndErrorMessage{}
+}
+
+func (v *ProduceResponseBatchIndexAndErrorMessage) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 8 {
+		v.BatchIndex = decoder.ReadInt32()
+	}
+	if version >= 8 {
+		v.BatchIndexErrorMessage, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceResponseBatchIndexAndErrorMessage) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponseBatchIndexAndErrorMessage{}
+		v.Default()
+	}
+	if version >= 8 {
+		encoder.WriteInt32(v.BatchIndex)
+	}
+	if version >= 8 {
+		encoder.WriteNullableString(v.BatchIndexErrorMessage)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ProduceResponseLeaderIdAndEpoch is a struct of ProduceResponsePartitionProduceResponse.
+type ProduceResponseLeaderIdAndEpoch struct {
+	// The ID of the current leader or -1 if the leader is unknown.
+	LeaderId int32
+	// The latest known leader epoch
+	LeaderEpoch int32
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponseLeaderIdAndEpoch) Default() {
+	*v = ProduceResponseLeaderIdAndEpoch{}
+	v.LeaderId = -1
+	v.LeaderEpoch = -1
+}
+
+func (v *ProduceResponseLeaderIdAndEpoch) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 10 {
+		v.LeaderId = decoder.ReadInt32()
+	}
+	if version >= 10 {
+		v.LeaderEpoch = decoder.ReadInt32()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceResponseLeaderIdAndEpoch) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponseLeaderIdAndEpoch{}
+		v.Default()
+	}
+	if version >= 10 {
+		encoder.WriteInt32(v.LeaderId)
+	}
+	if version >= 10 {
+		encoder.WriteInt32(v.LeaderEpoch)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// ProduceResponseNodeEndpoint is a struct of ProduceResponse.
+type ProduceResponseNodeEndpoint struct {
+	// The ID of the associated node.
+	NodeId int32
+	// The node's hostname.
+	Host string
+	// The node's port.
+	Port int32
+	// The rack of the node, or null if it has not been assigned to a rack.
+	Rack string
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *ProduceResponseNodeEndpoint) Default() {
+	*v = ProduceResponseNodeEndpoint{}
+}
+
+func (v *ProduceResponseNodeEndpoint) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 10 {
+		v.NodeId = decoder.ReadInt32()
+	}
+	if version >= 10 {
+		v.Host = decoder.ReadString()
+	}
+	if version >= 10 {
+		v.Port = decoder.ReadInt32()
+	}
+	if version >= 10 {
+		v.Rack, _ = decoder.ReadNullableString()
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *ProduceResponseNodeEndpoint) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &ProduceResponseNodeEndpoint{}
+		v.Default()
+	}
+	if version >= 10 {
+		encoder.WriteInt32(v.NodeId)
+	}
+	if version >= 10 {
+		encoder.WriteString(v.Host)
+	}
+	if version >= 10 {
+		encoder.WriteInt32(v.Port)
+	}
+	if version >= 10 {
+		encoder.WriteNullableString(v.Rack)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,259 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	id := uuid.MustParse("5f1d1c5e-8c6b-4d1a-9d3f-0a2b4c6d8e10")
+	taggedFields := map[uint64][]byte{7: {1, 2}, 0: {}, 300: []byte("late")}
+
+	for _, flexible := range []bool{false, true} {
+		encoder := NewEncoder(flexible)
+		encoder.WriteInt8(-3)
+		encoder.WriteBool(true)
+		encoder.WriteInt16(-300)
+		encoder.WriteUint16(65000)
+		encoder.WriteInt32(-70000)
+		encoder.WriteUint32(4_000_000_000)
+		encoder.WriteInt64(-1 << 40)
+		encoder.WriteFloat64(0.25)
+		encoder.WriteUvarint(300)
+		encoder.WriteVarint(-300)
+		encoder.WriteUuid(id)
+		encoder.WriteString("topic")
+		encoder.WriteString("")
+		encoder.WriteNullableString("")
+		encoder.WriteNullableString("group")
+		encoder.WriteBytes([]byte{9, 8})
+		encoder.WriteNullableBytes(nil)
+		encoder.WriteNullableBytes([]byte{})
+		encoder.WriteArrayLength(-1)
+		encoder.WriteInt32Array([]int32{1, -2, 3})
+		encoder.WriteTaggedFields(taggedFields)
+		encoder.WriteRaw([]byte("raw"))
+		if err := encoder.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		decoder := NewDecoder(encoder.Bytes(), flexible)
+		got := []any{
+			decoder.ReadInt8(),
+			decoder.ReadBool(),
+			decoder.ReadInt16(),
+			decoder.ReadUint16(),
+			decoder.ReadInt32(),
+			decoder.ReadUint32(),
+			decoder.ReadInt64(),
+			decoder.ReadFloat64(),
+			decoder.ReadUvarint(),
+			decoder.ReadVarint(),
+			decoder.ReadUuid(),
+			decoder.ReadString(),
+			decoder.ReadString(),
+		}
+		nullString, nullOk := decoder.ReadNullableString()
+		group, groupOk := decoder.ReadNullableString()
+		got = append(got,
+			nullString, nullOk, group, groupOk,
+			decoder.ReadBytes(),
+			decoder.ReadNullableBytes(),
+			decoder.ReadNullableBytes(),
+			decoder.ReadNullableArrayLength(),
+			decoder.ReadInt32Array(),
+			decoder.ReadTaggedFields(),
+			decoder.ReadRaw(3),
+		)
+		if err := decoder.Err(); err != nil {
+			t.Fatalf("flexible %t: %s", flexible, err)
+		}
+		if decoder.Remaining() != 0 {
+			t.Fatalf("flexible %t: %d bytes left", flexible, decoder.Remaining())
+		}
+
+		var wantTaggedFields map[uint64][]byte
+		if flexible {
+			wantTaggedFields = taggedFields
+		}
+		want := []any{
+			int8(-3), true, int16(-300), uint16(65000), int32(-70000), uint32(4_000_000_000),
+			int64(-1 << 40), 0.25, uint64(300), int64(-300), id, "topic", "",
+			"", false, "group", true,
+			[]byte{9, 8}, []byte(nil), []byte{}, -1, []int32{1, -2, 3},
+			wantTaggedFields, []byte("raw"),
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("flexible %t: decoded %v, want %v", flexible, got, want)
+		}
+	}
+}
+
+func TestEncoderCompactLengths(t *testing.T) {
+	tests := []struct {
+		name     string
+		flexible bool
+		write    func(encoder *Encoder)
+		want     []byte
+	}{
+		{"string", false, func(encoder *Encoder) { encoder.WriteString("ab") }, []byte{0, 2, 'a', 'b'}},
+		{"compact string", true, func(encoder *Encoder) { encoder.WriteString("ab") }, []byte{3, 'a', 'b'}},
+		{"null string", false, func(encoder *Encoder) { encoder.WriteNullableString("") }, []byte{0xff, 0xff}},
+		{"compact null string", true, func(encoder *Encoder) { encoder.WriteNullableString("") }, []byte{0}},
+		{"null bytes", false, func(encoder *Encoder) { encoder.WriteNullableBytes(nil) }, []byte{0xff, 0xff, 0xff, 0xff}},
+		{"compact array", true, func(encoder *Encoder) { encoder.WriteInt32Array([]int32{1}) }, []byte{2, 0, 0, 0, 1}},
+		{"no tagged fields", false, func(encoder *Encoder) { encoder.WriteTaggedFields(map[uint64][]byte{1: {1}}) }, nil},
+		{"empty tagged fields", true, func(encoder *Encoder) { encoder.WriteTaggedFields(nil) }, []byte{0}},
+		{"tagged fields sorted", true, func(encoder *Encoder) {
+			encoder.WriteTaggedFields(map[uint64][]byte{2: {0xbb}, 1: {0xaa}})
+		}, []byte{2, 1, 1, 0xaa, 2, 1, 0xbb}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoder := NewEncoder(test.flexible)
+			test.write(encoder)
+			if !bytes.Equal(encoder.Bytes(), test.want) {
+				t.Fatalf("encoded % x, want % x", encoder.Bytes(), test.want)
+			}
+		})
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		flexible bool
+		read     func(decoder *Decoder)
+		err      error
+	}{
+		{"truncated int32", []byte{0, 0, 1}, false, func(decoder *Decoder) { decoder.ReadInt32() }, ErrUnexpectedEnd},
+		{"truncated uvarint", []byte{0x80}, false, func(decoder *Decoder) { decoder.ReadUvarint() }, ErrUnexpectedEnd},
+		{"overlong varint", bytes.Repeat([]byte{0xff}, 11), false, func(decoder *Decoder) { decoder.ReadVarint() }, ErrInvalidVarint},
+		{"string past the end", []byte{0, 5, 'a'}, false, func(decoder *Decoder) { decoder.ReadString() }, ErrUnexpectedEnd},
+		{"null string", []byte{0xff, 0xff}, false, func(decoder *Decoder) { decoder.ReadString() }, ErrInvalidLength},
+		{"null compact bytes", []byte{0}, true, func(decoder *Decoder) { decoder.ReadBytes() }, ErrInvalidLength},
+		{"negative length", []byte{0xff, 0xfe}, false, func(decoder *Decoder) { decoder.ReadNullableString() }, ErrInvalidLength},
+		{"array past the end", []byte{0, 0, 0, 9, 0}, false, func(decoder *Decoder) { decoder.ReadInt32Array() }, ErrUnexpectedEnd},
+		{"tagged field past the end", []byte{1, 0, 5, 1}, true, func(decoder *Decoder) { decoder.ReadTaggedFields() }, ErrUnexpectedEnd},
+		{"too many tagged fields", []byte{9, 0}, true, func(decoder *Decoder) { decoder.ReadTaggedFields() }, ErrInvalidLength},
+		{"tagged fields out of order", []byte{2, 2, 0, 1, 0}, true, func(decoder *Decoder) { decoder.ReadTaggedFields() }, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			decoder := NewDecoder(test.data, test.flexible)
+			test.read(decoder)
+			err := decoder.Err()
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if test.err != nil && !errors.Is(err, test.err) {
+				t.Fatalf("error %q, want %q", err, test.err)
+			}
+
+			// the error sticks
+			if value := decoder.ReadInt8(); value != 0 || decoder.Err() != err {
+				t.Fatalf("read %d with error %v after %q", value, decoder.Err(), err)
+			}
+		})
+	}
+}
+
+func TestApiVersionsResponseRoundTrip(t *testing.T) {
+	response := &ApiVersionsResponse{
+		ApiKeys: []*ApiVersionsResponseApiVersion{
+			{ApiKey: 1, MinVersion: 0, MaxVersion: 16},
+			{ApiKey: 18, MinVersion: 0, MaxVersion: 4},
+		},
+		ThrottleTimeMs:         20,
+		SupportedFeatures:      []*ApiVersionsResponseSupportedFeatureKey{{Name: "metadata.version", MinVersion: 1, MaxVersion: 20}},
+		FinalizedFeaturesEpoch: 7,
+		FinalizedFeatures:      []*ApiVersionsResponseFinalizedFeatureKey{{Name: "metadata.version", MaxVersionLevel: 20, MinVersionLevel: 20}},
+		ZkMigrationReady:       true,
+		UnknownTaggedFields:    map[uint64][]byte{9: {0xca, 0xfe}},
+	}
+
+	tests := []struct {
+		version int16
+		// the fields the version has
+		want *ApiVersionsResponse
+	}{
+		{0, &ApiVersionsResponse{
+			ApiKeys:                response.ApiKeys,
+			FinalizedFeaturesEpoch: -1,
+		}},
+		{1, &ApiVersionsResponse{
+			ApiKeys:                response.ApiKeys,
+			ThrottleTimeMs:         20,
+			FinalizedFeaturesEpoch: -1,
+		}},
+		{3, response},
+		{4, response},
+	}
+	for _, test := range tests {
+		encoder := NewEncoder(false)
+		response.Encode(encoder, test.version)
+		if err := encoder.Err(); err != nil {
+			t.Fatalf("v%d: %s", test.version, err)
+		}
+
+		decoded := &ApiVersionsResponse{}
+		if err := decoded.Decode(NewDecoder(encoder.Bytes(), false), test.version); err != nil {
+			t.Fatalf("v%d: %s", test.version, err)
+		}
+		if !reflect.DeepEqual(withoutTaggedFields(decoded), withoutTaggedFields(test.want)) {
+			t.Fatalf("v%d: decoded %+v, want %+v", test.version, decoded, test.want)
+		}
+
+		// unknown tagged fields are kept, the known ones are taken out
+		if decoded.IsFlexible(test.version) {
+			if !reflect.DeepEqual(decoded.UnknownTaggedFields, response.UnknownTaggedFields) {
+				t.Fatalf("v%d: unknown tagged fields %v, want %v", test.version, decoded.UnknownTaggedFields, response.UnknownTaggedFields)
+			}
+		} else if decoded.UnknownTaggedFields != nil {
+			t.Fatalf("v%d: unknown tagged fields %v on a version without any", test.version, decoded.UnknownTaggedFields)
+		}
+
+		reencoder := NewEncoder(false)
+		decoded.Encode(reencoder, test.version)
+		if !bytes.Equal(reencoder.Bytes(), encoder.Bytes()) {
+			t.Fatalf("v%d: encoded again as % x, want % x", test.version, reencoder.Bytes(), encoder.Bytes())
+		}
+	}
+
+	encoder := NewEncoder(false)
+	response.Encode(encoder, 5)
+	if encoder.Err() == nil {
+		t.Fatal("v5 encoded")
+	}
+}
+
+// withoutTaggedFields returns a copy of response without the unknown tagged
+// fields, which decoding sets to an empty map on flexible versions.
+func withoutTaggedFields(response *ApiVersionsResponse) ApiVersionsResponse {
+	stripped := *response
+	stripped.UnknownTaggedFields = nil
+	stripped.ApiKeys = nil
+	for _, apiKey := range response.ApiKeys {
+		stripped.ApiKeys = append(stripped.ApiKeys, &ApiVersionsResponseApiVersion{
+			ApiKey: apiKey.ApiKey, MinVersion: apiKey.MinVersion, MaxVersion: apiKey.MaxVersion,
+		})
+	}
+	stripped.SupportedFeatures = nil
+	for _, feature := range response.SupportedFeatures {
+		stripped.SupportedFeatures = append(stripped.SupportedFeatures, &ApiVersionsResponseSupportedFeatureKey{
+			Name: feature.Name, MinVersion: feature.MinVersion, MaxVersion: feature.MaxVersion,
+		})
+	}
+	stripped.FinalizedFeatures = nil
+	for _, feature := range response.FinalizedFeatures {
+		stripped.FinalizedFeatures = append(stripped.FinalizedFeatures, &ApiVersionsResponseFinalizedFeatureKey{
+			Name: feature.Name, MaxVersionLevel: feature.MaxVersionLevel, MinVersionLevel: feature.MinVersionLevel,
+		})
+	}
+	return stripped
+}
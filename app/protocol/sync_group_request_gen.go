@@ -0,0 +1,153 @@
+// Code generated by gen from schema/SyncGroupRequest.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// SyncGroupRequest is the request of API key 14, versions 0 to 5.
+type SyncGroupRequest struct {
+	// The unique group identifier.
+	GroupId string
+	// The generation of the group.
+	GenerationId int32
+	// The member ID assigned by the group.
+	MemberId string
+	// The unique identifier of the consumer instance provided by end user.
+	GroupInstanceId string
+	// The group protocol type.
+	ProtocolType string
+	// The group protocol name.
+	ProtocolName string
+	// Each assignment.
+	Assignments []*SyncGroupRequestAssignment
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *SyncGroupRequest) ApiKey() int16 {
+	return 14
+}
+
+func (v *SyncGroupRequest) MinVersion() int16 {
+	return 0
+}
+
+func (v *SyncGroupRequest) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *SyncGroupRequest) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of SyncGroupRequest from decoder.
+func (v *SyncGroupRequest) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("SyncGroupRequest v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of SyncGroupRequest to encoder, errors are reported by
+// encoder.Err.
+func (v *SyncGroupRequest) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("SyncGroupRequest v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *SyncGroupRequest) Default() {
+	*v = SyncGroupRequest{}
+}
+
+func (v *SyncGroupRequest) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.GroupId = decoder.ReadString()
+	v.GenerationId = decoder.ReadInt32()
+	v.MemberId = decoder.ReadString()
+	if version >= 3 {
+		v.GroupInstanceId, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.ProtocolType, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.ProtocolName, _ = decoder.ReadNullableString()
+	}
+	assignmentsLength := decoder.ReadArrayLength()
+	v.Assignments = make([]*SyncGroupRequestAssignment, assignmentsLength)
+	for i := range v.Assignments {
+		v.Assignments[i] = &SyncGroupRequestAssignment{}
+		v.Assignments[i].decode(decoder, version)
+	}
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *SyncGroupRequest) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &SyncGroupRequest{}
+		v.Default()
+	}
+	encoder.WriteString(v.GroupId)
+	encoder.WriteInt32(v.GenerationId)
+	encoder.WriteString(v.MemberId)
+	if version >= 3 {
+		encoder.WriteNullableString(v.GroupInstanceId)
+	}
+	if version >= 5 {
+		encoder.WriteNullableString(v.ProtocolType)
+	}
+	if version >= 5 {
+		encoder.WriteNullableString(v.ProtocolName)
+	}
+	encoder.WriteArrayLength(len(v.Assignments))
+	for _, element := range v.Assignments {
+		element.encode(encoder, version)
+	}
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
+
+// SyncGroupRequestAssignment is a struct of SyncGroupRequest.
+type SyncGroupRequestAssignment struct {
+	// The ID of the member to assign.
+	MemberId string
+	// The member assignment.
+	Assignment []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+// Default sets the fields to their schema defaults.
+func (v *SyncGroupRequestAssignment) Default() {
+	*v = SyncGroupRequestAssignment{}
+}
+
+func (v *SyncGroupRequestAssignment) decode(decoder *Decoder, version int16) {
+	v.Default()
+	v.MemberId = decoder.ReadString()
+	v.Assignment = decoder.ReadBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *SyncGroupRequestAssignment) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &SyncGroupRequestAssignment{}
+		v.Default()
+	}
+	encoder.WriteString(v.MemberId)
+	encoder.WriteBytes(v.Assignment)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
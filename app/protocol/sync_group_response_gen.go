@@ -0,0 +1,103 @@
+// Code generated by gen from schema/SyncGroupResponse.json. DO NOT EDIT.
+
+package protocol
+
+import (
+	"fmt"
+)
+
+// SyncGroupResponse is the response of API key 14, versions 0 to 5.
+type SyncGroupResponse struct {
+	// The duration in milliseconds for which the request was throttled due to a quota violation, or zero if the request did not violate any quota.
+	ThrottleTimeMs int32
+	// The error code, or 0 if there was no error.
+	ErrorCode int16
+	// The group protocol type.
+	ProtocolType string
+	// The group protocol name.
+	ProtocolName string
+	// The member assignment.
+	Assignment []byte
+
+	// UnknownTaggedFields has the raw value of the tagged fields this
+	// schema doesn't know, by tag.
+	UnknownTaggedFields map[uint64][]byte
+}
+
+func (v *SyncGroupResponse) ApiKey() int16 {
+	return 14
+}
+
+func (v *SyncGroupResponse) MinVersion() int16 {
+	return 0
+}
+
+func (v *SyncGroupResponse) MaxVersion() int16 {
+	return 5
+}
+
+// IsFlexible reports whether version uses the compact types and tagged fields.
+func (v *SyncGroupResponse) IsFlexible(version int16) bool {
+	return version >= 4
+}
+
+// Decode reads version of SyncGroupResponse from decoder.
+func (v *SyncGroupResponse) Decode(decoder *Decoder, version int16) error {
+	if version < 0 || version > 5 {
+		return fmt.Errorf("SyncGroupResponse v%d is not supported", version)
+	}
+	decoder.Flexible = v.IsFlexible(version)
+	v.decode(decoder, version)
+	return decoder.Err()
+}
+
+// Encode writes version of SyncGroupResponse to encoder, errors are reported by
+// encoder.Err.
+func (v *SyncGroupResponse) Encode(encoder *Encoder, version int16) {
+	if version < 0 || version > 5 {
+		encoder.fail(fmt.Errorf("SyncGroupResponse v%d is not supported", version))
+		return
+	}
+	encoder.Flexible = v.IsFlexible(version)
+	v.encode(encoder, version)
+}
+
+// Default sets the fields to their schema defaults.
+func (v *SyncGroupResponse) Default() {
+	*v = SyncGroupResponse{}
+}
+
+func (v *SyncGroupResponse) decode(decoder *Decoder, version int16) {
+	v.Default()
+	if version >= 1 {
+		v.ThrottleTimeMs = decoder.ReadInt32()
+	}
+	v.ErrorCode = decoder.ReadInt16()
+	if version >= 5 {
+		v.ProtocolType, _ = decoder.ReadNullableString()
+	}
+	if version >= 5 {
+		v.ProtocolName, _ = decoder.ReadNullableString()
+	}
+	v.Assignment = decoder.ReadBytes()
+	v.UnknownTaggedFields = decoder.ReadTaggedFields()
+}
+
+func (v *SyncGroupResponse) encode(encoder *Encoder, version int16) {
+	if v == nil {
+		v = &SyncGroupResponse{}
+		v.Default()
+	}
+	if version >= 1 {
+		encoder.WriteInt32(v.ThrottleTimeMs)
+	}
+	encoder.WriteInt16(v.ErrorCode)
+	if version >= 5 {
+		encoder.WriteNullableString(v.ProtocolType)
+	}
+	if version >= 5 {
+		encoder.WriteNullableString(v.ProtocolName)
+	}
+	encoder.WriteBytes(v.Assignment)
+	encoder.WriteTaggedFields(v.UnknownTaggedFields)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+var errCorruptRecordBatch = errors.New("record batch is corrupt, its crc does not match")
+
+// newRecordBatch builds an uncompressed RecordBatch v2 holding one record per
+// value. keys may be nil, a nil key or value is written as null.
+func newRecordBatch(baseOffset int64, timestamp int64, keys [][]byte, values [][]byte) []byte {
+	records := &bytes.Buffer{}
+	for i, value := range values {
+		var key []byte
+		if keys != nil {
+			key = keys[i]
+		}
+		records.Write(encodeRecord(int64(i), 0, key, value))
+	}
+
+	batch := &bytes.Buffer{}
+	binary.Write(batch, binary.BigEndian, baseOffset)
+	// batchLength, filled in below
+	binary.Write(batch, binary.BigEndian, int32(0))
+	// partitionLeaderEpoch
+	binary.Write(batch, binary.BigEndian, int32(0))
+	// magic
+	binary.Write(batch, binary.BigEndian, int8(2))
+	// crc, filled in below
+	binary.Write(batch, binary.BigEndian, uint32(0))
+	// attributes: no compression, CreateTime, not transactional
+	binary.Write(batch, binary.BigEndian, int16(0))
+	binary.Write(batch, binary.BigEndian, int32(len(values)-1))
+	// baseTimestamp and maxTimestamp
+	binary.Write(batch, binary.BigEndian, timestamp)
+	binary.Write(batch, binary.BigEndian, timestamp)
+	// producerId, producerEpoch and baseSequence, -1 as there is no producer
+	binary.Write(batch, binary.BigEndian, int64(-1))
+	binary.Write(batch, binary.BigEndian, int16(-1))
+	binary.Write(batch, binary.BigEndian, int32(-1))
+	binary.Write(batch, binary.BigEndian, int32(len(values)))
+	batch.Write(records.Bytes())
+
+	batchBytes := batch.Bytes()
+	binary.BigEndian.PutUint32(batchBytes[recordBatchLengthPosition:], uint32(len(batchBytes)-recordBatchLogOverhead))
+	setRecordBatchCrc(batchBytes)
+	return batchBytes
+}
+
+// setRecordBatchCrc computes the CRC-32C of everything from attributes to the
+// end of the batch and stores it in the header.
+func setRecordBatchCrc(batch []byte) {
+	crc := crc32.Checksum(batch[recordBatchAttributesPosition:], castagnoliTable)
+	binary.BigEndian.PutUint32(batch[recordBatchCrcPosition:], crc)
+}
+
+// recordBatchCrcMatches reports whether the CRC-32C in the header of a whole
+// batch matches its contents.
+func recordBatchCrcMatches(batch []byte) bool {
+	crc := crc32.Checksum(batch[recordBatchAttributesPosition:], castagnoliTable)
+	return crc == binary.BigEndian.Uint32(batch[recordBatchCrcPosition:])
+}
+
+// validRecordBatchesLength returns the length of the leading batches of data
+// that are whole and have a matching crc.
+func validRecordBatchesLength(data []byte) int {
+	position := 0
+	for position+recordBatchHeaderSize <= len(data) {
+		batchSize := recordBatchLogOverhead + int(binary.BigEndian.Uint32(data[position+recordBatchLengthPosition:]))
+		if batchSize < recordBatchHeaderSize || position+batchSize > len(data) {
+			break
+		}
+		if !recordBatchCrcMatches(data[position : position+batchSize]) {
+			break
+		}
+		position += batchSize
+	}
+	return position
+}
+
+// recordBatchCodec returns the compression codec of a batch.
+func recordBatchCodec(batch []byte) int16 {
+	return int16(binary.BigEndian.Uint16(batch[recordBatchAttributesPosition:])) & recordBatchCompressionMask
+}
+
+// recordBatchRecords returns the records of a whole batch, decompressed.
+func recordBatchRecords(batch []byte) ([]byte, error) {
+	return decompress(recordBatchCodec(batch), batch[recordBatchHeaderSize:])
+}
+
+// rebuildRecordBatch returns a batch with the header of batch holding count
+// records compressed with codec. lastOffsetDelta is kept, so the offsets the
+// batch covers don't change.
+func rebuildRecordBatch(batch []byte, codec int16, records []byte, count uint32) ([]byte, error) {
+	compressed, err := compress(codec, records)
+	if err != nil {
+		return nil, err
+	}
+
+	rebuilt := make([]byte, recordBatchHeaderSize, recordBatchHeaderSize+len(compressed))
+	copy(rebuilt, batch[:recordBatchHeaderSize])
+	rebuilt = append(rebuilt, compressed...)
+	attributes := binary.BigEndian.Uint16(rebuilt[recordBatchAttributesPosition:])
+	binary.BigEndian.PutUint16(rebuilt[recordBatchAttributesPosition:], attributes&^recordBatchCompressionMask|uint16(codec))
+	binary.BigEndian.PutUint32(rebuilt[recordBatchLengthPosition:], uint32(len(rebuilt)-recordBatchLogOverhead))
+	binary.BigEndian.PutUint32(rebuilt[recordBatchRecordsCountPosition:], count)
+	setRecordBatchCrc(rebuilt)
+	return rebuilt, nil
+}
+
+// setRecordOffsetDelta returns an encoded record with its offsetDelta
+// replaced, the fields after it are copied as they are.
+func setRecordOffsetDelta(record []byte, offsetDelta int64) []byte {
+	recordBuffer := bytes.NewBuffer(record)
+	binary.ReadVarint(recordBuffer)
+	attributes, _ := recordBuffer.ReadByte()
+	timestampDelta, _ := binary.ReadVarint(recordBuffer)
+	binary.ReadVarint(recordBuffer)
+
+	body := []byte{attributes}
+	body = binary.AppendVarint(body, timestampDelta)
+	body = binary.AppendVarint(body, offsetDelta)
+	body = append(body, recordBuffer.Bytes()...)
+	return append(binary.AppendVarint([]byte{}, int64(len(body))), body...)
+}
+
+func encodeRecord(offsetDelta int64, timestampDelta int64, key []byte, value []byte) []byte {
+	record := []byte{}
+	// attributes, unused
+	record = append(record, 0)
+	record = binary.AppendVarint(record, timestampDelta)
+	record = binary.AppendVarint(record, offsetDelta)
+	record = appendVarintBytes(record, key)
+	record = appendVarintBytes(record, value)
+	// headers count
+	record = binary.AppendVarint(record, 0)
+
+	return append(binary.AppendVarint([]byte{}, int64(len(record))), record...)
+}
+
+func appendVarintBytes(buffer []byte, data []byte) []byte {
+	if data == nil {
+		return binary.AppendVarint(buffer, -1)
+	}
+	buffer = binary.AppendVarint(buffer, int64(len(data)))
+	return append(buffer, data...)
+}
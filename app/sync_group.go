@@ -0,0 +1,47 @@
+package main
+
+import "github.com/codecrafters-io/kafka-starter-go/app/protocol"
+
+// SyncGroup
+
+type SyncGroupRequest struct {
+	RequestHeader
+	protocol.SyncGroupRequest
+}
+
+func (request *SyncGroupRequest) parse(decoder *protocol.Decoder) error {
+	return request.SyncGroupRequest.Decode(decoder, request.apiVersion)
+}
+
+// generateResponse blocks a follower until the leader has sent the
+// assignments for the generation.
+func (request *SyncGroupRequest) generateResponse(commonResponse *Response) {
+	commonResponse.correlationId = request.correlationId
+
+	syncGroupResponse := protocol.SyncGroupResponse{}
+	syncGroupResponse.Default()
+	syncGroupResponse.Assignment = []byte{}
+
+	group := getConsumerGroup(request.GroupId, false)
+	if request.GroupId == "" {
+		syncGroupResponse.ErrorCode = errorCodeInvalidGroupId
+	} else if group == nil {
+		syncGroupResponse.ErrorCode = errorCodeUnknownMemberId
+	} else {
+		assignments := map[string][]byte{}
+		for _, assignment := range request.Assignments {
+			assignments[assignment.MemberId] = assignment.Assignment
+		}
+
+		result := <-group.sync(request.MemberId, request.GroupInstanceId, request.GenerationId, request.ProtocolType, request.ProtocolName, assignments)
+		syncGroupResponse.ErrorCode = result.errorCode
+		syncGroupResponse.ProtocolType = result.protocolType
+		syncGroupResponse.ProtocolName = result.protocolName
+		if result.assignment != nil {
+			syncGroupResponse.Assignment = result.assignment
+		}
+	}
+
+	commonResponse.body = protocol.NewEncoder(syncGroupResponse.IsFlexible(request.apiVersion))
+	syncGroupResponse.Encode(commonResponse.body, request.apiVersion)
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// serialises topic creation so two requests can't create the same topic
+	topicsLock sync.Mutex
+
+	legalTopicName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+)
+
+// getClusterTopics returns every topic of the metadata image along with its
+// partitions. The topics are shared with other requests and must not be
+// changed.
+func getClusterTopics() []*Topic {
+	return getMetadataImage().topics
+}
+
+func findTopicByName(topics []*Topic, name string) *Topic {
+	for _, topic := range topics {
+		if topic.name == name {
+			return topic
+		}
+	}
+	return nil
+}
+
+func findTopicById(topics []*Topic, topicId uuid.UUID) *Topic {
+	for _, topic := range topics {
+		if topic.topicId == topicId {
+			return topic
+		}
+	}
+	return nil
+}
+
+func findPartition(topic *Topic, partitionIndex int32) *Partition {
+	for _, partition := range topic.partitions {
+		if partition.partitionIndex == partitionIndex {
+			return partition
+		}
+	}
+	return nil
+}
+
+const transactionStateTopic = "__transaction_state"
+
+func isInternalTopic(name string) bool {
+	return name == consumerOffsetsTopic || name == transactionStateTopic
+}
+
+// createInternalTopic creates __consumer_offsets or __transaction_state with
+// the partition count of their own config, compacted like in kafka.
+func createInternalTopic(name string) (*Topic, int16, error) {
+	numPartitions := serverConfig.offsetsTopicNumPartitions
+	if name == transactionStateTopic {
+		numPartitions = serverConfig.transactionLogNumPartitions
+	}
+	return createTopic(name, numPartitions, 1, map[string]string{"cleanup.policy": "compact"})
+}
+
+func validateTopicName(name string) error {
+	if name == "" {
+		return fmt.Errorf("topic name is empty")
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("topic name cannot be \"%s\"", name)
+	}
+	if len(name) > 249 {
+		return fmt.Errorf("topic name is longer than 249 characters")
+	}
+	if !legalTopicName.MatchString(name) {
+		return fmt.Errorf("topic name %s contains characters other than ASCII alphanumerics, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// newReplicaAssignment places every replica of numPartitions partitions. This
+// broker is the only one in the cluster, so it leads every partition.
+func newReplicaAssignment(numPartitions int32, replicationFactor int16) ([][]int32, int16, error) {
+	if numPartitions <= 0 {
+		return nil, errorCodeInvalidPartitions, fmt.Errorf("number of partitions must be larger than 0")
+	}
+	if replicationFactor <= 0 {
+		return nil, errorCodeInvalidReplicationFactor, fmt.Errorf("replication factor must be larger than 0")
+	}
+	if replicationFactor != 1 {
+		return nil, errorCodeInvalidReplicationFactor, fmt.Errorf("replication factor %d is larger than the 1 available broker", replicationFactor)
+	}
+
+	assignment := make([][]int32, numPartitions)
+	for partitionIndex := range assignment {
+		assignment[partitionIndex] = []int32{serverConfig.nodeId}
+	}
+	return assignment, errorCodeNone, nil
+}
+
+// validateReplicas checks the replicas of a partition given by a client.
+func validateReplicas(replicas []int32) error {
+	if len(replicas) == 0 {
+		return fmt.Errorf("replica assignment is empty")
+	}
+	for i, brokerId := range replicas {
+		if brokerId != serverConfig.nodeId {
+			return fmt.Errorf("broker %d in replica assignment does not exist", brokerId)
+		}
+		if slices.Contains(replicas[:i], brokerId) {
+			return fmt.Errorf("duplicate broker %d in replica assignment", brokerId)
+		}
+	}
+	return nil
+}
+
+// createTopic creates a topic with the replicas placed by the broker.
+func createTopic(name string, numPartitions int32, replicationFactor int16, configs map[string]string) (*Topic, int16, error) {
+	assignment, errorCode, err := newReplicaAssignment(numPartitions, replicationFactor)
+	if err != nil {
+		return nil, errorCode, err
+	}
+	return createTopicWithAssignment(name, assignment, configs, false)
+}
+
+// createTopicWithAssignment writes a TopicRecord, a ConfigRecord per config
+// and one PartitionRecord per partition to the cluster metadata log and
+// creates the partition directories. The first replica of a partition is its
+// leader. With validateOnly the topic is checked but nothing is written.
+func createTopicWithAssignment(name string, assignment [][]int32, configs map[string]string, validateOnly bool) (*Topic, int16, error) {
+	if err := validateTopicName(name); err != nil {
+		return nil, errorCodeInvalidTopicException, err
+	}
+	if len(assignment) == 0 {
+		return nil, errorCodeInvalidPartitions, fmt.Errorf("number of partitions must be larger than 0")
+	}
+	for _, replicas := range assignment {
+		if err := validateReplicas(replicas); err != nil {
+			return nil, errorCodeInvalidReplicaAssignment, err
+		}
+		if len(replicas) != len(assignment[0]) {
+			return nil, errorCodeInvalidReplicaAssignment, fmt.Errorf("all partitions must have the same number of replicas")
+		}
+	}
+	if err := validateTopicConfigs(configs); err != nil {
+		return nil, errorCodeInvalidConfig, err
+	}
+
+	topicsLock.Lock()
+	defer topicsLock.Unlock()
+
+	if findTopicByName(getClusterTopics(), name) != nil {
+		return nil, errorCodeTopicAlreadyExists, fmt.Errorf("topic %s already exists", name)
+	}
+
+	topicRecord := TopicRecord{name: name, topicId: uuid.New()}
+	topic := &Topic{
+		name:       name,
+		topicId:    topicRecord.topicId,
+		isInternal: isInternalTopic(name),
+	}
+
+	values := [][]byte{topicRecord.encode()}
+	configNames := slices.Sorted(maps.Keys(configs))
+	for _, configName := range configNames {
+		configRecord := ConfigRecord{resourceType: 2, resourceName: name, name: configName, value: configs[configName]}
+		values = append(values, configRecord.encode())
+	}
+	for partitionIndex, replicas := range assignment {
+		partitionRecord := PartitionRecord{
+			partitionId:        int32(partitionIndex),
+			topicId:            topicRecord.topicId,
+			replicaArray:       replicas,
+			inSyncReplicaArray: replicas,
+			leader:             replicas[0],
+		}
+		values = append(values, partitionRecord.encode())
+
+		topic.partitions = append(topic.partitions, &Partition{
+			partitionIndex:         partitionRecord.partitionId,
+			leaderId:               partitionRecord.leader,
+			replicaNodes:           partitionRecord.replicaArray,
+			isrNodes:               partitionRecord.inSyncReplicaArray,
+			eligibleLeaderReplicas: []int32{},
+			lastKnownElr:           []int32{},
+			offlineReplicas:        []int32{},
+		})
+	}
+
+	if validateOnly {
+		return topic, errorCodeNone, nil
+	}
+
+	if err := appendClusterMetadata(values); err != nil {
+		return nil, errorCodeUnknownServerError, err
+	}
+
+	for _, partition := range topic.partitions {
+		if _, err := getPartitionLog(name, partition.partitionIndex); err != nil {
+			fmt.Printf("Error while creating partition directory. %s\n", err)
+		}
+	}
+
+	return topic, errorCodeNone, nil
+}
+
+// createPartitions adds a partition to a topic for each of the replica lists
+// in assignment, numbered after the existing ones. With validateOnly the
+// partitions are checked but nothing is written.
+func createPartitions(name string, count int32, assignment [][]int32, validateOnly bool) (int16, error) {
+	topicsLock.Lock()
+	defer topicsLock.Unlock()
+
+	topic := findTopicByName(getClusterTopics(), name)
+	if topic == nil {
+		return errorCodeUnknownTopicOrPartition, fmt.Errorf("topic %s does not exist", name)
+	}
+	currentCount := int32(len(topic.partitions))
+	if count < currentCount {
+		return errorCodeInvalidPartitions, fmt.Errorf("topic currently has %d partitions, which is higher than the requested %d", currentCount, count)
+	}
+	if count == currentCount {
+		return errorCodeInvalidPartitions, fmt.Errorf("topic already has %d partitions", currentCount)
+	}
+
+	// new partitions get as many replicas as the existing ones
+	replicationFactor := int(serverConfig.defaultReplicationFactor)
+	if currentCount > 0 {
+		replicationFactor = len(topic.partitions[0].replicaNodes)
+	}
+	if assignment == nil {
+		var errorCode int16
+		var err error
+		assignment, errorCode, err = newReplicaAssignment(count-currentCount, int16(replicationFactor))
+		if err != nil {
+			return errorCode, err
+		}
+	}
+	if int32(len(assignment)) != count-currentCount {
+		return errorCodeInvalidReplicaAssignment, fmt.Errorf("%d replica assignments were given for %d new partitions", len(assignment), count-currentCount)
+	}
+	for _, replicas := range assignment {
+		if err := validateReplicas(replicas); err != nil {
+			return errorCodeInvalidReplicaAssignment, err
+		}
+		if len(replicas) != replicationFactor {
+			return errorCodeInvalidReplicaAssignment, fmt.Errorf("inconsistent replication factor between partitions, %d instead of %d", len(replicas), replicationFactor)
+		}
+	}
+
+	if validateOnly {
+		return errorCodeNone, nil
+	}
+
+	values := [][]byte{}
+	for i, replicas := range assignment {
+		partitionRecord := PartitionRecord{
+			partitionId:        currentCount + int32(i),
+			topicId:            topic.topicId,
+			replicaArray:       replicas,
+			inSyncReplicaArray: replicas,
+			leader:             replicas[0],
+		}
+		values = append(values, partitionRecord.encode())
+	}
+	if err := appendClusterMetadata(values); err != nil {
+		return errorCodeUnknownServerError, err
+	}
+
+	for partitionIndex := currentCount; partitionIndex < count; partitionIndex++ {
+		if _, err := getPartitionLog(name, partitionIndex); err != nil {
+			fmt.Printf("Error while creating partition directory. %s\n", err)
+		}
+	}
+	return errorCodeNone, nil
+}
+
+// deleteTopic writes a RemoveTopicRecord to the cluster metadata log and
+// deletes the partition directories in the background.
+func deleteTopic(topic *Topic) error {
+	topicsLock.Lock()
+	defer topicsLock.Unlock()
+
+	removeTopicRecord := RemoveTopicRecord{topicId: topic.topicId}
+	if err := appendClusterMetadata([][]byte{removeTopicRecord.encode()}); err != nil {
+		return err
+	}
+
+	for _, partition := range topic.partitions {
+		if err := deletePartitionLog(topic.name, partition.partitionIndex); err != nil {
+			fmt.Printf("Error while deleting partition %s-%d. %s\n", topic.name, partition.partitionIndex, err)
+		}
+	}
+	return nil
+}
+
+// topicConfigDefaults returns the value of every topic config for a topic
+// that doesn't override it, taken from the broker config where there is one.
+func topicConfigDefaults() map[string]string {
+	return map[string]string{
+		"cleanup.policy":                  "delete",
+		"compression.type":                "producer",
+		"delete.retention.ms":             strconv.FormatInt(serverConfig.logCleanerDeleteRetentionMs, 10),
+		"file.delete.delay.ms":            "60000",
+		"flush.messages":                  "9223372036854775807",
+		"flush.ms":                        "9223372036854775807",
+		"index.interval.bytes":            strconv.Itoa(serverConfig.logIndexIntervalBytes),
+		"max.compaction.lag.ms":           "9223372036854775807",
+		"max.message.bytes":               "1048588",
+		"message.timestamp.after.max.ms":  "9223372036854775807",
+		"message.timestamp.before.max.ms": "9223372036854775807",
+		"message.timestamp.type":          "CreateTime",
+		"min.cleanable.dirty.ratio":       "0.5",
+		"min.compaction.lag.ms":           "0",
+		"min.insync.replicas":             "1",
+		"preallocate":                     "false",
+		"retention.bytes":                 strconv.FormatInt(serverConfig.logRetentionBytes, 10),
+		"retention.ms":                    strconv.FormatInt(serverConfig.logRetentionMs, 10),
+		"segment.bytes":                   strconv.FormatInt(serverConfig.logSegmentBytes, 10),
+		"segment.index.bytes":             "10485760",
+		"segment.jitter.ms":               "0",
+		"segment.ms":                      strconv.FormatInt(serverConfig.logRollMs, 10),
+		"unclean.leader.election.enable":  "false",
+	}
+}
+
+// validateTopicConfigs rejects unknown topic configs and values that don't
+// have the type of the config.
+func validateTopicConfigs(configs map[string]string) error {
+	defaults := topicConfigDefaults()
+	for name, value := range configs {
+		defaultValue, ok := defaults[name]
+		if !ok {
+			return fmt.Errorf("unknown topic config name: %s", name)
+		}
+
+		var err error
+		switch name {
+		case "cleanup.policy":
+			for _, policy := range strings.Split(value, ",") {
+				if policy = strings.TrimSpace(policy); policy != "delete" && policy != "compact" {
+					err = fmt.Errorf("%s is not a cleanup policy", policy)
+				}
+			}
+		case "compression.type":
+			if !slices.Contains([]string{"uncompressed", "zstd", "lz4", "snappy", "gzip", "producer"}, value) {
+				err = fmt.Errorf("%s is not a compression type", value)
+			}
+		case "message.timestamp.type":
+			if value != "CreateTime" && value != "LogAppendTime" {
+				err = fmt.Errorf("%s is not a timestamp type", value)
+			}
+		case "min.cleanable.dirty.ratio":
+			_, err = strconv.ParseFloat(value, 64)
+		default:
+			if defaultValue == "true" || defaultValue == "false" {
+				_, err = strconv.ParseBool(value)
+			} else {
+				_, err = strconv.ParseInt(value, 10, 64)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid value %s for topic config %s: %s", value, name, err)
+		}
+	}
+	return nil
+}
+
+// getTopicConfigs returns the configs set on a topic through ConfigRecords,
+// topics without overrides use the broker defaults. Deleting a topic drops
+// its configs.
+func getTopicConfigs(name string) map[string]string {
+	configs := getMetadataImage().configs[ConfigResource{resourceType: 2, resourceName: name}]
+	if configs == nil {
+		return map[string]string{}
+	}
+	return maps.Clone(configs)
+}
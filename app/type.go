@@ -2,6 +2,8 @@ package main
 
 import (
 	"bytes"
+
+	"github.com/codecrafters-io/kafka-starter-go/app/protocol"
 )
 
 // type Request struct {
@@ -18,19 +20,26 @@ type RequestHeader struct {
 	apiVersion    int16
 	correlationId int32
 	clientId      string
+	// raw values by tag, flexible headers only
+	taggedFields map[uint64][]byte
 }
 
 type Response struct {
 	messageSize   int32
 	correlationId int32
 
-	BytesData bytes.Buffer
+	body *protocol.Encoder
 }
 
 type RequestInterface interface {
-	parse(buffer *bytes.Buffer)
+	parse(decoder *protocol.Decoder) error
+	requestHeader() *RequestHeader
 }
 
 type ResponseInterface interface {
-	bytes(buffer *bytes.Buffer, includetagField bool)
+	bytes(buffer *bytes.Buffer, headerVersion int16) error
+}
+
+func (header *RequestHeader) requestHeader() *RequestHeader {
+	return header
 }
\ No newline at end of file
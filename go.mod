@@ -10,4 +10,4 @@ module github.com/codecrafters-io/kafka-starter-go
 
 go 1.24.0
 
-require github.com/google/uuid v1.6.0 // indirect
\ No newline at end of file
+require github.com/google/uuid v1.6.0